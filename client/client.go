@@ -0,0 +1,241 @@
+// Пакет client — типизированный Go-клиент HTTP API getcitation для других сервисов.
+//
+//	c := client.New("http://localhost:8080", nil)
+//	id, err := c.CreateQuote(ctx, "Confucius", "Life is simple, but we insist on making it complicated.")
+//	if errors.Is(err, client.ErrDuplicateEntry) {
+//		// такая цитата уже есть
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Ошибки, в которые превращаются ответы API. Проверяются через errors.Is.
+var (
+	ErrDuplicateEntry = errors.New("similar entry already exists")
+	ErrNoQuotesFound  = errors.New("no quotes found")
+)
+
+// Quote — цитата в том виде, в котором её возвращает API.
+type Quote struct {
+	ID     int    `json:"id"`
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+	Likes  int    `json:"likes"`
+	Views  int    `json:"views"`
+}
+
+// FieldError — поле запроса, не прошедшее проверку на сервере, и причина.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// APIError — ответ API с кодом ошибки. Для 404 и 409 разворачивается в ErrNoQuotesFound и
+// ErrDuplicateEntry соответственно. Fields заполнен, если поля запроса не прошли проверку.
+type APIError struct {
+	Code    int
+	Status  string
+	Message string
+	Fields  []FieldError
+
+	err error
+}
+
+func (e *APIError) Error() string {
+	if len(e.Fields) > 0 {
+		parts := make([]string, 0, len(e.Fields))
+		for _, field := range e.Fields {
+			parts = append(parts, field.Field+" "+field.Reason)
+		}
+		return fmt.Sprintf("getcitation: %d %s: %s: %s", e.Code, e.Status, e.Message, strings.Join(parts, "; "))
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("getcitation: %d %s: %s", e.Code, e.Status, e.Message)
+	}
+	return fmt.Sprintf("getcitation: %d %s", e.Code, e.Status)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Client обращается к API getcitation по BaseURL через HTTPClient.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New создаёт клиент. Если httpClient равен nil, используется http.DefaultClient.
+func New(baseURL string, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: httpClient,
+	}
+}
+
+// status — общая часть всех ответов API.
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorResponse — конверт ошибки API.
+type errorResponse struct {
+	Status  status       `json:"status"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+type createQuoteRequest struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+}
+
+type createQuoteResponse struct {
+	ID int `json:"id"`
+}
+
+type getQuotesResponse struct {
+	Quotes []Quote `json:"quotes"`
+}
+
+type getRandomQuoteResponse struct {
+	Quote Quote `json:"quote"`
+}
+
+// CreateQuote добавляет цитату и возвращает её ID.
+func (c Client) CreateQuote(ctx context.Context, author string, quote string) (int, error) {
+	const op = "client.CreateQuote()"
+
+	var resp createQuoteResponse
+
+	err := c.do(ctx, http.MethodPost, "/quotes", createQuoteRequest{Author: author, Quote: quote}, &resp)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.ID, nil
+}
+
+// GetQuotes возвращает первую страницу цитат (размер задаёт сервер, PAGE_SIZE_DEFAULT); если переданы
+// авторы — только цитаты любого из них.
+func (c Client) GetQuotes(ctx context.Context, authors ...string) ([]Quote, error) {
+	const op = "client.GetQuotes()"
+
+	path := "/quotes"
+	if len(authors) > 0 {
+		query := url.Values{"author": authors}
+		path += "?" + query.Encode()
+	}
+
+	var resp getQuotesResponse
+
+	err := c.do(ctx, http.MethodGet, path, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.Quotes, nil
+}
+
+// GetRandomQuote возвращает случайную цитату.
+func (c Client) GetRandomQuote(ctx context.Context) (Quote, error) {
+	const op = "client.GetRandomQuote()"
+
+	var resp getRandomQuoteResponse
+
+	err := c.do(ctx, http.MethodGet, "/quotes/random", nil, &resp)
+	if err != nil {
+		return Quote{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp.Quote, nil
+}
+
+// DeleteQuoteByID удаляет цитату по ID.
+func (c Client) DeleteQuoteByID(ctx context.Context, id int) error {
+	const op = "client.DeleteQuoteByID()"
+
+	err := c.do(ctx, http.MethodDelete, "/quotes/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// do выполняет запрос и декодирует успешный ответ в out (если out не nil), а ответ с ошибкой — в *APIError.
+func (c Client) do(ctx context.Context, method string, path string, in any, out any) error {
+	var body io.Reader
+
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newAPIError разбирает конверт ошибки API. Если тело не удалось разобрать, ошибка строится по коду ответа.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		Code:   resp.StatusCode,
+		Status: http.StatusText(resp.StatusCode),
+	}
+
+	var envelope errorResponse
+
+	err := json.NewDecoder(resp.Body).Decode(&envelope)
+	if err == nil {
+		if envelope.Status.Message != "" {
+			apiErr.Status = envelope.Status.Message
+		}
+		apiErr.Message = envelope.Message
+		apiErr.Fields = envelope.Errors
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		apiErr.err = ErrNoQuotesFound
+	case http.StatusConflict:
+		apiErr.err = ErrDuplicateEntry
+	}
+
+	return apiErr
+}
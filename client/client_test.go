@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestClient поднимает httptest.Server с handler и возвращает клиент к нему
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return New(server.URL+"/", server.Client())
+}
+
+// reply пишет JSON-ответ с кодом code
+func reply(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write([]byte(body))
+}
+
+func TestCreateQuote(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/quotes" {
+			t.Errorf("request = %s %s, want POST /quotes", r.Method, r.URL.Path)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", contentType)
+		}
+
+		var req createQuoteRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("decode request error = %v", err)
+		}
+		if req.Author != "Confucius" || req.Quote != "Life is simple" {
+			t.Errorf("request body = %+v", req)
+		}
+
+		reply(w, http.StatusOK, `{"status":{"code":200},"id":7}`)
+	})
+
+	id, err := c.CreateQuote(context.Background(), "Confucius", "Life is simple")
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	if id != 7 {
+		t.Errorf("CreateQuote() = %d, want 7", id)
+	}
+}
+
+func TestCreateQuoteDuplicate(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusConflict, `{"status":{"code":409,"message":"Conflict"},"message":"This quote already exists"}`)
+	})
+
+	_, err := c.CreateQuote(context.Background(), "Confucius", "Life is simple")
+	if !errors.Is(err, ErrDuplicateEntry) {
+		t.Fatalf("CreateQuote() error = %v, want %v", err, ErrDuplicateEntry)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateQuote() error = %T, want *APIError", err)
+	}
+	if apiErr.Code != http.StatusConflict || apiErr.Status != "Conflict" || apiErr.Message != "This quote already exists" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
+
+func TestCreateQuoteValidation(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusBadRequest, `{"status":{"code":400,"message":"Invalid Request Body"},"message":"Request fields failed validation","errors":[{"field":"author","reason":"must not be empty"}]}`)
+	})
+
+	_, err := c.CreateQuote(context.Background(), "", "Life is simple")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("CreateQuote() error = %v, want *APIError", err)
+	}
+	if errors.Is(err, ErrDuplicateEntry) || errors.Is(err, ErrNoQuotesFound) {
+		t.Errorf("CreateQuote() error = %v, want neither sentinel", err)
+	}
+
+	want := []FieldError{{Field: "author", Reason: "must not be empty"}}
+	if !slices.Equal(apiErr.Fields, want) {
+		t.Errorf("APIError.Fields = %v, want %v", apiErr.Fields, want)
+	}
+	if !strings.Contains(err.Error(), "author must not be empty") {
+		t.Errorf("Error() = %q, want the failed field", err.Error())
+	}
+}
+
+func TestGetQuotes(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/quotes" {
+			t.Errorf("request = %s %s, want GET /quotes", r.Method, r.URL.Path)
+		}
+
+		authors := r.URL.Query()["author"]
+		if !slices.Equal(authors, []string{"Lev Tolstoy", "Confucius"}) {
+			t.Errorf("author parameters = %q", authors)
+		}
+
+		reply(w, http.StatusOK, `{"status":{"code":200},"quotes":[{"id":1,"author":"Lev Tolstoy","quote":"All happy families are alike","likes":2,"views":5}]}`)
+	})
+
+	quotes, err := c.GetQuotes(context.Background(), "Lev Tolstoy", "Confucius")
+	if err != nil {
+		t.Fatalf("GetQuotes() error = %v", err)
+	}
+
+	want := []Quote{{ID: 1, Author: "Lev Tolstoy", Quote: "All happy families are alike", Likes: 2, Views: 5}}
+	if !slices.Equal(quotes, want) {
+		t.Errorf("GetQuotes() = %+v, want %+v", quotes, want)
+	}
+}
+
+func TestGetRandomQuote(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/quotes/random" {
+			t.Errorf("path = %s, want /quotes/random", r.URL.Path)
+		}
+
+		reply(w, http.StatusOK, `{"status":{"code":200},"quote":{"id":3,"author":"Confucius","quote":"Life is simple"}}`)
+	})
+
+	quote, err := c.GetRandomQuote(context.Background())
+	if err != nil {
+		t.Fatalf("GetRandomQuote() error = %v", err)
+	}
+	if quote.ID != 3 || quote.Author != "Confucius" {
+		t.Errorf("GetRandomQuote() = %+v", quote)
+	}
+}
+
+func TestGetRandomQuoteNotFound(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusNotFound, `{"status":{"code":404,"message":"Not Found"},"message":"No quotes found"}`)
+	})
+
+	_, err := c.GetRandomQuote(context.Background())
+	if !errors.Is(err, ErrNoQuotesFound) {
+		t.Fatalf("GetRandomQuote() error = %v, want %v", err, ErrNoQuotesFound)
+	}
+}
+
+func TestDeleteQuoteByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		body    string
+		wantErr error
+	}{
+		{"deleted", http.StatusOK, `{"status":{"code":200},"message":"Quote deleted successfully"}`, nil},
+		{"idempotent", http.StatusNoContent, "", nil},
+		{"not found", http.StatusNotFound, `{"status":{"code":404,"message":"Not Found"}}`, ErrNoQuotesFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/quotes/42" {
+					t.Errorf("request = %s %s, want DELETE /quotes/42", r.Method, r.URL.Path)
+				}
+				if tt.body == "" {
+					w.WriteHeader(tt.code)
+					return
+				}
+				reply(w, tt.code, tt.body)
+			})
+
+			err := c.DeleteQuoteByID(context.Background(), 42)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteQuoteByID() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIErrorWithoutEnvelope(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream is down", http.StatusBadGateway)
+	})
+
+	_, err := c.GetQuotes(context.Background())
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetQuotes() error = %v, want *APIError", err)
+	}
+	if apiErr.Code != http.StatusBadGateway || apiErr.Status != http.StatusText(http.StatusBadGateway) {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
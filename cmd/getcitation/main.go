@@ -1,9 +1,25 @@
 package main
 
-import "getcitation/internal/app"
+import (
+	"getcitation/internal/app"
+	"getcitation/internal/app/getcitation"
+)
+
+// Сведения о сборке, подставляемые через -ldflags, например:
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/getcitation
+var (
+	version   string
+	commit    string
+	buildTime string
+)
 
 func main() {
-	app, err := app.New()
+	app, err := app.New(getcitation.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+	}.WithDefaults())
 	if err != nil {
 		panic(err)
 	}
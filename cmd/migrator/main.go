@@ -1,12 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 
+	"getcitation/internal/storage"
 	"getcitation/internal/utils"
 	"getcitation/internal/utils/config"
 )
@@ -16,13 +22,31 @@ const (
 	directionDown = "down"
 )
 
+var (
+	ErrMigrationsNotDir = errors.New("migrations path is not a directory")
+	ErrNoMigrations     = errors.New("no .sql migrations found")
+)
+
 func main() {
 	config, err := config.New()
 	if err != nil {
 		panic(err)
 	}
 
-	conn := utils.BuildPostgreSQLDSN(config)
+	var conn string
+
+	switch config.StorageBackend {
+	case storage.BackendSQLite:
+		conn = "sqlite://" + strings.TrimPrefix(utils.BuildSQLiteDSN(config), "file:")
+
+	default:
+		conn = utils.BuildPostgreSQLDSN(config)
+	}
+
+	err = checkMigrations(config.MigrationsPath)
+	if err != nil {
+		panic(err)
+	}
 
 	m, err := migrate.New("file://"+config.MigrationsPath, conn)
 	if err != nil {
@@ -48,3 +72,33 @@ func main() {
 
 	fmt.Println("миграция завершена")
 }
+
+// checkMigrations проверяет, что каталог миграций существует и содержит .sql-файлы. Без этой проверки
+// migrate отвечает невнятным "no migration found", а ошибка здесь называет абсолютный путь, по которому
+// искались миграции, — так сразу видно, относительно какого каталога разрешился MIGRATIONS_PATH.
+func checkMigrations(path string) error {
+	const op = "main.checkMigrations()"
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// ошибка os.Stat уже содержит путь
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %s: %w", op, abs, ErrMigrationsNotDir)
+	}
+
+	files, err := filepath.Glob(filepath.Join(abs, "*.sql"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%s: %s: %w", op, abs, ErrNoMigrations)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -8,21 +9,31 @@ import (
 	"syscall"
 
 	"getcitation/internal/app/getcitation"
+	"getcitation/internal/app/grpcserver"
 	"getcitation/internal/lib/logger"
-	storage "getcitation/internal/storage/postgresql"
+	"getcitation/internal/storage"
+	"getcitation/internal/storage/postgresql"
+	"getcitation/internal/storage/sqlite"
 	"getcitation/internal/utils/config"
 )
 
 // App — основной объект приложения, агрегирующий все ключевые компоненты.
 type App struct {
 	GetCitation getcitation.App
-	Storage     storage.Storage
+	GRPC        *grpcserver.App
+	Storage     Storage
 	Log         logger.Logger
 	Config      config.Config
 }
 
+// Storage — общий интерфейс бэкендов хранилища, которыми управляет приложение.
+type Storage interface {
+	Shutdown() error
+}
+
 // New — конструктор для App. Создаёт и инициализирует все зависимости приложения.
-func New() (App, error) {
+// build — сведения о сборке, которые отдаёт /version.
+func New(build getcitation.BuildInfo) (App, error) {
 	const op = "app.New()"
 
 	config, err := config.New()
@@ -30,21 +41,66 @@ func New() (App, error) {
 		return App{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	logger, err := logger.New(config.AppLogMode)
+	logger, err := logger.New(config.AppLogMode, config.AppLogOutput)
 	if err != nil {
 		return App{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	storage, err := storage.New(config, logger.Log)
+	// Секреты в журнал не попадают: их скрывает Config.LogValue.
+	logger.Log.Info(
+		"конфигурация загружена",
+		slog.String("op", op),
+		slog.Any("config", config),
+		slog.Any("build", build),
+	)
+
+	var db Storage
+	var store getcitation.QuoteStore
+
+	switch config.StorageBackend {
+	case storage.BackendPostgreSQL:
+		postgresql, err := postgresql.New(config, logger.Log)
+		if err != nil {
+			return App{}, fmt.Errorf("%s: %w", op, err)
+		}
+		db, store = postgresql, postgresql.DB.Handlers
+
+	case storage.BackendSQLite:
+		sqlite, err := sqlite.New(config, logger.Log)
+		if err != nil {
+			return App{}, fmt.Errorf("%s: %w", op, err)
+		}
+		db, store = sqlite, sqlite.DB.Handlers
+	}
+
+	getcitation, err := getcitation.New(store, config, build, logger.Log)
 	if err != nil {
 		return App{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	getcitation := getcitation.New(storage, config, logger.Log)
+	if config.SeedOnEmpty {
+		_, err = getcitation.Seed(context.Background())
+		if err != nil {
+			return App{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var grpc *grpcserver.App
+
+	if config.GRPCPort != "" {
+		handlers := getcitation.Server.Handlers
+
+		server, err := grpcserver.New(handlers.Manipulator, handlers.Getter, handlers.ReadOnlyMode, config, logger.Log)
+		if err != nil {
+			return App{}, fmt.Errorf("%s: %w", op, err)
+		}
+		grpc = &server
+	}
 
 	return App{
 		GetCitation: getcitation,
-		Storage:     storage,
+		GRPC:        grpc,
+		Storage:     db,
 		Log:         logger,
 		Config:      config,
 	}, nil
@@ -57,7 +113,7 @@ func (a App) Run() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 
 	a.Log.Log.Info(
 		"запуск",
@@ -71,6 +127,15 @@ func (a App) Run() {
 		}
 	}()
 
+	if a.GRPC != nil {
+		go func() {
+			err := a.GRPC.Run()
+			if err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
 	select {
 	case sig := <-sigChan:
 		a.Log.Log.Error(
@@ -100,25 +165,41 @@ func (a App) Run() {
 			slog.Any("errors", errs),
 		)
 	}
+
+	a.Log.Log.Info(
+		"остановлено",
+		slog.String("op", op),
+	)
+
+	// Журнал закрывается последним, чтобы в него попали и события самой остановки. Ошибку закрытия
+	// записать в журнал уже нельзя, поэтому она выводится в stderr.
+	err := a.Log.Shutdown()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
 
-// shutdown — корректно завершает работу всех компонентов приложения.
+// shutdown — корректно завершает работу компонентов приложения, кроме журнала. Сначала останавливаются
+// серверы, чтобы запросы в обработке успели завершиться, пока хранилище еще доступно, затем закрывается
+// хранилище.
 func (a App) shutdown() []error {
 	const op = "app.shutdown()"
 
 	var errs []error
 
-	err := a.Storage.Shutdown()
+	err := a.GetCitation.Shutdown()
 	if err != nil {
 		errs = append(errs, err)
 	}
 
-	err = a.Log.Shutdown()
-	if err != nil {
-		errs = append(errs, err)
+	if a.GRPC != nil {
+		err = a.GRPC.Shutdown()
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	err = a.GetCitation.Shutdown()
+	err = a.Storage.Shutdown()
 	if err != nil {
 		errs = append(errs, err)
 	}
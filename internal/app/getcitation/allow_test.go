@@ -0,0 +1,91 @@
+package getcitation
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMethodNotAllowedSetsAllow(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/quotes", "GET, HEAD, POST"},
+		{"/quotes/1", "GET, DELETE"},
+		{"/quotes/all", "DELETE"},
+		{"/quotes/random", "GET, HEAD"},
+		{"/quotes/random.txt", "GET"},
+		{"/quotes/random.svg", "GET"},
+		{"/quotes/stream", "GET"},
+		{"/quotes/count", "GET"},
+		{"/quotes/search", "GET"},
+		{"/quotes/batch", "GET"},
+		{"/quotes/exists", "GET"},
+		{"/quotes/import", "POST"},
+		{"/quotes/recent", "GET"},
+		{"/quotes/feed.rss", "GET"},
+		{"/quotes/export", "GET"},
+		{"/quotes/1/restore", "POST"},
+		{"/quotes/1/like", "POST"},
+		{"/quotes/1/similar", "GET"},
+		{"/quotes/1/categories", "POST"},
+		{"/quotes/slug/life-is-simple", "GET"},
+		{"/stats", "GET"},
+		{"/stats/text", "GET"},
+		{"/authors", "GET, PATCH"},
+		{"/categories", "GET, POST"},
+		{"/audit", "GET"},
+		{"/openapi.json", "GET"},
+		{"/admin/readonly", "GET, HEAD, POST"},
+		{"/admin/reload-filters", "POST"},
+		{"/ready", "GET, HEAD"},
+		{"/version", "GET, HEAD"},
+	}
+
+	// Эти маршруты отдают не JSON, и ошибка от них тоже приходит текстом
+	plain := []string{"/quotes/random.txt", "/quotes/random.svg", "/quotes/feed.rss"}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := serve(handler, http.MethodPut, tt.path, "")
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("PUT status = %d, want %d: %s", w.Code, http.StatusMethodNotAllowed, w.Body)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.allow {
+				t.Errorf("Allow = %q, want %q", allow, tt.allow)
+			}
+
+			if slices.Contains(plain, tt.path) {
+				if body := strings.TrimSpace(w.Body.String()); body != errMethodNotAllowed {
+					t.Errorf("body = %q, want %q", body, errMethodNotAllowed)
+				}
+				return
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if response.Status.Code != http.StatusMethodNotAllowed || response.Status.Message != errMethodNotAllowed {
+				t.Errorf("response status = %+v, want %d %q", response.Status, http.StatusMethodNotAllowed, errMethodNotAllowed)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowedWithPrefix(t *testing.T) {
+	cfg := testConfig()
+	cfg.RoutePrefix = "/api/v1"
+
+	handler := newTestApp(t, cfg, &fakeStore{})
+
+	w := serve(handler, http.MethodPost, "/api/v1/quotes/random", "")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want %d: %s", w.Code, http.StatusMethodNotAllowed, w.Body)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("Allow = %q, want %q", allow, "GET, HEAD")
+	}
+}
@@ -0,0 +1,121 @@
+package getcitation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/storage"
+)
+
+// Роли, которые может содержать claim role
+const (
+	RoleAdmin string = "admin"
+)
+
+// claimsKey — ключ claims токена в контексте запроса
+type claimsKey struct{}
+
+// ClaimsFromContext возвращает claims проверенного токена. ok равен false, если аутентификация
+// выключена (JWT_SECRET не задан) или запрос до нее не дошел.
+func ClaimsFromContext(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(jwt.Claims)
+	return claims, ok
+}
+
+// ContextWithClaims кладет claims проверенного токена в контекст. Так их передает и gRPC сервер, чтобы
+// сервис записывал инициатора изменений в журнал аудита одинаково для обоих API.
+func ContextWithClaims(ctx context.Context, claims jwt.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// withActor помечает контекст субъектом токена как инициатором изменения для журнала аудита.
+// Без аутентификации контекст возвращается как есть, и инициатор в журнале остается пустым.
+func withActor(ctx context.Context) context.Context {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return storage.WithActor(ctx, claims.Subject)
+}
+
+// isAdmin сообщает, разрешены ли запросу административные операции. Без аутентификации разрешено все.
+func (h Handlers) isAdmin(r *http.Request) bool {
+	if h.Config.JWTSecret == "" {
+		return true
+	}
+
+	claims, ok := ClaimsFromContext(r.Context())
+	return ok && claims.Role == RoleAdmin
+}
+
+// isReadOnly сообщает, что метод только читает данные и доступен любой роли
+func isReadOnly(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// Authenticate проверяет bearer-токен (HS256, секрет JWT_SECRET) и кладет его claims в контекст запроса.
+// Чтение доступно любой роли, изменяющие методы — только роли admin. Без JWT_SECRET пропускает все запросы.
+func (h Handlers) Authenticate(next http.Handler) http.Handler {
+	const op = "getcitation.Transport.Authenticate()"
+
+	if h.Config.JWTSecret == "" {
+		return next
+	}
+
+	secret := []byte(h.Config.JWTSecret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		claims, err := jwt.Parse(strings.TrimSpace(token), secret, time.Now())
+		if !found || err != nil {
+			h.Log.Error(
+				errUnauthorized,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="getcitation"`)
+			h.writeJSON(w, http.StatusUnauthorized, Error{
+				Status: Status{
+					Code:    http.StatusUnauthorized,
+					Message: errUnauthorized,
+				},
+				Message: messageInvalidToken,
+			})
+
+			return
+		}
+
+		if !isReadOnly(r.Method) && claims.Role != RoleAdmin {
+			h.Log.Error(
+				errForbidden,
+				slog.String("op", op),
+				slog.String("subject", claims.Subject),
+				slog.String("role", claims.Role),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusForbidden, Error{
+				Status: Status{
+					Code:    http.StatusForbidden,
+					Message: errForbidden,
+				},
+				Message: messageAdminRequired,
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+	})
+}
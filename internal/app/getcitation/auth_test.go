@@ -0,0 +1,131 @@
+package getcitation
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/storage"
+)
+
+// bearer возвращает заголовок Authorization с токеном claims, подписанным secret
+func bearer(t *testing.T, claims jwt.Claims, secret string) string {
+	t.Helper()
+
+	token, err := jwt.Sign(claims, []byte(secret))
+	if err != nil {
+		t.Fatalf("jwt.Sign() error = %v", err)
+	}
+	return "Bearer " + token
+}
+
+func TestAuthenticateRejectsInvalidToken(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWTSecret = "test-secret"
+
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Seneca", Quote: "While we teach, we learn"})
+
+	handler := newTestApp(t, cfg, store)
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"missing", ""},
+		{"not bearer", "Basic YWxpY2U6c2VjcmV0"},
+		{"garbage", "Bearer not-a-token"},
+		{"wrong secret", bearer(t, jwt.Claims{Role: RoleAdmin}, "other-secret")},
+		{"expired", bearer(t, jwt.Claims{Role: RoleAdmin, ExpiresAt: time.Now().Add(-time.Minute).Unix()}, cfg.JWTSecret)},
+		{"not yet valid", bearer(t, jwt.Claims{Role: RoleAdmin, NotBefore: time.Now().Add(time.Hour).Unix()}, cfg.JWTSecret)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+				target := "/quotes"
+				if method == http.MethodDelete {
+					target = "/quotes/1"
+				}
+
+				w := serve(handler, method, target, "", "Authorization", tt.authorization)
+				if w.Code != http.StatusUnauthorized {
+					t.Fatalf("%s %s status = %d, want %d: %s", method, target, w.Code, http.StatusUnauthorized, w.Body)
+				}
+				if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="getcitation"` {
+					t.Errorf("%s %s WWW-Authenticate = %q", method, target, got)
+				}
+
+				var response Error
+				decode(t, w, &response)
+				if response.Status.Code != http.StatusUnauthorized || response.Status.Message != errUnauthorized || response.Message != messageInvalidToken {
+					t.Errorf("%s %s response = %+v", method, target, response)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticateRequiresAdminForMutations(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWTSecret = "test-secret"
+
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Seneca", Quote: "While we teach, we learn"})
+
+	handler := newTestApp(t, cfg, store)
+	reader := bearer(t, jwt.Claims{Subject: "bob", Role: "reader", ExpiresAt: time.Now().Add(time.Hour).Unix()}, cfg.JWTSecret)
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPost, "/quotes", `{"author":"Marcus Aurelius","quote":"The best revenge is not to be like that"}`},
+		{http.MethodDelete, "/quotes/1", ""},
+	}
+
+	for _, tt := range tests {
+		w := serve(handler, tt.method, tt.target, tt.body, "Authorization", reader)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("%s %s status = %d, want %d: %s", tt.method, tt.target, w.Code, http.StatusForbidden, w.Body)
+		}
+
+		var response Error
+		decode(t, w, &response)
+		if response.Status.Code != http.StatusForbidden || response.Status.Message != errForbidden || response.Message != messageAdminRequired {
+			t.Errorf("%s %s response = %+v", tt.method, tt.target, response)
+		}
+	}
+
+	// Запреты ничего не изменили, а чтение читателю доступно
+	w := serve(handler, http.MethodGet, "/quotes", "", "Authorization", reader)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /quotes status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var response GetQuotesResponse
+	decode(t, w, &response)
+	if len(response.Quotes) != 1 {
+		t.Errorf("GET /quotes returned %d quotes, want 1", len(response.Quotes))
+	}
+}
+
+func TestAuthenticateAllowsAdmin(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWTSecret = "test-secret"
+
+	handler := newTestApp(t, cfg, &fakeStore{})
+	admin := bearer(t, jwt.Claims{Subject: "alice", Role: RoleAdmin}, cfg.JWTSecret)
+
+	w := serve(handler, http.MethodPost, "/quotes", `{"author":"Seneca","quote":"While we teach, we learn"}`, "Authorization", admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /quotes status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	w = serve(handler, http.MethodDelete, "/quotes/1", "", "Authorization", admin)
+	if w.Code != http.StatusOK {
+		t.Errorf("DELETE /quotes/1 status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
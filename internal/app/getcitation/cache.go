@@ -0,0 +1,332 @@
+package getcitation
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"getcitation/internal/storage"
+)
+
+// cacheMaxEntries — сколько разных фильтров кэш хранит одновременно. Фильтр задает клиент, поэтому без
+// предела каждый новый набор параметров запроса добавлял бы запись, и кэш рос бы до следующей мутации.
+const cacheMaxEntries = 1024
+
+// QuoteCache — кэш списка цитат поверх сервиса. Хранит результат GetQuotes отдельно для каждого
+// фильтра и полностью сбрасывается после любой успешной мутации. TTL служит страховкой
+// на случай изменений в БД в обход сервиса.
+type QuoteCache struct {
+	Manipulator ServiceManipulator
+	Getter      ServiceGetter
+	TTL         time.Duration
+
+	mu      *sync.RWMutex
+	entries map[string]cacheEntry
+	// generation увеличивается при каждом сбросе кэша. Выборка, начатая до сброса, могла прочитать
+	// данные до мутации, поэтому её результат в кэш не попадает.
+	generation *uint64
+}
+
+// cacheEntry — закэшированный список цитат и момент, после которого он считается устаревшим.
+type cacheEntry struct {
+	quotes    []storage.Quote
+	expiresAt time.Time
+}
+
+// NewQuoteCache создает кэш поверх сервиса с заданным TTL записей
+func NewQuoteCache(manipulator ServiceManipulator, getter ServiceGetter, ttl time.Duration) QuoteCache {
+	return QuoteCache{
+		Manipulator: manipulator,
+		Getter:      getter,
+		TTL:         ttl,
+
+		mu:         &sync.RWMutex{},
+		entries:    map[string]cacheEntry{},
+		generation: new(uint64),
+	}
+}
+
+// GetQuotes возвращает список цитат из кэша, а при промахе — из сервиса, сохраняя результат.
+// Вызывающий получает свою копию списка и может менять её, не портя кэш.
+func (c QuoteCache) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	key := cacheKey(filter)
+
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	generation := *c.generation
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return slices.Clone(entry.quotes), nil
+	}
+
+	quotes, err := c.Getter.GetQuotes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, generation, quotes)
+	return quotes, nil
+}
+
+// store сохраняет выборку под ключом key, если с её начала (generation) кэш не сбрасывался. Перед
+// записью удаляются устаревшие записи; если кэш и после этого полон, выборка не сохраняется.
+func (c QuoteCache) store(key string, generation uint64, quotes []storage.Quote) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if *c.generation != generation {
+		return
+	}
+
+	now := time.Now()
+	maps.DeleteFunc(c.entries, func(_ string, entry cacheEntry) bool {
+		return !now.Before(entry.expiresAt)
+	})
+
+	if len(c.entries) >= cacheMaxEntries {
+		return
+	}
+
+	c.entries[key] = cacheEntry{
+		quotes:    slices.Clone(quotes),
+		expiresAt: now.Add(c.TTL),
+	}
+}
+
+// cacheKey возвращает ключ кэша для фильтра. Фильтр кодируется в JSON: строки в кавычках и с
+// экранированием, поэтому разные фильтры не дают один ключ (как ?author=Lev Tolstoy и
+// ?author=Lev&author=Tolstoy при выводе через %+v).
+func cacheKey(filter storage.QuoteFilter) string {
+	key, _ := json.Marshal(filter)
+	return string(key)
+}
+
+// GetQuoteIDByUUID не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error) {
+	return c.Getter.GetQuoteIDByUUID(ctx, uuid)
+}
+
+// GetQuoteIDBySlug не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetQuoteIDBySlug(ctx context.Context, slug string) (int, error) {
+	return c.Getter.GetQuoteIDBySlug(ctx, slug)
+}
+
+// GetQuoteByID не кэшируется, чтобы каждый запрос засчитывался как просмотр
+func (c QuoteCache) GetQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	return c.Getter.GetQuoteByID(ctx, id)
+}
+
+// ExportQuotes не кэшируется: выгрузка читается из БД построчно
+func (c QuoteCache) ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error {
+	return c.Getter.ExportQuotes(ctx, filter, fn)
+}
+
+// GetStats не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetStats(ctx context.Context) (storage.Stats, error) {
+	return c.Getter.GetStats(ctx)
+}
+
+// GetTextStats не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetTextStats(ctx context.Context) (storage.TextStats, error) {
+	return c.Getter.GetTextStats(ctx)
+}
+
+// GetAuthors не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error) {
+	return c.Getter.GetAuthors(ctx, prefix, limit, offset)
+}
+
+// GetCategories не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetCategories(ctx context.Context) ([]storage.Category, error) {
+	return c.Getter.GetCategories(ctx)
+}
+
+// GetRecentQuotes не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error) {
+	return c.Getter.GetRecentQuotes(ctx, limit)
+}
+
+// GetQuotesByIDs не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error) {
+	return c.Getter.GetQuotesByIDs(ctx, ids)
+}
+
+// CountFilteredQuotes не кэшируется и всегда обращается к сервису
+func (c QuoteCache) CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error) {
+	return c.Getter.CountFilteredQuotes(ctx, filter)
+}
+
+// GetAuditLog не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error) {
+	return c.Getter.GetAuditLog(ctx, limit, offset)
+}
+
+// SearchQuotes не кэшируется и всегда обращается к сервису
+func (c QuoteCache) SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error) {
+	return c.Getter.SearchQuotes(ctx, query, limit)
+}
+
+// GetSimilarQuotes не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error) {
+	return c.Getter.GetSimilarQuotes(ctx, id, limit)
+}
+
+// GetFairRandomQuote не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetFairRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error) {
+	return c.Getter.GetFairRandomQuote(ctx, excludeAuthor, lang)
+}
+
+// GetRandomQuote не кэшируется и всегда обращается к сервису
+func (c QuoteCache) GetRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error) {
+	return c.Getter.GetRandomQuote(ctx, excludeAuthor, lang)
+}
+
+// QuoteLanguages не кэшируется и всегда обращается к сервису
+func (c QuoteCache) QuoteLanguages(ctx context.Context) ([]string, error) {
+	return c.Getter.QuoteLanguages(ctx)
+}
+
+// QuoteExists не кэшируется и всегда обращается к сервису
+func (c QuoteCache) QuoteExists(ctx context.Context, author string, quote string) (bool, error) {
+	return c.Getter.QuoteExists(ctx, author, quote)
+}
+
+// CountQuotes не кэшируется и всегда обращается к сервису
+func (c QuoteCache) CountQuotes(ctx context.Context, authorFilter string) (int, error) {
+	return c.Getter.CountQuotes(ctx, authorFilter)
+}
+
+// CreateQuote создает цитату и сбрасывает кэш
+func (c QuoteCache) CreateQuote(ctx context.Context, author string, quote string, lang string, source string) (int, error) {
+	id, err := c.Manipulator.CreateQuote(ctx, author, quote, lang, source)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Invalidate()
+	return id, nil
+}
+
+// CreateQuoteIdempotent создает цитату с ключом идемпотентности и сбрасывает кэш
+func (c QuoteCache) CreateQuoteIdempotent(ctx context.Context, key string, author string, quote string, lang string, source string) (int, error) {
+	id, err := c.Manipulator.CreateQuoteIdempotent(ctx, key, author, quote, lang, source)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Invalidate()
+	return id, nil
+}
+
+// UpsertQuote создает цитату и сбрасывает кэш, если цитата действительно добавлена
+func (c QuoteCache) UpsertQuote(ctx context.Context, author string, quote string, lang string, source string) (int, bool, error) {
+	id, existing, err := c.Manipulator.UpsertQuote(ctx, author, quote, lang, source)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !existing {
+		c.Invalidate()
+	}
+	return id, existing, nil
+}
+
+// ImportQuotes импортирует цитаты и сбрасывает кэш, если хотя бы одна добавлена. Итоги отклоненного
+// строгого импорта возвращаются вместе с ошибкой: в них строка, из-за которой импорт отменен.
+func (c QuoteCache) ImportQuotes(ctx context.Context, rows []ImportRow, options ImportOptions) ([]ImportResult, error) {
+	results, err := c.Manipulator.ImportQuotes(ctx, rows, options)
+	if err != nil {
+		return results, err
+	}
+
+	for _, result := range results {
+		if result.Status == ImportInserted {
+			c.Invalidate()
+			break
+		}
+	}
+	return results, nil
+}
+
+// DeleteQuoteByID удаляет цитату и сбрасывает кэш
+func (c QuoteCache) DeleteQuoteByID(ctx context.Context, id int) error {
+	err := c.Manipulator.DeleteQuoteByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c.Invalidate()
+	return nil
+}
+
+// PurgeQuotes удаляет все цитаты и сбрасывает кэш
+func (c QuoteCache) PurgeQuotes(ctx context.Context) (int, error) {
+	count, err := c.Manipulator.PurgeQuotes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Invalidate()
+	return count, nil
+}
+
+// RenameAuthor переименовывает автора и сбрасывает кэш
+func (c QuoteCache) RenameAuthor(ctx context.Context, from string, to string) (int, error) {
+	renamed, err := c.Manipulator.RenameAuthor(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Invalidate()
+	return renamed, nil
+}
+
+// CreateCategory создает категорию; кэш не сбрасывается, так как новая категория пуста
+func (c QuoteCache) CreateCategory(ctx context.Context, name string) (int, error) {
+	return c.Manipulator.CreateCategory(ctx, name)
+}
+
+// AssignCategory относит цитату к категории и сбрасывает кэш
+func (c QuoteCache) AssignCategory(ctx context.Context, quoteID int, categoryID int) error {
+	err := c.Manipulator.AssignCategory(ctx, quoteID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	c.Invalidate()
+	return nil
+}
+
+// RestoreQuoteByID восстанавливает цитату и сбрасывает кэш
+func (c QuoteCache) RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	quote, err := c.Manipulator.RestoreQuoteByID(ctx, id)
+	if err != nil {
+		return storage.Quote{}, err
+	}
+
+	c.Invalidate()
+	return quote, nil
+}
+
+// LikeQuoteByID увеличивает счетчик лайков и сбрасывает кэш, так как от лайков зависят списки
+func (c QuoteCache) LikeQuoteByID(ctx context.Context, id int) (int, error) {
+	likes, err := c.Manipulator.LikeQuoteByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Invalidate()
+	return likes, nil
+}
+
+// Invalidate полностью очищает кэш и отбрасывает результаты выборок, начатых до сброса
+func (c QuoteCache) Invalidate() {
+	c.mu.Lock()
+	clear(c.entries)
+	*c.generation++
+	c.mu.Unlock()
+}
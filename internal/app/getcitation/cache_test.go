@@ -0,0 +1,247 @@
+package getcitation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+)
+
+func TestCacheKeyDistinguishesFilters(t *testing.T) {
+	filters := []storage.QuoteFilter{
+		{Authors: []string{"Lev Tolstoy"}},
+		{Authors: []string{"Lev", "Tolstoy"}},
+		{Authors: []string{"Lev Tolstoy"}, IncludeDeleted: true},
+		{Authors: []string{"Lev] IncludeDeleted:true"}},
+		{Authors: []string{""}},
+		{},
+	}
+
+	seen := map[string]storage.QuoteFilter{}
+	for _, filter := range filters {
+		key := cacheKey(filter)
+		if other, ok := seen[key]; ok {
+			t.Errorf("cacheKey(%+v) = cacheKey(%+v) = %q", filter, other, key)
+		}
+		seen[key] = filter
+	}
+}
+
+func TestQuoteCacheAuthorsDoNotCollide(t *testing.T) {
+	store := &fakeStore{}
+	store.add(
+		storage.Quote{Author: "Lev Tolstoy", Quote: "All happy families are alike"},
+		storage.Quote{Author: "Lev", Quote: "Short name"},
+		storage.Quote{Author: "Tolstoy", Quote: "Surname only"},
+	)
+
+	cfg := testConfig()
+	cfg.CacheTTL = time.Minute
+	handler := newTestApp(t, cfg, store)
+
+	tests := []struct {
+		query url.Values
+		want  int
+	}{
+		{url.Values{"author": {"Lev Tolstoy"}}, 1},
+		{url.Values{"author": {"Lev", "Tolstoy"}}, 2},
+	}
+
+	for _, tt := range tests {
+		w := serve(handler, http.MethodGet, "/quotes?"+tt.query.Encode(), "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /quotes?%s status = %d, want %d: %s", tt.query.Encode(), w.Code, http.StatusOK, w.Body)
+		}
+
+		var response GetQuotesResponse
+		decode(t, w, &response)
+		if len(response.Quotes) != tt.want {
+			t.Errorf("GET /quotes?%s returned %d quotes, want %d", tt.query.Encode(), len(response.Quotes), tt.want)
+		}
+	}
+}
+
+// fakeGetter — сервис чтения для тестов кэша: считает вызовы GetQuotes. Остальные методы паникуют.
+type fakeGetter struct {
+	ServiceGetter
+
+	mu     sync.Mutex
+	calls  int
+	quotes []storage.Quote
+
+	// onGetQuotes, если задана, вызывается в начале GetQuotes
+	onGetQuotes func()
+}
+
+func (g *fakeGetter) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	if g.onGetQuotes != nil {
+		g.onGetQuotes()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls++
+	return slices.Clone(g.quotes), nil
+}
+
+// fetches возвращает, сколько раз кэш обратился к сервису
+func (g *fakeGetter) fetches() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.calls
+}
+
+// fakeManipulator — сервис записи для тестов кэша: мутации всегда успешны
+type fakeManipulator struct {
+	ServiceManipulator
+}
+
+func (fakeManipulator) CreateQuote(ctx context.Context, author string, quote string, lang string, source string) (int, error) {
+	return 1, nil
+}
+
+func (fakeManipulator) DeleteQuoteByID(ctx context.Context, id int) error {
+	return nil
+}
+
+func (fakeManipulator) LikeQuoteByID(ctx context.Context, id int) (int, error) {
+	return 1, nil
+}
+
+// mustGetQuotes читает список цитат через кэш
+func mustGetQuotes(t *testing.T, cache QuoteCache, filter storage.QuoteFilter) []storage.Quote {
+	t.Helper()
+
+	quotes, err := cache.GetQuotes(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetQuotes() error = %v", err)
+	}
+	return quotes
+}
+
+func TestQuoteCacheHit(t *testing.T) {
+	getter := &fakeGetter{quotes: []storage.Quote{{ID: 1, Author: "Seneca", Quote: "While we teach, we learn"}}}
+	cache := NewQuoteCache(fakeManipulator{}, getter, time.Minute)
+
+	first := mustGetQuotes(t, cache, storage.QuoteFilter{})
+	first[0].Quote = "changed by the caller"
+
+	second := mustGetQuotes(t, cache, storage.QuoteFilter{})
+	if got := getter.fetches(); got != 1 {
+		t.Errorf("service queried %d times, want 1", got)
+	}
+	if second[0].Quote != "While we teach, we learn" {
+		t.Errorf("cached quote = %q, the caller's change leaked into the cache", second[0].Quote)
+	}
+}
+
+func TestQuoteCacheExpiry(t *testing.T) {
+	getter := &fakeGetter{}
+	cache := NewQuoteCache(fakeManipulator{}, getter, time.Millisecond)
+
+	mustGetQuotes(t, cache, storage.QuoteFilter{})
+	time.Sleep(10 * time.Millisecond)
+	mustGetQuotes(t, cache, storage.QuoteFilter{})
+
+	if got := getter.fetches(); got != 2 {
+		t.Errorf("service queried %d times, want 2", got)
+	}
+}
+
+func TestQuoteCacheEvictsExpiredOnWrite(t *testing.T) {
+	getter := &fakeGetter{}
+	cache := NewQuoteCache(fakeManipulator{}, getter, time.Millisecond)
+
+	for i := range 3 {
+		mustGetQuotes(t, cache, storage.QuoteFilter{Authors: []string{fmt.Sprint(i)}})
+	}
+	time.Sleep(10 * time.Millisecond)
+	mustGetQuotes(t, cache, storage.QuoteFilter{})
+
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	if len(cache.entries) != 1 {
+		t.Errorf("cache holds %d entries, want 1", len(cache.entries))
+	}
+}
+
+func TestQuoteCacheInvalidatedByMutations(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cache QuoteCache) error
+	}{
+		{"create", func(cache QuoteCache) error {
+			_, err := cache.CreateQuote(context.Background(), "Seneca", "While we teach, we learn", "", "")
+			return err
+		}},
+		{"delete", func(cache QuoteCache) error {
+			return cache.DeleteQuoteByID(context.Background(), 1)
+		}},
+		{"like", func(cache QuoteCache) error {
+			_, err := cache.LikeQuoteByID(context.Background(), 1)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := &fakeGetter{}
+			cache := NewQuoteCache(fakeManipulator{}, getter, time.Minute)
+
+			mustGetQuotes(t, cache, storage.QuoteFilter{})
+
+			err := tt.mutate(cache)
+			if err != nil {
+				t.Fatalf("mutation error = %v", err)
+			}
+
+			mustGetQuotes(t, cache, storage.QuoteFilter{})
+			if got := getter.fetches(); got != 2 {
+				t.Errorf("service queried %d times, want 2", got)
+			}
+		})
+	}
+}
+
+func TestQuoteCacheDropsFillStartedBeforeMutation(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	// Задерживается только первая выборка: мутация случается, пока она идет
+	var once sync.Once
+	getter := &fakeGetter{onGetQuotes: func() {
+		once.Do(func() {
+			entered <- struct{}{}
+			<-release
+		})
+	}}
+	cache := NewQuoteCache(fakeManipulator{}, getter, time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.GetQuotes(context.Background(), storage.QuoteFilter{})
+	}()
+
+	<-entered
+	_, err := cache.CreateQuote(context.Background(), "Seneca", "While we teach, we learn", "", "")
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	close(release)
+	<-done
+
+	// Выборка прочитала данные до мутации, поэтому не должна была попасть в кэш
+	mustGetQuotes(t, cache, storage.QuoteFilter{})
+	if got := getter.fetches(); got != 2 {
+		t.Errorf("service queried %d times, want 2: a fill started before the mutation was cached", got)
+	}
+}
@@ -0,0 +1,364 @@
+package getcitation
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"getcitation/internal/storage"
+)
+
+// Сообщения об ошибках категорий
+const (
+	messageCategoryExists      string = "A category with this name already exists"
+	messageCategoryNotFound    string = "No category with this ID"
+	messageMalformedCategory   string = "category parameter must be a positive integer"
+	messageMalformedCategoryID string = "category_id must be a positive integer"
+)
+
+// GetCategoriesResponse описывает формат ответа со списком категорий
+type GetCategoriesResponse struct {
+	Status     Status             `json:"status"`
+	Categories []storage.Category `json:"categories"`
+}
+
+// CreateCategoryRequest описывает формат запроса на создание категории
+type CreateCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateCategoryResponse описывает формат ответа при создании категории
+type CreateCategoryResponse struct {
+	Status Status `json:"status"`
+	ID     int    `json:"id"`
+}
+
+// AssignCategoryRequest описывает формат запроса на отнесение цитаты к категории
+type AssignCategoryRequest struct {
+	CategoryID int `json:"category_id"`
+}
+
+// AssignCategoryResponse описывает формат ответа при отнесении цитаты к категории
+type AssignCategoryResponse struct {
+	Status     Status `json:"status"`
+	ID         int    `json:"id"`
+	CategoryID int    `json:"category_id"`
+}
+
+// Categories обрабатывает HTTP запросы к категориям: список (GET) и создание (POST)
+func (h Handlers) Categories(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.Categories()"
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetCategories(w, r)
+
+	case http.MethodPost:
+		h.CreateCategory(w, r)
+
+	default:
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+	}
+}
+
+// GetCategories обрабатывает HTTP GET запрос на получение всех категорий по алфавиту с числом цитат в каждой
+func (h Handlers) GetCategories(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetCategories()"
+
+	categories, err := h.Getter.GetCategories(r.Context())
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, GetCategoriesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Categories: categories,
+	})
+}
+
+// CreateCategory обрабатывает HTTP POST запрос на создание категории цитат
+func (h Handlers) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.CreateCategory()"
+
+	if !isJSON(r) {
+		h.Log.Error(
+			errUnsupportedMedia,
+			slog.String("op", op),
+			slog.String("content_type", r.Header.Get("Content-Type")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+			Status: Status{
+				Code:    http.StatusUnsupportedMediaType,
+				Message: errUnsupportedMedia,
+			},
+			Message: messageJSONRequired,
+		})
+
+		return
+	}
+
+	limitBody(w, r, h.Config.MaxBodyBytes)
+
+	var req CreateCategoryRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if h.rejectLargeBody(w, r, op, err) {
+		return
+	}
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+		})
+
+		return
+	}
+	defer r.Body.Close()
+
+	id, err := h.Manipulator.CreateCategory(r.Context(), req.Name)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, ValidationErrorResponse{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageValidationFailed,
+				Errors:  validationErr.Fields,
+			})
+
+			return
+		}
+		if errors.Is(err, ErrDuplicateEntry) {
+			h.Log.Error(
+				errConflict,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusConflict, Error{
+				Status: Status{
+					Code:    http.StatusConflict,
+					Message: errConflict,
+				},
+				Message: messageCategoryExists,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.Log.Info(
+		"категория создана",
+		slog.String("op", op),
+		slog.Int("id", id),
+		slog.String("name", req.Name),
+	)
+
+	h.writeJSON(w, http.StatusOK, CreateCategoryResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		ID: id,
+	})
+}
+
+// AssignQuoteCategory обрабатывает HTTP POST запрос на отнесение цитаты к категории. Повторное отнесение
+// к той же категории не считается ошибкой.
+func (h Handlers) AssignQuoteCategory(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.AssignQuoteCategory()"
+
+	if r.Method != http.MethodPost {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	id, err := h.parseQuoteID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	if !isJSON(r) {
+		h.Log.Error(
+			errUnsupportedMedia,
+			slog.String("op", op),
+			slog.String("content_type", r.Header.Get("Content-Type")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+			Status: Status{
+				Code:    http.StatusUnsupportedMediaType,
+				Message: errUnsupportedMedia,
+			},
+			Message: messageJSONRequired,
+		})
+
+		return
+	}
+
+	limitBody(w, r, h.Config.MaxBodyBytes)
+
+	var req AssignCategoryRequest
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if h.rejectLargeBody(w, r, op, err) {
+		return
+	}
+	if err != nil || req.CategoryID < 1 {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedCategoryID,
+		})
+
+		return
+	}
+	defer r.Body.Close()
+
+	err = h.Manipulator.AssignCategory(r.Context(), id, req.CategoryID)
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) || errors.Is(err, ErrNoCategory) {
+			message := messageQuoteNotFoundByID
+			if errors.Is(err, ErrNoCategory) {
+				message = messageCategoryNotFound
+			}
+
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: message,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, AssignCategoryResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		ID:         id,
+		CategoryID: req.CategoryID,
+	})
+}
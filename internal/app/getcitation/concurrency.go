@@ -0,0 +1,59 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// overloadRetryAfter — через сколько секунд клиенту стоит повторить запрос, отклоненный из-за перегрузки
+const overloadRetryAfter int = 1
+
+// messageOverloaded — сообщение ответа 503, когда все места для одновременных запросов заняты
+const messageOverloaded string = "Too many concurrent requests, retry later"
+
+// LimitConcurrency пропускает не больше MAX_CONCURRENT_REQUESTS запросов одновременно. Запрос сверх
+// предела не ждет в очереди, а сразу получает 503 с Retry-After, поэтому под нагрузкой сервис не копит
+// горутины, ожидающие соединения с БД. Долгоживущие маршруты (exempt) не занимают места: иначе
+// подписчики SSE вытеснили бы обычные запросы. С нулевым MAX_CONCURRENT_REQUESTS пропускает все запросы.
+func (h Handlers) LimitConcurrency(next http.Handler, exempt ...string) http.Handler {
+	const op = "getcitation.Transport.LimitConcurrency()"
+
+	if h.Config.MaxConcurrentRequests <= 0 {
+		return next
+	}
+
+	slots := make(chan struct{}, h.Config.MaxConcurrentRequests)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(exempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			h.Log.Warn(
+				"достигнут предел одновременных запросов",
+				slog.String("op", op),
+				slog.Int("limit", h.Config.MaxConcurrentRequests),
+				slog.String("path", r.URL.Path),
+			)
+
+			w.Header().Set("Retry-After", strconv.Itoa(overloadRetryAfter))
+			w.Header().Set("Cache-Control", cacheControlNoStore)
+
+			h.writeJSON(w, http.StatusServiceUnavailable, Error{
+				Status: Status{
+					Code:    http.StatusServiceUnavailable,
+					Message: errServiceUnavailable,
+				},
+				Message: messageOverloaded,
+			})
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package getcitation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLimitConcurrency(t *testing.T) {
+	const limit = 3
+
+	cfg := testConfig()
+	cfg.MaxConcurrentRequests = limit
+
+	h := Handlers{Log: testLogger(), Config: cfg}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := h.LimitConcurrency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}), "/quotes/stream")
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// Занимаем все места запросами, которые ждут release
+	var wg sync.WaitGroup
+	codes := make(chan int, limit)
+	for range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve("/slow").Code
+		}()
+	}
+	for range limit {
+		<-entered
+	}
+
+	for range 5 {
+		w := serve("/quotes")
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status over the limit = %d, want %d", w.Code, http.StatusServiceUnavailable)
+		}
+		if retryAfter := w.Header().Get("Retry-After"); retryAfter != "1" {
+			t.Errorf("Retry-After = %q, want 1", retryAfter)
+		}
+
+		var response Error
+		decode(t, w, &response)
+		if response.Status.Message != errServiceUnavailable || response.Message != messageOverloaded {
+			t.Errorf("response = %+v, want %q with %q", response, errServiceUnavailable, messageOverloaded)
+		}
+	}
+
+	if w := serve("/quotes/stream"); w.Code != http.StatusOK {
+		t.Errorf("exempt route status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("status within the limit = %d, want %d", code, http.StatusOK)
+		}
+	}
+
+	if w := serve("/quotes"); w.Code != http.StatusOK {
+		t.Errorf("status after slots are freed = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestLimitConcurrencyProbesExempt(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxConcurrentRequests = 1
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	// Задерживается только первая выборка: обработчик еще считает цитаты через GetQuotes
+	var once sync.Once
+	store := &fakeStore{onGetQuotes: func() {
+		once.Do(func() {
+			entered <- struct{}{}
+			<-release
+		})
+	}}
+
+	handler := newTestApp(t, cfg, store)
+
+	// Единственное место занимает список цитат, который ждет release
+	done := make(chan int)
+	go func() {
+		done <- serve(handler, http.MethodGet, "/quotes", "").Code
+	}()
+	<-entered
+
+	if w := serve(handler, http.MethodGet, "/quotes/random", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /quotes/random status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	// Пробы оркестратора обслуживаются в обход ограничителя
+	for _, path := range []string{"/ready", "/version"} {
+		if w := serve(handler, http.MethodGet, path, ""); w.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d: %s", path, w.Code, http.StatusOK, w.Body)
+		}
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("GET /quotes status = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,81 @@
+package getcitation
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Заголовки CORS: методы и заголовки запросов, которые использует API, и заголовки ответа,
+// которые браузер должен показать скрипту
+const (
+	corsAllowMethods  string = "GET, HEAD, POST, PATCH, DELETE"
+	corsAllowHeaders  string = "Authorization, Content-Type, Idempotency-Key, If-None-Match"
+	corsExposeHeaders string = "ETag"
+)
+
+// CORS разрешает запросы из браузера с источников из CORS_ALLOWED_ORIGINS (* — с любых). На preflight
+// (OPTIONS с Access-Control-Request-Method) отвечает сам, не передавая запрос дальше, поэтому ставится
+// перед Authenticate: браузер не шлет токен в preflight. CORS_MAX_AGE позволяет браузеру кэшировать
+// ответ на preflight и не повторять его перед каждым запросом. CORS_ALLOWED_HEADERS дополняет список
+// разрешенных заголовков запроса, а CORS_ALLOW_CREDENTIALS разрешает cookie и Authorization; браузер
+// принимает такой ответ только с явным источником, поэтому вместе с ним * не допускается (см. config).
+// Без CORS_ALLOWED_ORIGINS пропускает все запросы как есть.
+func (h Handlers) CORS(next http.Handler) http.Handler {
+	if len(h.Config.CORSAllowedOrigins) == 0 {
+		return next
+	}
+
+	origins := make([]string, 0, len(h.Config.CORSAllowedOrigins))
+	for _, origin := range h.Config.CORSAllowedOrigins {
+		origins = append(origins, strings.TrimSpace(origin))
+	}
+	anyOrigin := slices.Contains(origins, "*") && !h.Config.CORSAllowCredentials
+
+	allowHeaders := corsAllowHeaders
+	for _, header := range h.Config.CORSAllowedHeaders {
+		if header = strings.TrimSpace(header); header != "" {
+			allowHeaders += ", " + header
+		}
+	}
+
+	// Access-Control-Max-Age задается в целых секундах
+	maxAge := int(h.Config.CORSMaxAge.Seconds())
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+
+		w.Header().Add("Vary", "Origin")
+
+		if origin == "" || (!anyOrigin && !slices.Contains(origins, origin)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if h.Config.CORSAllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+		w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		if maxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(maxAge))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
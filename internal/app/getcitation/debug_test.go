@@ -0,0 +1,61 @@
+package getcitation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorDebug(t *testing.T) {
+	storeErr := fmt.Errorf("sqlite.GetQuoteByID(): %w", errors.New("disk I/O error"))
+
+	tests := []struct {
+		mode   string
+		expose bool
+		want   bool
+	}{
+		{"local", true, true},
+		{"dev", true, true},
+		{"prod", true, false},
+		{"local", false, false},
+		{"dev", false, false},
+		{"prod", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s expose=%t", tt.mode, tt.expose), func(t *testing.T) {
+			cfg := testConfig()
+			cfg.AppLogMode = tt.mode
+			cfg.ExposeInternalErrors = tt.expose
+
+			handler := newTestApp(t, cfg, &fakeStore{err: storeErr})
+
+			w := serve(handler, http.MethodGet, "/quotes/1", "")
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+
+			if !tt.want {
+				if response.Debug != nil || strings.Contains(w.Body.String(), "disk I/O error") {
+					t.Errorf("body = %s, want no internal error details", w.Body)
+				}
+				return
+			}
+
+			if response.Debug == nil {
+				t.Fatalf("body = %s, want debug details", w.Body)
+			}
+			if response.Debug.Op != "getcitation.Transport.GetQuoteByID()" {
+				t.Errorf("debug op = %q, want the handler op", response.Debug.Op)
+			}
+			if !strings.Contains(response.Debug.Error, storeErr.Error()) {
+				t.Errorf("debug error = %q, want it to contain the op chain %q", response.Debug.Error, storeErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package getcitation
+
+import (
+	"net/http"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestDeleteQuoteByID(t *testing.T) {
+	for _, prefix := range []string{"", "/api/v1"} {
+		t.Run("prefix "+prefix, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.RoutePrefix = prefix
+
+			store := &fakeStore{}
+			store.add(
+				storage.Quote{Author: "Seneca", Quote: "Luck is what happens"},
+				storage.Quote{Author: "Confucius", Quote: "Life is simple"},
+			)
+
+			handler := newTestApp(t, cfg, store)
+
+			// Неизвестное действие над цитатой не удаляет ее
+			if w := serve(handler, http.MethodDelete, prefix+"/quotes/1/unknown", ""); w.Code != http.StatusNotFound {
+				t.Errorf("DELETE /quotes/1/unknown status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+			}
+			if store.quotes[0].DeletedAt != nil {
+				t.Fatalf("DELETE /quotes/1/unknown deleted quote 1")
+			}
+
+			w := serve(handler, http.MethodDelete, prefix+"/quotes/2", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("DELETE /quotes/2 status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if store.quotes[1].DeletedAt == nil || store.quotes[0].DeletedAt != nil {
+				t.Errorf("DELETE /quotes/2 did not delete exactly quote 2")
+			}
+
+			if w := serve(handler, http.MethodDelete, prefix+"/quotes/2", ""); w.Code != http.StatusNotFound {
+				t.Errorf("repeated DELETE status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+			}
+
+			w = serve(handler, http.MethodDelete, prefix+"/quotes/a/b/c", "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("DELETE without an ID status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+			var response Error
+			decode(t, w, &response)
+			if response.Message != messageNoID {
+				t.Errorf("DELETE without an ID message = %q, want %q", response.Message, messageNoID)
+			}
+		})
+	}
+}
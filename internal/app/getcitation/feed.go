@@ -0,0 +1,172 @@
+package getcitation
+
+import (
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// feedTitle и feedDescription — заголовок и описание канала RSS
+const (
+	feedTitle       = "getcitation"
+	feedDescription = "Recently added quotes"
+)
+
+// RSS — корневой элемент документа RSS 2.0
+type RSS struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+// RSSChannel — канал RSS. LastBuildDate — время добавления самой новой цитаты; пустой, если цитат нет.
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []RSSItem `xml:"item"`
+}
+
+// RSSItem — цитата в ленте: заголовок — автор, описание — текст цитаты
+type RSSItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category,omitempty"`
+	GUID        RSSGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+// RSSGUID — постоянный идентификатор элемента ленты. Это не URL, поэтому isPermaLink="false".
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// feedBaseURL строит абсолютный URL списка цитат по запросу: RSS требует абсолютных ссылок.
+func (h Handlers) feedBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return (&url.URL{Scheme: scheme, Host: r.Host, Path: h.Config.RoutePrefix + "/quotes"}).String()
+}
+
+// newRSS собирает ленту из цитат, начиная с самой новой. Ссылки на цитаты строятся так же, как пути
+// /quotes/{id}: по UUID при QUOTE_ID_TYPE=uuid, иначе по числовому ID.
+func (h Handlers) newRSS(base string, quotes []storage.Quote) RSS {
+	channel := RSSChannel{
+		Title:       feedTitle,
+		Link:        base,
+		Description: feedDescription,
+		Items:       make([]RSSItem, 0, len(quotes)),
+	}
+
+	for _, quote := range quotes {
+		id := strconv.Itoa(quote.ID)
+		if h.Config.QuoteIDType == config.QuoteIDUUID && quote.UUID != "" {
+			id = quote.UUID
+		}
+
+		item := RSSItem{
+			Title:       quote.Author,
+			Link:        base + "/" + id,
+			Description: quote.Quote,
+			Category:    quote.Language,
+			GUID:        RSSGUID{Value: "quote-" + id},
+		}
+		if quote.CreatedAt != nil {
+			item.PubDate = quote.CreatedAt.UTC().Format(time.RFC1123Z)
+			if channel.LastBuildDate == "" {
+				channel.LastBuildDate = item.PubDate
+			}
+		}
+
+		channel.Items = append(channel.Items, item)
+	}
+
+	return RSS{Version: "2.0", Channel: channel}
+}
+
+// GetQuotesFeed обрабатывает HTTP GET запрос на ленту RSS 2.0 последних добавленных цитат, начиная с самой новой.
+// Количество задается параметром limit, как у /quotes/recent. Ошибки, как у /quotes/random.txt, приходят
+// обычным текстом.
+func (h Handlers) GetQuotesFeed(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetQuotesFeed()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeText(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	limit := defaultRecentLimit
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("limit", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeText(w, http.StatusBadRequest, messagePositiveLimit)
+			return
+		}
+		limit = min(limit, maxRecentLimit)
+	}
+
+	quotes, err := h.Getter.GetRecentQuotes(r.Context(), limit)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeText(w, code, message)
+		return
+	}
+
+	body, err := xml.MarshalIndent(h.newRSS(h.feedBaseURL(r), quotes), "", "  ")
+	if err != nil {
+		h.Log.Error(
+			errInternalServerError,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeText(w, http.StatusInternalServerError, errInternalServerError)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+
+	w.Write(body)
+}
@@ -0,0 +1,146 @@
+package getcitation
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+)
+
+// rssDocument — проверяемая часть RSS 2.0: разбирается независимо от типов RSS, чтобы тест ловил
+// расхождения со спецификацией, а не только с собственной структурой
+type rssDocument struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel []struct {
+		Title         []string `xml:"title"`
+		Link          []string `xml:"link"`
+		Description   []string `xml:"description"`
+		LastBuildDate string   `xml:"lastBuildDate"`
+		Items         []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+			GUID        struct {
+				IsPermaLink string `xml:"isPermaLink,attr"`
+				Value       string `xml:",chardata"`
+			} `xml:"guid"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func TestGetQuotesFeed(t *testing.T) {
+	older := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	store := &fakeStore{}
+	store.add(
+		storage.Quote{Author: "Confucius", Quote: "Life is simple", Language: "en", CreatedAt: &older},
+		storage.Quote{Author: "Tom & Jerry", Quote: `<b>"Cats" & mice</b>`, CreatedAt: &newer},
+	)
+
+	handler := newTestApp(t, testConfig(), store)
+
+	w := serve(handler, http.MethodGet, "/quotes/feed.rss", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/rss+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want application/rss+xml; charset=utf-8", contentType)
+	}
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, xml.Header) {
+		t.Errorf("body does not start with the XML declaration: %s", body)
+	}
+	if strings.Contains(body, "<b>") || !strings.Contains(body, "&lt;b&gt;") {
+		t.Errorf("quote markup is not escaped: %s", body)
+	}
+
+	var doc rssDocument
+	err := xml.Unmarshal(w.Body.Bytes(), &doc)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v: %s", err, body)
+	}
+
+	// RSS 2.0: версия 2.0, ровно один channel с обязательными title, link и description
+	if doc.Version != "2.0" {
+		t.Errorf("rss version = %q, want 2.0", doc.Version)
+	}
+	if len(doc.Channel) != 1 {
+		t.Fatalf("document has %d channels, want 1", len(doc.Channel))
+	}
+	channel := doc.Channel[0]
+	if len(channel.Title) != 1 || len(channel.Link) != 1 || len(channel.Description) != 1 {
+		t.Fatalf("channel = %+v, want one title, link and description each", channel)
+	}
+	if u, err := url.Parse(channel.Link[0]); err != nil || !u.IsAbs() {
+		t.Errorf("channel link = %q, want an absolute URL", channel.Link[0])
+	}
+
+	wantItems := []struct {
+		title       string
+		description string
+		link        string
+		pubDate     time.Time
+	}{
+		{"Tom & Jerry", `<b>"Cats" & mice</b>`, "http://example.com/quotes/2", newer},
+		{"Confucius", "Life is simple", "http://example.com/quotes/1", older},
+	}
+	if len(channel.Items) != len(wantItems) {
+		t.Fatalf("channel has %d items, want %d", len(channel.Items), len(wantItems))
+	}
+
+	for i, want := range wantItems {
+		item := channel.Items[i]
+
+		if item.Title != want.title || item.Description != want.description || item.Link != want.link {
+			t.Errorf("item %d = %+v, want %q, %q, %q", i, item, want.title, want.description, want.link)
+		}
+
+		pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
+		if err != nil || !pubDate.Equal(want.pubDate) {
+			t.Errorf("item %d pubDate = %q, want %s in RFC 822 format", i, item.PubDate, want.pubDate)
+		}
+
+		if item.GUID.Value == "" || item.GUID.IsPermaLink != "false" {
+			t.Errorf("item %d guid = %+v, want a value with isPermaLink=false", i, item.GUID)
+		}
+	}
+
+	if channel.LastBuildDate != channel.Items[0].PubDate {
+		t.Errorf("lastBuildDate = %q, want the newest pubDate %q", channel.LastBuildDate, channel.Items[0].PubDate)
+	}
+}
+
+func TestGetQuotesFeedEmpty(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	w := serve(handler, http.MethodGet, "/quotes/feed.rss", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var doc rssDocument
+	err := xml.Unmarshal(w.Body.Bytes(), &doc)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v: %s", err, w.Body)
+	}
+	if len(doc.Channel) != 1 || len(doc.Channel[0].Items) != 0 || doc.Channel[0].LastBuildDate != "" {
+		t.Errorf("empty feed = %+v, want one channel without items and lastBuildDate", doc)
+	}
+}
+
+func TestGetQuotesFeedBadLimit(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	w := serve(handler, http.MethodGet, "/quotes/feed.rss?limit=0", "")
+	if w.Code != http.StatusBadRequest || strings.TrimSpace(w.Body.String()) != messagePositiveLimit {
+		t.Errorf("response = %d %q, want %d %q", w.Code, w.Body, http.StatusBadRequest, messagePositiveLimit)
+	}
+}
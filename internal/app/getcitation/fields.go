@@ -0,0 +1,89 @@
+package getcitation
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"getcitation/internal/storage"
+)
+
+// quoteFields — поля цитаты, которые можно запросить параметром fields, в порядке storage.Quote
+var quoteFields = []string{"id", "uuid", "slug", "author", "quote", "likes", "views", "language", "source", "deleted_at"}
+
+// parseFields разбирает параметр fields (имена полей через запятую). Без параметра возвращает nil —
+// отдаются все поля. Неизвестное имя поля — ошибка, пустой список тоже.
+func parseFields(query url.Values) ([]string, error) {
+	const op = "getcitation.parseFields()"
+
+	if !query.Has("fields") {
+		return nil, nil
+	}
+
+	var fields []string
+
+	for _, raw := range query["fields"] {
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if !slices.Contains(quoteFields, field) {
+				return nil, fmt.Errorf("%s: unknown field %q", op, field)
+			}
+			if !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s: no fields selected", op)
+	}
+	return fields, nil
+}
+
+// selectFields оставляет в каждой цитате только перечисленные поля. language, source и deleted_at, как и в полном
+// ответе, пропускаются, если не заданы.
+func selectFields(quotes []storage.Quote, fields []string) []map[string]any {
+	selected := make([]map[string]any, 0, len(quotes))
+
+	for _, quote := range quotes {
+		view := make(map[string]any, len(fields))
+
+		for _, field := range fields {
+			switch field {
+			case "id":
+				view[field] = quote.ID
+			case "uuid":
+				view[field] = quote.UUID
+			case "slug":
+				view[field] = quote.Slug
+			case "author":
+				view[field] = quote.Author
+			case "quote":
+				view[field] = quote.Quote
+			case "likes":
+				view[field] = quote.Likes
+			case "views":
+				view[field] = quote.Views
+			case "language":
+				if quote.Language != "" {
+					view[field] = quote.Language
+				}
+			case "source":
+				if quote.Source != "" {
+					view[field] = quote.Source
+				}
+			case "deleted_at":
+				if quote.DeletedAt != nil {
+					view[field] = quote.DeletedAt
+				}
+			}
+		}
+
+		selected = append(selected, view)
+	}
+	return selected
+}
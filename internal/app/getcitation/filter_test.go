@@ -0,0 +1,77 @@
+package getcitation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestRejectLongFilter(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxFilterLength = 8
+
+	// Хранилище отвечает ошибкой на любой запрос: 400 означает, что до БД дело не дошло
+	handler := newTestApp(t, cfg, &fakeStore{err: errors.New("database must not be queried")})
+
+	long := url.QueryEscape(strings.Repeat("a", 1<<20))
+
+	tests := []struct {
+		target string
+		param  string
+	}{
+		{"/quotes?author=" + long, "author"},
+		{"/quotes?author=Seneca&author=" + long, "author"},
+		{"/quotes/count?author=" + long, "author"},
+		{"/quotes/search?q=" + long, "q"},
+		{"/quotes/random?exclude_author=" + long, "exclude_author"},
+		{"/quotes/export?author=" + long, "author"},
+		{"/authors?prefix=" + long, "prefix"},
+	}
+
+	for _, tt := range tests {
+		path, _, _ := strings.Cut(tt.target, "?")
+		t.Run(path+" "+tt.param, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, tt.target, "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if want := fmt.Sprintf(messageFilterTooLong, tt.param, cfg.MaxFilterLength); response.Message != want {
+				t.Errorf("message = %q, want %q", response.Message, want)
+			}
+		})
+	}
+}
+
+func TestRejectLongFilterCountsCharacters(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxFilterLength = 6
+
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Сенека", Quote: "Пока мы учим, мы учимся"})
+
+	handler := newTestApp(t, cfg, store)
+
+	// Шесть кириллических букв — двенадцать байт, но в пределах шести символов
+	w := serve(handler, http.MethodGet, "/quotes?author="+url.QueryEscape("Сенека"), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status at the limit = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var response GetQuotesResponse
+	decode(t, w, &response)
+	if len(response.Quotes) != 1 {
+		t.Errorf("got %d quotes, want 1", len(response.Quotes))
+	}
+
+	if w := serve(handler, http.MethodGet, "/quotes?author="+url.QueryEscape("Сенека!"), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("status over the limit = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
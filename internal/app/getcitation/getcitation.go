@@ -2,16 +2,29 @@ package getcitation
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
+	"net"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
-	storage "getcitation/internal/storage/postgresql"
+	"getcitation/internal/lib/broadcaster"
+	"getcitation/internal/lib/webhook"
+	"getcitation/internal/storage"
+	"getcitation/internal/utils"
 	"getcitation/internal/utils/config"
 )
 
@@ -22,15 +35,84 @@ const (
 	errInternalServerError string = "Internal Server Error"
 	errNotFound            string = "Not Found"
 	errConflict            string = "Conflict"
+	errUnauthorized        string = "Unauthorized"
+	errForbidden           string = "Forbidden"
+	errNotImplemented      string = "Not Implemented"
+	errServiceUnavailable  string = "Service Unavailable"
+	errUnsupportedMedia    string = "Unsupported Media Type"
+	errUnprocessable       string = "Unprocessable Entity"
+	errNotAcceptable       string = "Not Acceptable"
+	errBodyTooLarge        string = "Request Entity Too Large"
 )
 
 // Сообщения для конкретных ошибок в ответах
 const (
-	messageNoID               string = "ID must be present as query parameter"
-	messageMalformedID        string = "ID parameter is malformed"
-	messageQuoteNotFoundByID  string = "Quote with the provide ID doesn't exists"
-	messageQuoteAlreadyExists string = "This quote already exists"
-	messageQuotesNotFound     string = "No quotes found"
+	messageNoID                  string = "ID must be present as query parameter"
+	messageMalformedID           string = "ID parameter is malformed"
+	messageMalformedUUID         string = "ID parameter must be a UUID"
+	messageUnsupportedVersion    string = "Response version must be 1 or 2 (v parameter or Accept: application/vnd.getcitation.v2+json)"
+	messageQuoteNotFoundByID     string = "Quote with the provide ID doesn't exists"
+	messageQuoteAlreadyExists    string = "This quote already exists"
+	messageQuotesNotFound        string = "No quotes found"
+	messageQuoteNotDeleted       string = "Quote with the provided ID is not deleted"
+	messageMalformedKey          string = "Idempotency-Key header is too long"
+	messageMalformedDeleted      string = "include_deleted parameter must be a boolean"
+	messageMalformedSort         string = "sort parameter must be empty, popular or most_viewed"
+	messageTooManyAuthors        string = "Too many author parameters"
+	messageMalformedFormat       string = "format parameter must be ndjson"
+	messageInvalidToken          string = "Bearer token is missing or invalid"
+	messageAdminRequired         string = "This operation requires the admin role"
+	messageMalformedFair         string = "fair parameter must be a boolean"
+	messageMalformedLimit        string = "limit parameter must be an integer between 1 and 50"
+	messageSimilarUnsupported    string = "Similar quotes search requires the PostgreSQL backend"
+	messageNoSearchQuery         string = "q must be present as query parameter"
+	messageSearchUnsupported     string = "Full-text search requires the PostgreSQL backend"
+	messageStorageUnavailable    string = "Storage is temporarily unavailable"
+	messageSchemaOutdated        string = "Database schema is at migration %d, but the service requires %d: apply migrations"
+	messageSchemaDirty           string = "Database schema is dirty after a failed migration %d: fix it and apply migrations"
+	messageValidationFailed      string = "Request fields failed validation"
+	messageMalformedPageLimit    string = "limit parameter must be an integer between 1 and %d"
+	messageMalformedOffset       string = "offset parameter must be a non-negative integer"
+	messageMalformedAuthorsLimit string = "limit parameter must be a positive integer"
+	messageMalformedAfter        string = "after parameter must be a non-negative integer"
+	messageCursorConflict        string = "after parameter cannot be combined with offset or sort"
+	messageConfirmRequired       string = "confirm=true query parameter is required to purge all quotes"
+	messageMalformedInclude      string = "include parameter must be meta"
+	messageJSONRequired          string = "Content-Type must be application/json"
+	messageRequestTimeout        string = "Request took too long to process"
+	messageMalformedFields       string = "fields parameter must list id, uuid, slug, author, quote, likes, views, language, source or deleted_at"
+	messageAuthorNotFound        string = "No quotes by the from author"
+	messageAuthorConflict        string = "The to author already has one of the renamed quotes"
+	messagePositiveLimit         string = "limit parameter must be a positive integer"
+	messageMalformedIDs          string = "ids parameter must be a comma-separated list of 1 to %d positive integers"
+	messageExistsParams          string = "author and quote must be present as query parameters"
+	messageBannedWords           string = "Quote or author contains a banned word"
+	messageKeyReused             string = "Idempotency-Key was already used with a different request body"
+	messageMalformedUpsert       string = "upsert parameter must be a boolean"
+	messageUpsertConflict        string = "upsert parameter cannot be combined with Idempotency-Key"
+)
+
+// Параметры запросов
+const (
+	headerIdempotencyKey    string        = "Idempotency-Key"
+	maxIdempotencyKeyLength int           = 255
+	maxAuthorFilters        int           = 20
+	exportFormatNDJSON      string        = "ndjson"
+	exportFlushEvery        int           = 100
+	defaultSimilarLimit     int           = 5
+	maxSimilarLimit         int           = 50
+	maxSearchResults        int           = 100
+	maxBatchIDs             int           = 100
+	defaultRecentLimit      int           = 10
+	maxRecentLimit          int           = 100
+	readyTimeout            time.Duration = 2 * time.Second
+	streamDrainTimeout      time.Duration = 5 * time.Second
+)
+
+// Заголовки кэширования
+const (
+	cacheControlRevalidate string = "no-cache"
+	cacheControlNoStore    string = "no-store"
 )
 
 // Сообщения успешных операций
@@ -42,81 +124,293 @@ const (
 var (
 	ErrDuplicateEntry = fmt.Errorf("similar entry already exists")
 	ErrNoQuotesFound  = fmt.Errorf("no quotes found")
+	ErrNotDeleted     = fmt.Errorf("quote is not deleted")
+	ErrNoCategory     = fmt.Errorf("category does not exist")
+	ErrKeyReused      = fmt.Errorf("idempotency key was used with a different request")
+	ErrBannedWord     = fmt.Errorf("banned word")
+	ErrImportRejected = fmt.Errorf("import rejected, no quotes were added")
+	ErrNotSupported   = fmt.Errorf("operation is not supported by the storage backend")
+	ErrIncompleteTLS  = fmt.Errorf("both TLS_CERT_FILE and TLS_KEY_FILE must be set to enable TLS")
+	ErrTLSVersion     = fmt.Errorf("unsupported TLS_MIN_VERSION, expected 1.2 or 1.3")
+	ErrAddressInUse   = fmt.Errorf("server address is already in use, stop the other process or change the configured address")
 )
 
 // App представляет основное приложение с HTTP-сервером, логгером и конфигом
 type App struct {
-	Server Server
-	Log    *slog.Logger
-	Config config.Config
+	Server  Server
+	Webhook webhook.Publisher
+	Stream  broadcaster.Broadcaster
+	Log     *slog.Logger
+	Config  config.Config
 }
 
-// Server содержит HTTP сервер и обработчики маршрутов
+// Server содержит HTTP сервер, его слушающий сокет и обработчики маршрутов
 type Server struct {
 	HTTPServer *http.Server
+	Listener   net.Listener
 	Handlers   Handlers
 }
 
-// New создает и инициализирует новое приложение getcitation
-func New(db storage.Storage, config config.Config, log *slog.Logger) App {
+// New создает и инициализирует новое приложение getcitation поверх выбранного хранилища.
+// Сокет открывается сразу, чтобы ошибка привязки к адресу обнаруживалась до запуска приложения.
+func New(store QuoteStore, config config.Config, build BuildInfo, log *slog.Logger) (App, error) {
 	const op = "getcitation.New()"
 
+	publisher := webhook.New(config, log)
+	stream := broadcaster.New(config.StreamBuffer)
+
+	words, err := LoadBannedWords(config.BannedWordsPath)
+	if err != nil {
+		return App{}, fmt.Errorf("%s: %w", op, err)
+	}
+	bannedWords := &atomic.Pointer[BannedWords]{}
+	bannedWords.Store(&words)
+
 	service := Service{
 		Log:    log,
 		Config: config,
 
-		Manipulator: db.DB.Handlers,
-		Getter:      db.DB.Handlers,
+		BannedWords: bannedWords,
+
+		Manipulator: store,
+		Getter:      store,
+		Events:      publisher,
+		Stream:      stream,
 	}
 
 	handlers := Handlers{
-		Log:    log,
+		Log:    sampleClientErrors(log, config.LogSampleRate),
 		Config: config,
 
 		Manipulator: service,
 		Getter:      service,
+		Stream:      stream,
+		Readiness:   store,
+		Pool:        store,
+		OpenAPI:     newOpenAPI(config.JWTSecret != "", config.RoutePrefix, config.QuoteIDType),
+		Build:       build,
+
+		Started:      &atomic.Bool{},
+		ReadOnlyMode: &atomic.Bool{},
+		BannedWords:  bannedWords,
+	}
+	handlers.ReadOnlyMode.Store(config.ReadOnlyMode)
+
+	if config.CacheTTL > 0 {
+		cache := NewQuoteCache(service, service, config.CacheTTL)
+
+		handlers.Manipulator = cache
+		handlers.Getter = cache
 	}
 
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/quotes", handlers.GetAndCreateQuotes)
-	mux.HandleFunc("/quotes/", handlers.DeleteQuoteByID)
-	mux.HandleFunc("/quotes/random", handlers.GetRandomQuote)
+	// Все маршруты API живут под ROUTE_PREFIX (например, /api/v1 за шлюзом).
+	prefix := config.RoutePrefix
+
+	mux.HandleFunc(prefix+"/quotes", handlers.GetAndCreateQuotes)
+	mux.HandleFunc(prefix+"/quotes/", handlers.DeleteQuoteByID)
+	mux.HandleFunc(prefix+"/quotes/{id}", handlers.GetAndDeleteQuoteByID)
+	mux.HandleFunc(prefix+"/quotes/all", handlers.PurgeQuotes)
+	mux.HandleFunc(prefix+"/quotes/random", handlers.GetRandomQuote)
+	mux.HandleFunc(prefix+"/quotes/random.txt", handlers.GetRandomQuoteText)
+	mux.HandleFunc(prefix+"/quotes/random.svg", handlers.GetRandomQuoteSVG)
+	mux.HandleFunc(prefix+"/quotes/stream", handlers.StreamQuotes)
+	mux.HandleFunc(prefix+"/quotes/count", handlers.CountQuotes)
+	mux.HandleFunc(prefix+"/quotes/search", handlers.SearchQuotes)
+	mux.HandleFunc(prefix+"/quotes/batch", handlers.GetQuotesBatch)
+	mux.HandleFunc(prefix+"/quotes/exists", handlers.QuoteExists)
+	mux.HandleFunc(prefix+"/quotes/import", handlers.ImportQuotes)
+	mux.HandleFunc(prefix+"/quotes/recent", handlers.GetRecentQuotes)
+	mux.HandleFunc(prefix+"/quotes/feed.rss", handlers.GetQuotesFeed)
+	mux.HandleFunc(prefix+"/quotes/export", handlers.ExportQuotes)
+	mux.HandleFunc(prefix+"/quotes/{id}/{action}", handlers.QuoteAction)
+	mux.HandleFunc(prefix+"/quotes/slug/{slug}", handlers.GetQuoteBySlug)
+	mux.HandleFunc(prefix+"/stats", handlers.GetStats)
+	mux.HandleFunc(prefix+"/stats/text", handlers.GetTextStats)
+	mux.HandleFunc(prefix+"/authors", handlers.Authors)
+	mux.HandleFunc(prefix+"/categories", handlers.Categories)
+	mux.HandleFunc(prefix+"/audit", handlers.GetAuditLog)
+	mux.HandleFunc(prefix+"/openapi.json", handlers.GetOpenAPI)
+	mux.HandleFunc(prefix+"/admin/readonly", handlers.AdminReadOnly)
+	mux.HandleFunc(prefix+"/admin/reload-filters", handlers.AdminReloadFilters)
+
+	// Проба готовности опрашивается оркестратором напрямую и без токена, поэтому стоит перед
+	// аутентификацией и не зависит от ROUTE_PREFIX. Так же устроен /version для проверки выкладки.
+	root := http.NewServeMux()
+
+	root.Handle("/ready", handlers.ResponseFormat(http.HandlerFunc(handlers.Ready)))
+	root.Handle("/version", handlers.ResponseFormat(http.HandlerFunc(handlers.GetVersion)))
+	root.Handle("/", handlers.LimitConcurrency(handlers.CORS(handlers.Authenticate(handlers.ReadOnly(handlers.Timeout(handlers.ResponseFormat(mux), prefix+"/quotes/stream", prefix+"/quotes/export"), prefix+"/admin/readonly", prefix+"/admin/reload-filters"))), prefix+"/quotes/stream"))
+
+	addr := net.JoinHostPort(config.ServerHost, config.ServerPort)
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	listener, err := listen(config)
+	if err != nil {
+		return App{}, fmt.Errorf("%s: %w", op, err)
+	}
 
 	server := &http.Server{
-		Addr:         fmt.Sprintf("%s:%s", config.ServerHost, config.ServerPort),
-		Handler:      mux,
-		WriteTimeout: config.ServerWriteTimeout,
-		ReadTimeout:  config.ServerReadTimeout,
-		IdleTimeout:  config.ServerIdleTimeout,
+		Addr:              addr,
+		Handler:           handlers.LogRequests(handlers.StartupGate(handlers.TrimSlash(root))),
+		WriteTimeout:      config.ServerWriteTimeout,
+		ReadTimeout:       config.ServerReadTimeout,
+		ReadHeaderTimeout: config.ServerReadHeaderTimeout,
+		IdleTimeout:       config.ServerIdleTimeout,
+		MaxHeaderBytes:    config.ServerMaxHeaderBytes,
+		TLSConfig:         tlsConfig,
 	}
+	server.SetKeepAlivesEnabled(!config.ServerKeepAliveDisabled)
 
 	return App{
 		Server: Server{
 			HTTPServer: server,
+			Listener:   listener,
 			Handlers:   handlers,
 		},
-		Log:    log,
-		Config: config,
+		Webhook: publisher,
+		Stream:  stream,
+		Log:     log,
+		Config:  config,
+	}, nil
+}
+
+// newTLSConfig загружает сертификат и ключ, если TLS включён, чтобы ошибки конфигурации
+// обнаруживались при запуске, а не на первом соединении. Возвращает nil, если TLS выключен.
+func newTLSConfig(config config.Config) (*tls.Config, error) {
+	const op = "getcitation.newTLSConfig()"
+
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		return nil, nil
+	}
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrIncompleteTLS)
+	}
+
+	var minVersion uint16
+
+	switch config.TLSMinVersion {
+	case "1.2":
+		minVersion = tls.VersionTLS12
+	case "1.3":
+		minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("%s: %w: %s", op, ErrTLSVersion, config.TLSMinVersion)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   minVersion,
+	}, nil
+}
+
+// listen открывает слушающий сокет сервера: Unix-сокет, если задан SERVER_SOCKET, иначе TCP на SERVER_HOST:SERVER_PORT
+func listen(config config.Config) (net.Listener, error) {
+	const op = "getcitation.listen()"
+
+	network, addr := "tcp", net.JoinHostPort(config.ServerHost, config.ServerPort)
+
+	if config.ServerSocket != "" {
+		network, addr = "unix", config.ServerSocket
+
+		// Файл сокета мог остаться от аварийно завершённого процесса. Удаляем его, только если
+		// к нему никто не подключён, иначе сообщаем, что адрес занят.
+		info, err := os.Stat(addr)
+		if err == nil && info.Mode()&os.ModeSocket != 0 {
+			conn, err := net.Dial(network, addr)
+			if err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("%s: %w: %s", op, ErrAddressInUse, addr)
+			}
+
+			err = os.Remove(addr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return nil, fmt.Errorf("%s: %w: %s", op, ErrAddressInUse, addr)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	return listener, nil
 }
 
-// Run запускает HTTP сервер приложения и блокирует выполнение до его остановки
+// Run запускает HTTP (или HTTPS, если настроен TLS) сервер приложения и блокирует выполнение до его остановки.
+// До первой успешной проверки хранилища сервер отвечает на все запросы 503 (см. StartupGate)
 func (a App) Run() error {
 	const op = "getcitation.Run()"
 
-	err := a.Server.HTTPServer.ListenAndServe()
+	// Проверка хранилища останавливается вместе с сервером, если оно так и не ответило
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go a.Server.Handlers.WaitForStorage(ctx)
+
+	var err error
+
+	if a.Server.HTTPServer.TLSConfig != nil {
+		err = a.Server.HTTPServer.ServeTLS(a.Server.Listener, "", "")
+	} else {
+		err = a.Server.HTTPServer.Serve(a.Server.Listener)
+	}
 	if err != nil && err != http.ErrServerClosed {
 		return err
 	}
 	return nil
 }
 
-// Shutdown корректно завершает работу HTTP сервера
+// Shutdown корректно завершает работу HTTP сервера и дожидается доставки уже отправленных вебхуков.
+// Подписчики потока (SSE) сами не отключаются, и HTTPServer.Shutdown ждал бы их вечно, поэтому сначала
+// поток закрывается: клиенты получают событие shutdown, а их соединения завершаются. Если за
+// streamDrainTimeout они не отключились, сервер закрывает соединения принудительно.
 func (a App) Shutdown() error {
 	const op = "getcitation.Shutdown()"
 
-	err := a.Server.HTTPServer.Shutdown(context.TODO())
+	streamCtx, streamCancel := context.WithTimeout(context.Background(), streamDrainTimeout)
+	defer streamCancel()
+
+	err := a.Stream.Shutdown(streamCtx)
+	if err != nil {
+		a.Log.Warn(
+			"подписчики потока не отключились вовремя, соединения закрываются принудительно",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+
+		err = a.Server.HTTPServer.Close()
+	} else {
+		err = a.Server.HTTPServer.Shutdown(context.TODO())
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.Config.ServerSocket != "" {
+		err = os.Remove(a.Config.ServerSocket)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.Config.WebhookTimeout)
+	defer cancel()
+
+	err = a.Webhook.Shutdown(ctx)
 	if err != nil {
 		return err
 	}
@@ -125,14 +419,46 @@ func (a App) Shutdown() error {
 
 // Интерфейс для манипуляций с цитатами (создание, удаление)
 type ServiceManipulator interface {
-	CreateQuote(author string, quote string) (int, error)
-	DeleteQuoteByID(id int) error
+	CreateQuote(ctx context.Context, author string, quote string, lang string, source string) (int, error)
+	CreateQuoteIdempotent(ctx context.Context, key string, author string, quote string, lang string, source string) (int, error)
+	UpsertQuote(ctx context.Context, author string, quote string, lang string, source string) (int, bool, error)
+	ImportQuotes(ctx context.Context, rows []ImportRow, options ImportOptions) ([]ImportResult, error)
+	DeleteQuoteByID(ctx context.Context, id int) error
+	PurgeQuotes(ctx context.Context) (int, error)
+	RenameAuthor(ctx context.Context, from string, to string) (int, error)
+	RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error)
+	LikeQuoteByID(ctx context.Context, id int) (int, error)
+	CreateCategory(ctx context.Context, name string) (int, error)
+	AssignCategory(ctx context.Context, quoteID int, categoryID int) error
 }
 
 // Интерфейс для получения цитат (рандомная, по автору)
 type ServiceGetter interface {
-	GetRandomQuote() (storage.Quote, error)
-	GetQuotes(authorFilter string) ([]storage.Quote, error)
+	GetRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error)
+	GetFairRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error)
+	QuoteLanguages(ctx context.Context) ([]string, error)
+	QuoteExists(ctx context.Context, author string, quote string) (bool, error)
+	GetQuoteByID(ctx context.Context, id int) (storage.Quote, error)
+	GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error)
+	GetQuoteIDBySlug(ctx context.Context, slug string) (int, error)
+	GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error)
+	GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error)
+	GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error)
+	SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error)
+	GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error)
+	CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error)
+	ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error
+	CountQuotes(ctx context.Context, authorFilter string) (int, error)
+	GetStats(ctx context.Context) (storage.Stats, error)
+	GetTextStats(ctx context.Context) (storage.TextStats, error)
+	GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error)
+	GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error)
+	GetCategories(ctx context.Context) ([]storage.Category, error)
+}
+
+// Интерфейс для подписки на поток новых цитат
+type StreamSubscriber interface {
+	Subscribe() (<-chan storage.Quote, func())
 }
 
 // Handlers содержит методы HTTP-обработчиков, использующих сервис
@@ -142,12 +468,32 @@ type Handlers struct {
 
 	Manipulator ServiceManipulator
 	Getter      ServiceGetter
+	Stream      StreamSubscriber
+	Readiness   ReadinessChecker
+	Pool        PoolReporter
+	OpenAPI     OpenAPI
+	Build       BuildInfo
+
+	// Started открывает StartupGate после первой успешной проверки хранилища
+	Started *atomic.Bool
+	// ReadOnlyMode включает режим только для чтения (см. ReadOnly), переключается через /admin/readonly
+	ReadOnlyMode *atomic.Bool
+	// BannedWords — общий с Service список запрещенных слов, перечитывается через /admin/reload-filters
+	BannedWords *atomic.Pointer[BannedWords]
 }
 
-// Error описывает структуру ошибки в формате JSON для ответов API
+// Error описывает структуру ошибки в формате JSON для ответов API. Debug заполняется только
+// при EXPOSE_INTERNAL_ERRORS в режимах local и dev.
 type Error struct {
-	Status  Status `json:"status"`
-	Message string `json:"message"`
+	Status  Status      `json:"status"`
+	Message string      `json:"message"`
+	Debug   *ErrorDebug `json:"debug,omitempty"`
+}
+
+// ErrorDebug описывает детали внутренней ошибки для отладки: op обработчика и текст ошибки с цепочкой op
+type ErrorDebug struct {
+	Op    string `json:"op"`
+	Error string `json:"error"`
 }
 
 // Status описывает статус HTTP ответа
@@ -156,35 +502,113 @@ type Status struct {
 	Message string `json:"message"`
 }
 
+// ValidationErrorResponse описывает ответ на запрос, поля которого не прошли проверку: все ошибки сразу
+type ValidationErrorResponse struct {
+	Status  Status       `json:"status"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
 // CreateQuoteRequest описывает формат запроса на создание цитаты
 type CreateQuoteRequest struct {
 	ID     int    `json:"id"`
 	Author string `json:"author"`
 	Quote  string `json:"quote"`
+	// Language — тег языка BCP 47 (например, "en" или "pt-BR"), необязательный
+	Language string `json:"language,omitempty"`
+	// Source — произведение или URL, откуда взята цитата, необязательный
+	Source string `json:"source,omitempty"`
 }
 
 // CreateQuoteResponse описывает формат успешного ответа при создании цитаты
 type CreateQuoteResponse struct {
-	Status Status `json:"status"`
-	ID     int    `json:"id"`
+	Status   Status `json:"status"`
+	ID       int    `json:"id"`
+	UUID     string `json:"uuid,omitempty"`
+	Existing bool   `json:"existing,omitempty"`
 }
 
 // GetQuotesResponse описывает формат ответа при запросе списка цитат
 type GetQuotesResponse struct {
 	Status Status          `json:"status"`
 	Quotes []storage.Quote `json:"quotes"`
+	Total  *int            `json:"total,omitempty"`
+	Links  *PageLinks      `json:"links,omitempty"`
+	// NextCursor — значение after для следующей страницы при выборке по курсору, нет на последней странице
+	NextCursor int `json:"next_cursor,omitempty"`
+}
+
+// GetQuoteFieldsResponse описывает формат ответа со списком цитат, урезанных до полей из параметра fields
+type GetQuoteFieldsResponse struct {
+	Status     Status           `json:"status"`
+	Quotes     []map[string]any `json:"quotes"`
+	Total      int              `json:"total"`
+	Links      PageLinks        `json:"links"`
+	NextCursor int              `json:"next_cursor,omitempty"`
 }
 
-// GetAndCreateQuotes обрабатывает HTTP запросы на получение списка цитат и создание новых
+// GetAndCreateQuotes обрабатывает HTTP запросы на получение списка цитат (GET, HEAD) и создание новых
 func (h Handlers) GetAndCreateQuotes(w http.ResponseWriter, r *http.Request) {
 	const op = "getcitation.Transport.GetAndCreateQuotes()"
 
 	switch r.Method {
 	case http.MethodPost:
-		var req CreateQuoteRequest
+		if !isJSON(r) {
+			h.Log.Error(
+				errUnsupportedMedia,
+				slog.String("op", op),
+				slog.String("content_type", r.Header.Get("Content-Type")),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+				Status: Status{
+					Code:    http.StatusUnsupportedMediaType,
+					Message: errUnsupportedMedia,
+				},
+				Message: messageJSONRequired,
+			})
+
+			return
+		}
 
-		err := json.NewDecoder(r.Body).Decode(&req)
+		limitBody(w, r, h.Config.MaxBodyBytes)
+
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = validateSchema(createQuoteSchema, body)
+		}
 		if err != nil {
+			if h.rejectLargeBody(w, r, op, err) {
+				return
+			}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, ValidationErrorResponse{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageValidationFailed,
+					Errors:  validationErr.Fields,
+				})
+
+				return
+			}
+			// Текст ошибки разбора JSON с позицией помогает клиенту найти, что не так в теле
+			var message string
+			var jsonErr *JSONError
+			if errors.As(err, &jsonErr) {
+				message = jsonErr.Error()
+			}
+
 			h.Log.Error(
 				errBadRequest,
 				slog.String("op", op),
@@ -192,21 +616,21 @@ func (h Handlers) GetAndCreateQuotes(w http.ResponseWriter, r *http.Request) {
 				slog.String("path", r.URL.Path),
 			)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-
-			json.NewEncoder(w).Encode(Error{
+			h.writeJSON(w, http.StatusBadRequest, Error{
 				Status: Status{
 					Code:    http.StatusBadRequest,
 					Message: errBadRequest,
 				},
+				Message: message,
 			})
 
 			return
 		}
-		defer r.Body.Close()
 
-		if req.Author == "" || req.Quote == "" {
+		var req CreateQuoteRequest
+
+		err = json.Unmarshal(body, &req)
+		if err != nil {
 			h.Log.Error(
 				errBadRequest,
 				slog.String("op", op),
@@ -214,21 +638,133 @@ func (h Handlers) GetAndCreateQuotes(w http.ResponseWriter, r *http.Request) {
 				slog.String("path", r.URL.Path),
 			)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: newJSONError(err, body).Error(),
+			})
+
+			return
+		}
+		defer r.Body.Close()
+
+		key := r.Header.Get(headerIdempotencyKey)
+		if len(key) > maxIdempotencyKeyLength {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("path", r.URL.Path),
+			)
 
-			json.NewEncoder(w).Encode(Error{
+			h.writeJSON(w, http.StatusBadRequest, Error{
 				Status: Status{
 					Code:    http.StatusBadRequest,
 					Message: errBadRequest,
 				},
+				Message: messageMalformedKey,
 			})
 
 			return
 		}
 
-		id, err := h.Manipulator.CreateQuote(req.Author, req.Quote)
+		// upsert=true для повторяемого наполнения: на дубликат отвечает ID существующей цитаты вместо 409
+		var upsert bool
+		if raw := r.URL.Query().Get("upsert"); raw != "" {
+			upsert, err = strconv.ParseBool(raw)
+			if err != nil || upsert && key != "" {
+				message := messageMalformedUpsert
+				if err == nil {
+					message = messageUpsertConflict
+				}
+
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: message,
+				})
+
+				return
+			}
+		}
+
+		var id int
+		var existing bool
+		switch {
+		case upsert:
+			id, existing, err = h.Manipulator.UpsertQuote(r.Context(), req.Author, req.Quote, req.Language, req.Source)
+		case key != "":
+			id, err = h.Manipulator.CreateQuoteIdempotent(r.Context(), key, req.Author, req.Quote, req.Language, req.Source)
+		default:
+			id, err = h.Manipulator.CreateQuote(r.Context(), req.Author, req.Quote, req.Language, req.Source)
+		}
 		if err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, ValidationErrorResponse{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageValidationFailed,
+					Errors:  validationErr.Fields,
+				})
+
+				return
+			}
+			if errors.Is(err, ErrBannedWord) {
+				h.Log.Error(
+					errUnprocessable,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusUnprocessableEntity, Error{
+					Status: Status{
+						Code:    http.StatusUnprocessableEntity,
+						Message: errUnprocessable,
+					},
+					Message: messageBannedWords,
+				})
+
+				return
+			}
+			if errors.Is(err, ErrKeyReused) {
+				h.Log.Error(
+					errUnprocessable,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusUnprocessableEntity, Error{
+					Status: Status{
+						Code:    http.StatusUnprocessableEntity,
+						Message: errUnprocessable,
+					},
+					Message: messageKeyReused,
+				})
+
+				return
+			}
 			if errors.Is(err, ErrDuplicateEntry) {
 				h.Log.Error(
 					errConflict,
@@ -237,10 +773,7 @@ func (h Handlers) GetAndCreateQuotes(w http.ResponseWriter, r *http.Request) {
 					slog.String("path", r.URL.Path),
 				)
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusConflict)
-
-				json.NewEncoder(w).Encode(Error{
+				h.writeJSON(w, http.StatusConflict, Error{
 					Status: Status{
 						Code:    http.StatusConflict,
 						Message: errConflict,
@@ -250,334 +783,3373 @@ func (h Handlers) GetAndCreateQuotes(w http.ResponseWriter, r *http.Request) {
 
 				return
 			}
+			code, message := internalStatus(err)
+
 			h.Log.Error(
-				errInternalServerError,
+				message,
 				slog.String("op", op),
 				slog.Any("error", err),
 				slog.String("path", r.URL.Path),
 			)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-
-			json.NewEncoder(w).Encode(Error{
+			h.writeJSON(w, code, Error{
 				Status: Status{
-					Code:    http.StatusInternalServerError,
-					Message: errInternalServerError,
+					Code:    code,
+					Message: message,
 				},
+				Debug: h.errorDebug(op, err),
 			})
 
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		// В режиме upsert новая цитата отвечает 201, чтобы клиент отличал ее от уже существующей (200).
+		// Обычное добавление, как и раньше, отвечает 200.
+		code := http.StatusOK
+		if upsert && !existing {
+			code = http.StatusCreated
+		}
 
-		json.NewEncoder(w).Encode(CreateQuoteResponse{
+		response := CreateQuoteResponse{
 			Status: Status{
-				Code: http.StatusOK,
+				Code: code,
 			},
-			ID: id,
-		})
-
-	case http.MethodGet:
-		author := r.URL.Query().Get("author")
+			ID:       id,
+			Existing: existing,
+		}
 
-		quotes, err := h.Getter.GetQuotes(author)
-		if err != nil {
-			if errors.Is(err, ErrNoQuotesFound) {
+		// При QUOTE_ID_TYPE=uuid цитата адресуется по UUID, поэтому клиенту нужен именно он. Цитата уже
+		// добавлена, так что ошибка чтения UUID не превращает ответ в ошибку.
+		if h.Config.QuoteIDType == config.QuoteIDUUID {
+			quotes, err := h.Getter.GetQuotesByIDs(r.Context(), []int{id})
+			if err == nil && len(quotes) == 1 {
+				response.UUID = quotes[0].UUID
+			} else {
 				h.Log.Error(
-					errNotFound,
+					"не удалось получить UUID добавленной цитаты",
 					slog.String("op", op),
+					slog.Int("id", id),
 					slog.Any("error", err),
-					slog.String("path", r.URL.Path),
 				)
+			}
+		}
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusNotFound)
+		h.writeJSON(w, code, response)
 
-				json.NewEncoder(w).Encode(Error{
-					Status: Status{
-						Code:    http.StatusNotFound,
-						Message: errNotFound,
-					},
-					Message: messageQuotesNotFound,
-				})
+	case http.MethodGet, http.MethodHead:
+		filter := storage.QuoteFilter{
+			Sort: r.URL.Query().Get("sort"),
+		}
 
-				return
+		for _, author := range r.URL.Query()["author"] {
+			if author != "" {
+				filter.Authors = append(filter.Authors, author)
 			}
+		}
+
+		if h.rejectLongFilter(w, r, op, "author", filter.Authors...) {
+			return
+		}
+
+		if len(filter.Authors) > maxAuthorFilters {
 			h.Log.Error(
-				errInternalServerError,
+				errBadRequest,
 				slog.String("op", op),
-				slog.Any("error", err),
+				slog.Int("authors", len(filter.Authors)),
 				slog.String("path", r.URL.Path),
 			)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-
-			json.NewEncoder(w).Encode(Error{
+			h.writeJSON(w, http.StatusBadRequest, Error{
 				Status: Status{
-					Code:    http.StatusInternalServerError,
-					Message: errInternalServerError,
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
 				},
+				Message: messageTooManyAuthors,
 			})
 
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		switch filter.Sort {
+		case "", storage.SortPopular, storage.SortMostViewed:
+		default:
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("path", r.URL.Path),
+			)
 
-		json.NewEncoder(w).Encode(GetQuotesResponse{
-			Status: Status{
-				Code: http.StatusOK,
-			},
-			Quotes: quotes,
-		})
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedSort,
+			})
 
-	default:
-		h.Log.Error(
-			errMethodNotAllowed,
-			slog.String("op", op),
-			slog.String("path", r.URL.Path),
-		)
+			return
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		filter.Limit = h.Config.PageSizeDefault
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusMethodNotAllowed,
-				Message: errMethodNotAllowed,
-			},
-		})
-	}
-}
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > h.Config.PageSizeMax {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.String("limit", raw),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: fmt.Sprintf(messageMalformedPageLimit, h.Config.PageSizeMax),
+				})
+
+				return
+			}
+			filter.Limit = limit
+		}
+
+		if raw := r.URL.Query().Get("offset"); raw != "" {
+			offset, err := strconv.Atoi(raw)
+			if err != nil || offset < 0 {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.String("offset", raw),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageMalformedOffset,
+				})
+
+				return
+			}
+			filter.Offset = offset
+		}
+
+		// Курсор after — альтернатива offset: страница начинается сразу после цитаты с этим ID
+		cursor := r.URL.Query().Has("after")
+
+		if cursor {
+			raw := r.URL.Query().Get("after")
+
+			after, err := strconv.Atoi(raw)
+			if err != nil || after < 0 {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.String("after", raw),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageMalformedAfter,
+				})
+
+				return
+			}
+
+			if r.URL.Query().Has("offset") || filter.Sort != "" {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageCursorConflict,
+				})
+
+				return
+			}
+			filter.After = after
+		}
+
+		if raw := r.URL.Query().Get("category"); raw != "" {
+			category, err := strconv.Atoi(raw)
+			if err != nil || category < 1 {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.String("category", raw),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageMalformedCategory,
+				})
+
+				return
+			}
+			filter.Category = category
+		}
+
+		if raw := r.URL.Query().Get("include_deleted"); raw != "" {
+			includeDeleted, err := strconv.ParseBool(raw)
+			if err != nil {
+				h.Log.Error(
+					errBadRequest,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusBadRequest, Error{
+					Status: Status{
+						Code:    http.StatusBadRequest,
+						Message: errBadRequest,
+					},
+					Message: messageMalformedDeleted,
+				})
+
+				return
+			}
+			filter.IncludeDeleted = includeDeleted
+		}
+
+		if filter.IncludeDeleted && !h.isAdmin(r) {
+			h.Log.Error(
+				errForbidden,
+				slog.String("op", op),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusForbidden, Error{
+				Status: Status{
+					Code:    http.StatusForbidden,
+					Message: errForbidden,
+				},
+				Message: messageAdminRequired,
+			})
+
+			return
+		}
+
+		fields, err := parseFields(r.URL.Query())
+		if err != nil {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedFields,
+			})
+
+			return
+		}
+
+		// По курсору запрашивается на одну цитату больше страницы: так видно, есть ли следующая,
+		// без лишнего запроса за пустой последней страницей
+		page := filter
+		if cursor {
+			page.Limit++
+		}
+
+		quotes, err := h.Getter.GetQuotes(r.Context(), page)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				h.Log.Warn(
+					"запрос отменён клиентом",
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				return
+			}
+			if errors.Is(err, ErrNoQuotesFound) {
+				h.Log.Error(
+					errNotFound,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+
+				h.writeJSON(w, http.StatusNotFound, Error{
+					Status: Status{
+						Code:    http.StatusNotFound,
+						Message: errNotFound,
+					},
+					Message: messageQuotesNotFound,
+				})
+
+				return
+			}
+			code, message := internalStatus(err)
+
+			h.Log.Error(
+				message,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, code, Error{
+				Status: Status{
+					Code:    code,
+					Message: message,
+				},
+				Debug: h.errorDebug(op, err),
+			})
+
+			return
+		}
+
+		total, err := h.Getter.CountFilteredQuotes(r.Context(), filter)
+		if err != nil {
+			code, message := internalStatus(err)
+
+			h.Log.Error(
+				message,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, code, Error{
+				Status: Status{
+					Code:    code,
+					Message: message,
+				},
+				Debug: h.errorDebug(op, err),
+			})
+
+			return
+		}
+
+		var nextCursor int
+		if cursor && len(quotes) > filter.Limit {
+			quotes = quotes[:filter.Limit]
+			nextCursor = quotes[len(quotes)-1].ID
+		}
+
+		etag := quotesETag(quotes, total)
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		links := pageLinks(r.URL, filter.Limit, filter.Offset, total)
+		if cursor {
+			links = cursorLinks(r.URL, filter.Limit, filter.After, nextCursor)
+		}
+
+		if fields != nil {
+			h.writeJSON(w, http.StatusOK, GetQuoteFieldsResponse{
+				Status: Status{
+					Code: http.StatusOK,
+				},
+				Quotes:     selectFields(quotes, fields),
+				Total:      total,
+				Links:      links,
+				NextCursor: nextCursor,
+			})
+
+			return
+		}
+
+		h.writeJSON(w, http.StatusOK, GetQuotesResponse{
+			Status: Status{
+				Code: http.StatusOK,
+			},
+			Quotes:     quotes,
+			Total:      &total,
+			Links:      &links,
+			NextCursor: nextCursor,
+		})
+
+	default:
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodHead, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+	}
+}
+
+// internalStatus выбирает ответ на ошибку, которую клиент не может исправить сам: 503, если хранилище
+// временно недоступно и запрос стоит повторить позже, и 500 в остальных случаях.
+func internalStatus(err error) (int, string) {
+	if errors.Is(err, storage.ErrUnavailable) {
+		return http.StatusServiceUnavailable, errServiceUnavailable
+	}
+	return http.StatusInternalServerError, errInternalServerError
+}
+
+// isJSON сообщает, что тело запроса объявлено как JSON. Параметры типа (например, charset) допускаются.
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// quotesETag вычисляет слабый ETag для страницы списка цитат по их ID и содержимому. Общее число цитат
+// тоже учитывается: от него зависят ссылки на соседние страницы.
+func quotesETag(quotes []storage.Quote, total int) string {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "%d\x00", total)
+
+	for _, quote := range quotes {
+		fmt.Fprintf(hash, "%d\x00%s\x00%s\x00%d\x00%d\x00%t\x00", quote.ID, quote.Author, quote.Quote, quote.Likes, quote.Views, quote.DeletedAt != nil)
+	}
+
+	return fmt.Sprintf(`W/"%x"`, hash.Sum(nil)[:16])
+}
+
+// etagMatches проверяет, совпадает ли один из ETag заголовка If-None-Match с текущим (слабое сравнение)
+func etagMatches(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadyResponse описывает формат ответа пробы готовности
+type ReadyResponse struct {
+	Status Status `json:"status"`
+}
+
+// Ready обрабатывает HTTP GET запрос пробы готовности: 200, если хранилище отвечает, и 503, если
+// соединение с БД потеряно и еще не восстановлено.
+func (h Handlers) Ready(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.Ready()"
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodHead)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+
+	err := h.Readiness.Ready(ctx)
+	if err != nil {
+		h.Log.Error(
+			errServiceUnavailable,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		message := messageStorageUnavailable
+
+		var migrationErr *storage.MigrationError
+		if errors.As(err, &migrationErr) {
+			message = fmt.Sprintf(messageSchemaOutdated, migrationErr.Current, migrationErr.Expected)
+			if migrationErr.Dirty {
+				message = fmt.Sprintf(messageSchemaDirty, migrationErr.Current)
+			}
+		}
+
+		h.writeJSON(w, http.StatusServiceUnavailable, Error{
+			Status: Status{
+				Code:    http.StatusServiceUnavailable,
+				Message: errServiceUnavailable,
+			},
+			Message: message,
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, ReadyResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+	})
+}
+
+// GetAndDeleteQuoteByID обрабатывает HTTP запросы к конкретной цитате: получение (GET) и удаление (DELETE)
+func (h Handlers) GetAndDeleteQuoteByID(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetAndDeleteQuoteByID()"
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetQuoteByID(w, r)
+
+	case http.MethodDelete:
+		h.DeleteQuoteByID(w, r)
+
+	default:
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodDelete)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+	}
+}
+
+// GetQuoteByIDResponse описывает формат ответа при получении цитаты по ID
+type GetQuoteByIDResponse struct {
+	Status Status        `json:"status"`
+	Quote  storage.Quote `json:"quote"`
+	Meta   *QuoteMeta    `json:"meta,omitempty"`
+}
+
+// GetQuoteByID обрабатывает HTTP GET запрос на получение цитаты по ID
+func (h Handlers) GetQuoteByID(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetQuoteByID()"
+
+	id, err := h.parseQuoteID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	h.writeQuoteByID(w, r, op, id)
+}
+
+// writeQuoteByID отвечает цитатой с указанным ID (с производными полями при include=meta) или ошибкой
+func (h Handlers) writeQuoteByID(w http.ResponseWriter, r *http.Request, op string, id int) {
+	includeMeta, err := parseIncludeMeta(r.URL.Query())
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedInclude,
+		})
+
+		return
+	}
+
+	quote, err := h.Getter.GetQuoteByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuoteNotFoundByID,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	var meta *QuoteMeta
+	if includeMeta {
+		m := NewQuoteMeta(quote)
+		meta = &m
+	}
+
+	h.writeJSON(w, http.StatusOK, GetQuoteByIDResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quote: quote,
+		Meta:  meta,
+	})
+}
+
+// QuoteAction направляет запросы /quotes/{id}/{action} к обработчику действия над цитатой. Действия
+// зарегистрированы одним маршрутом, а не шаблонами /quotes/{id}/like и т. п.: иначе ServeMux считает их
+// конфликтующими с /quotes/slug/{slug}. На неизвестное действие ответ 404, как на любой незарегистрированный
+// путь: DELETE /quotes/1/unknown не должен удалять цитату 1.
+func (h Handlers) QuoteAction(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("action") {
+	case "restore":
+		h.RestoreQuoteByID(w, r)
+	case "like":
+		h.LikeQuoteByID(w, r)
+	case "similar":
+		h.GetSimilarQuotes(w, r)
+	case "categories":
+		h.AssignQuoteCategory(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetQuoteBySlug обрабатывает HTTP GET запрос на получение цитаты по slug (/quotes/slug/{slug})
+func (h Handlers) GetQuoteBySlug(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetQuoteBySlug()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	id, err := h.Getter.GetQuoteIDBySlug(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	h.writeQuoteByID(w, r, op, id)
+}
+
+// DeleteQuoteByIDResponse описывает формат ответа при удалении цитаты
+type DeleteQuoteByIDResponse struct {
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// DeleteQuoteByID обрабатывает HTTP DELETE запрос на удаление цитаты по ID. При IDEMPOTENT_DELETE
+// отвечает 204 без тела, даже если цитаты нет, иначе — 200 или 404.
+func (h Handlers) DeleteQuoteByID(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.DeleteQuoteByID()"
+
+	if r.Method != http.MethodDelete {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodDelete)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	idStr := r.PathValue("id")
+	if idStr == "" {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageNoID,
+		})
+
+		return
+	}
+
+	id, err := h.parseQuoteID(r.Context(), idStr)
+	if err == nil {
+		err = h.Manipulator.DeleteQuoteByID(r.Context(), id)
+	} else if !errors.Is(err, ErrNoQuotesFound) {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+	if errors.Is(err, ErrNoQuotesFound) && h.Config.IdempotentDelete {
+		h.Log.Debug(
+			"цитата для удаления не найдена, удаление идемпотентно",
+			slog.String("op", op),
+			slog.Int("id", id),
+		)
+
+		err = nil
+	}
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuoteNotFoundByID,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	if h.Config.IdempotentDelete {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, DeleteQuoteByIDResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Message: successDelete,
+	})
+}
+
+// PurgeQuotesResponse описывает формат ответа при удалении всех цитат
+type PurgeQuotesResponse struct {
+	Status  Status `json:"status"`
+	Deleted int    `json:"deleted"`
+}
+
+// PurgeQuotes обрабатывает HTTP DELETE запрос на удаление всех цитат. Требует параметр confirm=true,
+// чтобы таблицу нельзя было очистить случайным запросом; при включенной аутентификации — роль admin.
+func (h Handlers) PurgeQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.PurgeQuotes()"
+
+	if r.Method != http.MethodDelete {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodDelete)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	confirmed, err := strconv.ParseBool(r.URL.Query().Get("confirm"))
+	if err != nil || !confirmed {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("confirm", r.URL.Query().Get("confirm")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageConfirmRequired,
+		})
+
+		return
+	}
+
+	claims, _ := ClaimsFromContext(r.Context())
+	clientIP := utils.ClientIP(r, h.Config.TrustedProxies)
+
+	h.Log.Warn(
+		"удаление всех цитат",
+		slog.String("op", op),
+		slog.String("subject", claims.Subject),
+		slog.String("client_ip", clientIP),
+	)
+
+	count, err := h.Manipulator.PurgeQuotes(r.Context())
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.Log.Warn(
+		"все цитаты удалены",
+		slog.String("op", op),
+		slog.Int("deleted", count),
+		slog.String("subject", claims.Subject),
+		slog.String("client_ip", clientIP),
+	)
+
+	h.writeJSON(w, http.StatusOK, PurgeQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Deleted: count,
+	})
+}
+
+// Authors обрабатывает HTTP запросы к авторам: список (GET) и переименование (PATCH)
+func (h Handlers) Authors(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.Authors()"
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAuthors(w, r)
+
+	case http.MethodPatch:
+		h.RenameAuthor(w, r)
+
+	default:
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodPatch)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+	}
+}
+
+// GetAuthorsResponse описывает формат ответа со страницей авторов. HasMore означает, что за страницей
+// есть еще авторы: следующую можно запросить с offset, увеличенным на limit.
+type GetAuthorsResponse struct {
+	Status  Status                 `json:"status"`
+	Authors []storage.AuthorQuotes `json:"authors"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+	HasMore bool                   `json:"has_more"`
+}
+
+// GetAuthors обрабатывает HTTP GET запрос на получение авторов по алфавиту с числом цитат каждого.
+// Список отдается постранично: limit больше PAGE_SIZE_MAX уменьшается до него, чтобы при большом числе
+// авторов ответ не разрастался. Параметр prefix оставляет только авторов, чье имя начинается с него без учета
+// регистра, — для подсказок при вводе.
+func (h Handlers) GetAuthors(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetAuthors()"
+
+	prefix := r.URL.Query().Get("prefix")
+	limit := h.Config.PageSizeDefault
+	offset := 0
+
+	if h.rejectLongFilter(w, r, op, "prefix", prefix) {
+		return
+	}
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("limit", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedAuthorsLimit,
+			})
+
+			return
+		}
+		limit = min(parsed, h.Config.PageSizeMax)
+	}
+
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("offset", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedOffset,
+			})
+
+			return
+		}
+		offset = parsed
+	}
+
+	// Лишний автор сверх страницы показывает, есть ли следующая
+	authors, err := h.Getter.GetAuthors(r.Context(), prefix, limit+1, offset)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	hasMore := len(authors) > limit
+	if hasMore {
+		authors = authors[:limit]
+	}
+
+	h.writeJSON(w, http.StatusOK, GetAuthorsResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Authors: authors,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	})
+}
+
+// RenameAuthorRequest описывает формат запроса на переименование автора
+type RenameAuthorRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenameAuthorResponse описывает формат ответа при переименовании автора
+type RenameAuthorResponse struct {
+	Status  Status `json:"status"`
+	Renamed int    `json:"renamed"`
+}
+
+// RenameAuthor обрабатывает HTTP PATCH запрос на переименование автора у всех его цитат (например, чтобы
+// исправить опечатку в имени). Мягко удаленные цитаты не переименовываются.
+func (h Handlers) RenameAuthor(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.RenameAuthor()"
+
+	if r.Method != http.MethodPatch {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPatch)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	if !isJSON(r) {
+		h.Log.Error(
+			errUnsupportedMedia,
+			slog.String("op", op),
+			slog.String("content_type", r.Header.Get("Content-Type")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+			Status: Status{
+				Code:    http.StatusUnsupportedMediaType,
+				Message: errUnsupportedMedia,
+			},
+			Message: messageJSONRequired,
+		})
+
+		return
+	}
+
+	limitBody(w, r, h.Config.MaxBodyBytes)
+
+	var req RenameAuthorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if h.rejectLargeBody(w, r, op, err) {
+		return
+	}
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+		})
+
+		return
+	}
+	defer r.Body.Close()
+
+	renamed, err := h.Manipulator.RenameAuthor(r.Context(), req.From, req.To)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, ValidationErrorResponse{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageValidationFailed,
+				Errors:  validationErr.Fields,
+			})
+
+			return
+		}
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageAuthorNotFound,
+			})
+
+			return
+		}
+		if errors.Is(err, ErrDuplicateEntry) {
+			h.Log.Error(
+				errConflict,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusConflict, Error{
+				Status: Status{
+					Code:    http.StatusConflict,
+					Message: errConflict,
+				},
+				Message: messageAuthorConflict,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.Log.Info(
+		"автор переименован",
+		slog.String("op", op),
+		slog.String("from", req.From),
+		slog.String("to", req.To),
+		slog.Int("renamed", renamed),
+	)
+
+	h.writeJSON(w, http.StatusOK, RenameAuthorResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Renamed: renamed,
+	})
+}
+
+// RestoreQuoteByIDResponse описывает формат ответа при восстановлении цитаты
+type RestoreQuoteByIDResponse struct {
+	Status Status        `json:"status"`
+	Quote  storage.Quote `json:"quote"`
+}
+
+// RestoreQuoteByID обрабатывает HTTP POST запрос на восстановление мягко удаленной цитаты по ID
+func (h Handlers) RestoreQuoteByID(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.RestoreQuoteByID()"
+
+	if r.Method != http.MethodPost {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	id, err := h.parseQuoteID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	quote, err := h.Manipulator.RestoreQuoteByID(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNoQuotesFound):
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuoteNotFoundByID,
+			})
+
+			return
+
+		case errors.Is(err, ErrNotDeleted), errors.Is(err, ErrDuplicateEntry):
+			message := messageQuoteNotDeleted
+			if errors.Is(err, ErrDuplicateEntry) {
+				message = messageQuoteAlreadyExists
+			}
+
+			h.Log.Error(
+				errConflict,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusConflict, Error{
+				Status: Status{
+					Code:    http.StatusConflict,
+					Message: errConflict,
+				},
+				Message: message,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, RestoreQuoteByIDResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quote: quote,
+	})
+}
+
+// LikeQuoteByIDResponse описывает формат ответа при лайке цитаты
+type LikeQuoteByIDResponse struct {
+	Status Status `json:"status"`
+	ID     int    `json:"id"`
+	Likes  int    `json:"likes"`
+}
+
+// LikeQuoteByID обрабатывает HTTP POST запрос на лайк цитаты по ID
+func (h Handlers) LikeQuoteByID(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.LikeQuoteByID()"
+
+	if r.Method != http.MethodPost {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	id, err := h.parseQuoteID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	likes, err := h.Manipulator.LikeQuoteByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuoteNotFoundByID,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, LikeQuoteByIDResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		ID:    id,
+		Likes: likes,
+	})
+}
+
+// SearchQuotes обрабатывает HTTP GET запрос на полнотекстовый поиск цитат по параметру q.
+// Возвращает до 100 цитат по убыванию релевантности; пустой список, если ничего не нашлось.
+func (h Handlers) SearchQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.SearchQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageNoSearchQuery,
+		})
+
+		return
+	}
+
+	if h.rejectLongFilter(w, r, op, "q", query) {
+		return
+	}
+
+	quotes, err := h.Getter.SearchQuotes(r.Context(), query, maxSearchResults)
+	if err != nil {
+		if errors.Is(err, ErrNotSupported) {
+			h.Log.Error(
+				errNotImplemented,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotImplemented, Error{
+				Status: Status{
+					Code:    http.StatusNotImplemented,
+					Message: errNotImplemented,
+				},
+				Message: messageSearchUnsupported,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, GetQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quotes: quotes,
+	})
+}
+
+// GetQuotesBatch обрабатывает HTTP GET запрос на получение нескольких цитат по списку ID в параметре ids
+// (через запятую, не больше maxBatchIDs). Цитаты, которых нет, просто отсутствуют в ответе.
+func (h Handlers) GetQuotesBatch(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetQuotesBatch()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: fmt.Sprintf(messageMalformedIDs, maxBatchIDs),
+		})
+
+		return
+	}
+
+	quotes, err := h.Getter.GetQuotesByIDs(r.Context(), ids)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, GetQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quotes: quotes,
+	})
+}
+
+// GetRecentQuotes обрабатывает HTTP GET запрос на получение последних добавленных цитат, начиная с самой новой.
+// Количество задается параметром limit (по умолчанию 10); значения больше maxRecentLimit урезаются до него.
+func (h Handlers) GetRecentQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetRecentQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	limit := defaultRecentLimit
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("limit", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messagePositiveLimit,
+			})
+
+			return
+		}
+		limit = min(limit, maxRecentLimit)
+	}
+
+	quotes, err := h.Getter.GetRecentQuotes(r.Context(), limit)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, GetQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quotes: quotes,
+	})
+}
+
+// QuoteExistsResponse описывает ответ на проверку, есть ли уже такая цитата
+type QuoteExistsResponse struct {
+	Status Status `json:"status"`
+	Exists bool   `json:"exists"`
+}
+
+// QuoteExists обрабатывает HTTP GET запрос на проверку, есть ли уже цитата с такими author и quote.
+// Сравнение то же, что при добавлении, поэтому клиенту не нужно ловить 409, чтобы узнать о дубликате.
+func (h Handlers) QuoteExists(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.QuoteExists()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	author := r.URL.Query().Get("author")
+	quote := r.URL.Query().Get("quote")
+
+	if author == "" || quote == "" {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageExistsParams,
+		})
+
+		return
+	}
+
+	exists, err := h.Getter.QuoteExists(r.Context(), author, quote)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+
+	h.writeJSON(w, http.StatusOK, QuoteExistsResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Exists: exists,
+	})
+}
+
+// parseIDs разбирает список ID через запятую. Пустой список, нечисловые и неположительные ID,
+// а также больше maxBatchIDs элементов — ошибка. Повторы отбрасываются.
+func parseIDs(raw string) ([]int, error) {
+	const op = "getcitation.parseIDs()"
+
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("%s: empty ids", op)
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxBatchIDs {
+		return nil, fmt.Errorf("%s: %d ids, at most %d allowed", op, len(parts), maxBatchIDs)
+	}
+
+	ids := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if id < 1 {
+			return nil, fmt.Errorf("%s: non-positive id %d", op, id)
+		}
+
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetSimilarQuotesResponse описывает формат ответа со списком похожих цитат
+type GetSimilarQuotesResponse struct {
+	Status Status          `json:"status"`
+	ID     int             `json:"id"`
+	Quotes []storage.Quote `json:"quotes"`
+}
+
+// GetSimilarQuotes обрабатывает HTTP GET запрос на получение цитат, похожих по тексту на цитату с указанным ID.
+// Количество задается параметром limit (по умолчанию 5, не больше 50).
+func (h Handlers) GetSimilarQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetSimilarQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	id, err := h.parseQuoteID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeQuoteIDError(w, r, op, err)
+		return
+	}
+
+	limit := defaultSimilarLimit
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSimilarLimit {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("limit", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedLimit,
+			})
+
+			return
+		}
+	}
+
+	quotes, err := h.Getter.GetSimilarQuotes(r.Context(), id, limit)
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuoteNotFoundByID,
+			})
+
+			return
+		}
+		if errors.Is(err, ErrNotSupported) {
+			h.Log.Error(
+				errNotImplemented,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotImplemented, Error{
+				Status: Status{
+					Code:    http.StatusNotImplemented,
+					Message: errNotImplemented,
+				},
+				Message: messageSimilarUnsupported,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, GetSimilarQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		ID:     id,
+		Quotes: quotes,
+	})
+}
+
+// GetRandomQuoteResponse описывает формат ответа при получении случайной цитаты
+type GetRandomQuoteResponse struct {
+	Status Status        `json:"status"`
+	Quote  storage.Quote `json:"quote"`
+	Meta   *QuoteMeta    `json:"meta,omitempty"`
+}
+
+// GetRandomQuote обрабатывает HTTP GET запрос на получение случайной цитаты
+func (h Handlers) GetRandomQuote(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetRandomQuote()"
+
+	if r.Method == http.MethodHead {
+		h.HeadRandomQuote(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodHead)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	var err error
+
+	fair := false
+
+	if raw := r.URL.Query().Get("fair"); raw != "" {
+		fair, err = strconv.ParseBool(raw)
+		if err != nil {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedFair,
+			})
+
+			return
+		}
+	}
+
+	includeMeta, err := parseIncludeMeta(r.URL.Query())
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedInclude,
+		})
+
+		return
+	}
+
+	excludeAuthor := r.URL.Query().Get("exclude_author")
+
+	if h.rejectLongFilter(w, r, op, "exclude_author", excludeAuthor) {
+		return
+	}
+
+	lang := h.preferredLanguage(r)
+
+	random := h.Getter.GetRandomQuote
+	if fair {
+		random = h.Getter.GetFairRandomQuote
+	}
+
+	quote, err := random(r.Context(), excludeAuthor, lang)
+	if errors.Is(err, ErrNoQuotesFound) && lang != "" {
+		// На подходящем языке цитат не осталось (например, все принадлежат exclude_author) — берем любую
+		quote, err = random(r.Context(), excludeAuthor, "")
+	}
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusNotFound, Error{
+				Status: Status{
+					Code:    http.StatusNotFound,
+					Message: errNotFound,
+				},
+				Message: messageQuotesNotFound,
+			})
+
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.Header().Add("Vary", "Accept-Language")
+	if quote.Language != "" {
+		w.Header().Set("Content-Language", quote.Language)
+	}
+	var meta *QuoteMeta
+	if includeMeta {
+		m := NewQuoteMeta(quote)
+		meta = &m
+	}
+
+	h.writeJSON(w, http.StatusOK, GetRandomQuoteResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Quote: quote,
+		Meta:  meta,
+	})
+}
+
+// HeadRandomQuote обрабатывает HTTP HEAD запрос к случайной цитате: 200, если цитаты есть, и 404, если нет.
+// Сама цитата не выбирается, поэтому HEAD не засчитывается как просмотр.
+func (h Handlers) HeadRandomQuote(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.HeadRandomQuote()"
+
+	count, err := h.Getter.CountQuotes(r.Context(), "")
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		w.WriteHeader(code)
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.Header().Set("Content-Type", "application/json")
+
+	if count == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetRandomQuoteText обрабатывает HTTP GET запрос на случайную цитату обычным текстом ("цитата" — автор)
+// для терминалов и скриптов. Ответ, в том числе об ошибке, всегда текстовый, независимо от Accept.
+func (h Handlers) GetRandomQuoteText(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetRandomQuoteText()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeText(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	lang := h.preferredLanguage(r)
+
+	quote, err := h.Getter.GetRandomQuote(r.Context(), "", lang)
+	if errors.Is(err, ErrNoQuotesFound) && lang != "" {
+		quote, err = h.Getter.GetRandomQuote(r.Context(), "", "")
+	}
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeText(w, http.StatusNotFound, messageQuotesNotFound)
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeText(w, code, message)
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.Header().Add("Vary", "Accept-Language")
+	if quote.Language != "" {
+		w.Header().Set("Content-Language", quote.Language)
+	}
+
+	h.writeText(w, http.StatusOK, fmt.Sprintf("\"%s\" — %s", quote.Quote, quote.Author))
+}
+
+// CountQuotesResponse описывает формат ответа при подсчете цитат
+type CountQuotesResponse struct {
+	Status Status `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// CountQuotes обрабатывает HTTP GET запрос на подсчет цитат с возможным фильтром по автору
+func (h Handlers) CountQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.CountQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	author := r.URL.Query().Get("author")
+
+	if h.rejectLongFilter(w, r, op, "author", author) {
+		return
+	}
+
+	count, err := h.Getter.CountQuotes(r.Context(), author)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, CountQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Count: count,
+	})
+}
+
+// StatsResponse описывает формат ответа со сводными показателями по цитатам
+type StatsResponse struct {
+	Status Status            `json:"status"`
+	Stats  storage.Stats     `json:"stats"`
+	Pool   storage.PoolStats `json:"pool"`
+}
+
+// GetStats обрабатывает HTTP GET запрос на получение сводных показателей: число цитат и авторов,
+// самый плодовитый автор, а также состояние пула соединений с БД
+func (h Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetStats()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	stats, err := h.Getter.GetStats(r.Context())
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, StatsResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Stats: stats,
+		Pool:  storage.NewPoolStats(h.Pool.PoolStats()),
+	})
+}
+
+// TextStatsResponse описывает формат ответа с показателями текста цитат
+type TextStatsResponse struct {
+	Status Status            `json:"status"`
+	Stats  storage.TextStats `json:"stats"`
+}
+
+// GetTextStats обрабатывает HTTP GET запрос на получение показателей текста цитат: число слов и символов,
+// средняя длина, самая длинная и самая короткая цитата
+func (h Handlers) GetTextStats(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetTextStats()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	stats, err := h.Getter.GetTextStats(r.Context())
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, TextStatsResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Stats: stats,
+	})
+}
+
+// AuditLogResponse описывает формат ответа при получении журнала аудита
+type AuditLogResponse struct {
+	Status  Status               `json:"status"`
+	Entries []storage.AuditEntry `json:"entries"`
+}
+
+// GetAuditLog обрабатывает HTTP GET запрос на получение журнала аудита (с последних записей).
+// Поддерживает limit и offset, как список цитат; при включенной аутентификации доступен только роли admin.
+func (h Handlers) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetAuditLog()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	if !h.isAdmin(r) {
+		h.Log.Error(
+			errForbidden,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusForbidden, Error{
+			Status: Status{
+				Code:    http.StatusForbidden,
+				Message: errForbidden,
+			},
+			Message: messageAdminRequired,
+		})
+
+		return
+	}
+
+	limit := h.Config.PageSizeDefault
+	offset := 0
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > h.Config.PageSizeMax {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("limit", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: fmt.Sprintf(messageMalformedPageLimit, h.Config.PageSizeMax),
+			})
+
+			return
+		}
+		limit = parsed
+	}
+
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.String("offset", raw),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedOffset,
+			})
+
+			return
+		}
+		offset = parsed
+	}
+
+	entries, err := h.Getter.GetAuditLog(r.Context(), limit, offset)
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, AuditLogResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Entries: entries,
+	})
+}
+
+// ExportQuotes обрабатывает HTTP GET запрос на выгрузку цитат в формате NDJSON (одна цитата на строку).
+// Цитаты пишутся в ответ по мере чтения из БД и периодически сбрасываются клиенту, поэтому память не
+// зависит от размера таблицы. После отправки заголовков ошибки только логируются.
+func (h Handlers) ExportQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.ExportQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != exportFormatNDJSON {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedFormat,
+		})
+
+		return
+	}
+
+	var filter storage.QuoteFilter
+
+	for _, author := range r.URL.Query()["author"] {
+		if author != "" {
+			filter.Authors = append(filter.Authors, author)
+		}
+	}
+
+	if h.rejectLongFilter(w, r, op, "author", filter.Authors...) {
+		return
+	}
+
+	if len(filter.Authors) > maxAuthorFilters {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Int("authors", len(filter.Authors)),
+			slog.String("path", r.URL.Path),
+		)
 
-// DeleteQuoteByIDResponse описывает формат ответа при удалении цитаты
-type DeleteQuoteByIDResponse struct {
-	Status  Status `json:"status"`
-	Message string `json:"message"`
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageTooManyAuthors,
+		})
+
+		return
+	}
+
+	// Выгрузка большой таблицы может длиться дольше, чем SERVER_WRITETIMEOUT.
+	controller := http.NewResponseController(w)
+
+	err := controller.SetWriteDeadline(time.Time{})
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	written := 0
+
+	err = h.Getter.ExportQuotes(r.Context(), filter, func(quote storage.Quote) error {
+		err := encoder.Encode(quote)
+		if err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			return controller.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		h.Log.Error(
+			"выгрузка прервана",
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.Int("written", written),
+			slog.String("path", r.URL.Path),
+		)
+		return
+	}
+
+	controller.Flush()
+}
+
+// StreamQuotes обрабатывает HTTP GET запрос на подписку на новые цитаты через Server-Sent Events
+func (h Handlers) StreamQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.StreamQuotes()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	// Поток живёт дольше, чем SERVER_WRITETIMEOUT, поэтому снимаем дедлайн записи для этого соединения.
+	controller := http.NewResponseController(w)
+
+	err := controller.SetWriteDeadline(time.Time{})
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	quotes, unsubscribe := h.Stream.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	controller.Flush()
+
+	ticker := time.NewTicker(h.Config.StreamKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case quote, ok := <-quotes:
+			if !ok {
+				// Сервис останавливается: говорим клиенту об этом явно, а не обрываем поток молча
+				fmt.Fprint(w, "event: shutdown\ndata: {}\n\n")
+				controller.Flush()
+				return
+			}
+
+			data, err := json.Marshal(quote)
+			if err != nil {
+				h.Log.Error(
+					errInternalServerError,
+					slog.String("op", op),
+					slog.Any("error", err),
+					slog.String("path", r.URL.Path),
+				)
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "id: %d\nevent: quote\ndata: %s\n\n", quote.ID, data)
+			if err != nil {
+				return
+			}
+			controller.Flush()
+
+		case <-ticker.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			controller.Flush()
+		}
+	}
+}
+
+// DBManipulator описывает интерфейс для операций с БД, связанными с цитатами (создание, удаление)
+type DBManipulator interface {
+	CreateQuote(ctx context.Context, quote storage.Quote) (int, error)
+	CreateQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error)
+	UpsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error)
+	ImportQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error)
+	DeleteQuoteByID(ctx context.Context, id int) error
+	PurgeQuotes(ctx context.Context) (int, error)
+	RenameAuthor(ctx context.Context, from string, to string) (int, error)
+	RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error)
+	LikeQuoteByID(ctx context.Context, id int) (int, error)
+	CreateCategory(ctx context.Context, name string) (int, error)
+	AssignCategory(ctx context.Context, quoteID int, categoryID int) error
+}
+
+// DBGetter описывает интерфейс для получения цитат из БД
+type DBGetter interface {
+	GetRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error)
+	GetFairRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error)
+	QuoteLanguages(ctx context.Context) ([]string, error)
+	QuoteExists(ctx context.Context, author string, quote string) (bool, error)
+	GetQuoteByID(ctx context.Context, id int) (storage.Quote, error)
+	GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error)
+	GetQuoteIDBySlug(ctx context.Context, slug string) (int, error)
+	GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error)
+	GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error)
+	GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error)
+	SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error)
+	GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error)
+	CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error)
+	ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error
+	CountQuotes(ctx context.Context, authorFilter string) (int, error)
+	GetStats(ctx context.Context) (storage.Stats, error)
+	GetTextStats(ctx context.Context) (storage.TextStats, error)
+	GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error)
+	GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error)
+	GetCategories(ctx context.Context) ([]storage.Category, error)
+}
+
+// QuoteStore описывает хранилище цитат целиком — его реализует каждый бэкенд (PostgreSQL, SQLite)
+type QuoteStore interface {
+	DBManipulator
+	DBGetter
+	ReadinessChecker
+	PoolReporter
+}
+
+// ReadinessChecker сообщает, готово ли хранилище обслуживать запросы
+type ReadinessChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// PoolReporter сообщает состояние пула соединений с БД
+type PoolReporter interface {
+	PoolStats() sql.DBStats
+}
+
+// EventPublisher описывает получателя событий об изменении цитат (например, вебхук)
+type EventPublisher interface {
+	Publish(event webhook.Event)
+}
+
+// StreamPublisher описывает получателя новых цитат для потоковой рассылки (SSE)
+type StreamPublisher interface {
+	Publish(quote storage.Quote)
+}
+
+// Service реализует бизнес-логику приложения — создание, удаление и получение цитат
+type Service struct {
+	Log    *slog.Logger
+	Config config.Config
+
+	// BannedWords — слова, с которыми нельзя добавить цитату (BANNED_WORDS_PATH). Список подменяется
+	// целиком при перечитывании, поэтому проверка, которая уже идет, доходит до конца со старым.
+	BannedWords *atomic.Pointer[BannedWords]
+
+	Manipulator DBManipulator
+	Getter      DBGetter
+	Events      EventPublisher
+	Stream      StreamPublisher
+}
+
+// CreateQuote создает новую цитату через слой хранилища и обрабатывает возможные ошибки дубликатов
+func (s Service) CreateQuote(ctx context.Context, author string, quote string, lang string, source string) (int, error) {
+	const op = "getcitation.Service.CreateQuote()"
+
+	err := validateQuote(author, quote, lang, source)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = s.checkBannedWords(author, quote)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	lang = canonicalLanguage(lang)
+
+	id, err := s.Manipulator.CreateQuote(withActor(ctx), storage.Quote{
+		Author:   author,
+		Quote:    quote,
+		Language: lang,
+		Source:   source,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicateEntry) {
+			return 0, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Events.Publish(webhook.Event{
+		Type:      webhook.EventQuoteCreated,
+		ID:        id,
+		Author:    author,
+		Quote:     quote,
+		Timestamp: time.Now().UTC(),
+	})
+	s.Stream.Publish(storage.Quote{
+		ID:     id,
+		Author: author,
+		Quote:  quote,
+	})
+	return id, nil
+}
+
+// ImportQuotes проверяет строки импорта так же, как CreateQuote, и добавляет прошедшие проверку одной
+// транзакцией. Возвращает итог каждой обработанной строки. При StopOnError обработка заканчивается на
+// первой строке с ошибкой: она попадает в итоги, а цитаты из строк до нее все равно добавляются.
+// При Strict первая строка с ошибкой или дубликат отменяют импорт целиком: возвращается ErrImportRejected
+// и итог только этой строки.
+func (s Service) ImportQuotes(ctx context.Context, rows []ImportRow, options ImportOptions) ([]ImportResult, error) {
+	const op = "getcitation.Service.ImportQuotes()"
+
+	results := make([]ImportResult, 0, len(rows))
+	quotes := make([]storage.Quote, 0, len(rows))
+	// pending[i] — номер итога строки, из которой взята quotes[i]
+	pending := make([]int, 0, len(rows))
+
+	for _, row := range rows {
+		reason := row.Reason
+		if reason == "" {
+			err := validateQuote(row.Author, row.Quote, row.Language, row.Source)
+			if err == nil {
+				err = s.checkBannedWords(row.Author, row.Quote)
+			}
+			if err != nil {
+				reason = err.Error()
+			}
+		}
+
+		if reason != "" {
+			result := ImportResult{
+				Line:   row.Line,
+				Status: ImportError,
+				Reason: reason,
+			}
+			if options.Strict {
+				return []ImportResult{result}, fmt.Errorf("%s: %w", op, ErrImportRejected)
+			}
+
+			results = append(results, result)
+			if options.StopOnError {
+				break
+			}
+			continue
+		}
+
+		pending = append(pending, len(results))
+		results = append(results, ImportResult{Line: row.Line})
+		quotes = append(quotes, storage.Quote{
+			Author:   row.Author,
+			Quote:    row.Quote,
+			Language: canonicalLanguage(row.Language),
+			Source:   row.Source,
+		})
+	}
+
+	if len(quotes) == 0 {
+		return results, nil
+	}
+
+	ids, err := s.Manipulator.ImportQuotes(withActor(ctx), quotes, options.Strict)
+	if err != nil {
+		var rowErr *storage.RowError
+		if errors.As(err, &rowErr) && errors.Is(err, storage.ErrDuplicateEntry) {
+			result := ImportResult{
+				Line:   results[pending[rowErr.Row]].Line,
+				Status: ImportDuplicate,
+			}
+			return []ImportResult{result}, fmt.Errorf("%s: %w: %w", op, ErrImportRejected, err)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, id := range ids {
+		result := &results[pending[i]]
+
+		if id == 0 {
+			result.Status = ImportDuplicate
+			continue
+		}
+		result.Status, result.ID = ImportInserted, id
+
+		quote := quotes[i]
+		quote.ID = id
+
+		s.Events.Publish(webhook.Event{
+			Type:      webhook.EventQuoteCreated,
+			ID:        id,
+			Author:    quote.Author,
+			Quote:     quote.Quote,
+			Timestamp: time.Now().UTC(),
+		})
+		s.Stream.Publish(quote)
+	}
+
+	return results, nil
+}
+
+// checkBannedWords возвращает ErrBannedWord, если автор или текст цитаты содержат запрещенное слово
+func (s Service) checkBannedWords(author string, quote string) error {
+	bannedWords := s.BannedWords.Load()
+
+	for _, text := range []string{author, quote} {
+		if word := bannedWords.Find(text); word != "" {
+			return fmt.Errorf("%w: %q", ErrBannedWord, word)
+		}
+	}
+	return nil
+}
+
+// CreateQuoteIdempotent создает цитату с учетом ключа идемпотентности: повторный запрос с тем же ключом возвращает ID исходной цитаты
+func (s Service) CreateQuoteIdempotent(ctx context.Context, key string, author string, quote string, lang string, source string) (int, error) {
+	const op = "getcitation.Service.CreateQuoteIdempotent()"
+
+	err := validateQuote(author, quote, lang, source)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = s.checkBannedWords(author, quote)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	lang = canonicalLanguage(lang)
+
+	id, replayed, err := s.Manipulator.CreateQuoteIdempotent(withActor(ctx), key, storage.Quote{
+		Author:   author,
+		Quote:    quote,
+		Language: lang,
+		Source:   source,
+	}, s.Config.IdempotencyKeyTTL)
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicateEntry) {
+			return 0, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+		}
+		if errors.Is(err, storage.ErrKeyReused) {
+			return 0, fmt.Errorf("%s: %w", op, ErrKeyReused)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if replayed {
+		s.Log.Debug(
+			"повторный запрос с ключом идемпотентности",
+			slog.String("op", op),
+			slog.Int("id", id),
+		)
+		return id, nil
+	}
+
+	s.Events.Publish(webhook.Event{
+		Type:      webhook.EventQuoteCreated,
+		ID:        id,
+		Author:    author,
+		Quote:     quote,
+		Timestamp: time.Now().UTC(),
+	})
+	s.Stream.Publish(storage.Quote{
+		ID:     id,
+		Author: author,
+		Quote:  quote,
+	})
+	return id, nil
+}
+
+// UpsertQuote создает цитату, а если такая же цитата уже есть, возвращает ее ID и true вместо ErrDuplicateEntry
+func (s Service) UpsertQuote(ctx context.Context, author string, quote string, lang string, source string) (int, bool, error) {
+	const op = "getcitation.Service.UpsertQuote()"
+
+	err := validateQuote(author, quote, lang, source)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	err = s.checkBannedWords(author, quote)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	lang = canonicalLanguage(lang)
+
+	id, existing, err := s.Manipulator.UpsertQuote(withActor(ctx), storage.Quote{
+		Author:   author,
+		Quote:    quote,
+		Language: lang,
+		Source:   source,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if existing {
+		return id, true, nil
+	}
+
+	s.Events.Publish(webhook.Event{
+		Type:      webhook.EventQuoteCreated,
+		ID:        id,
+		Author:    author,
+		Quote:     quote,
+		Timestamp: time.Now().UTC(),
+	})
+	s.Stream.Publish(storage.Quote{
+		ID:     id,
+		Author: author,
+		Quote:  quote,
+	})
+	return id, false, nil
+}
+
+// DeleteQuoteByID удаляет цитату по ID, возвращает ошибку, если цитата не найдена
+func (s Service) DeleteQuoteByID(ctx context.Context, id int) error {
+	const op = "getcitation.Service.DeleteQuoteByID()"
+
+	err := s.Manipulator.DeleteQuoteByID(withActor(ctx), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.Events.Publish(webhook.Event{
+		Type:      webhook.EventQuoteDeleted,
+		ID:        id,
+		Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+// PurgeQuotes удаляет все цитаты и возвращает их количество
+func (s Service) PurgeQuotes(ctx context.Context) (int, error) {
+	const op = "getcitation.Service.PurgeQuotes()"
+
+	count, err := s.Manipulator.PurgeQuotes(withActor(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
 }
 
-// DeleteQuoteByID обрабатывает HTTP DELETE запрос на удаление цитаты по ID
-func (h Handlers) DeleteQuoteByID(w http.ResponseWriter, r *http.Request) {
-	const op = "getcitation.Transport.DeleteQuoteByID()"
+// RenameAuthor переименовывает автора from в to у всех его цитат и возвращает число переименованных цитат
+func (s Service) RenameAuthor(ctx context.Context, from string, to string) (int, error) {
+	const op = "getcitation.Service.RenameAuthor()"
 
-	if r.Method != http.MethodDelete {
-		h.Log.Error(
-			errMethodNotAllowed,
-			slog.String("op", op),
-			slog.String("path", r.URL.Path),
-		)
+	var v validator
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	v.required("from", from)
+	v.required("to", to)
+	v.maxLength("to", to, maxAuthorLength)
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusMethodNotAllowed,
-				Message: errMethodNotAllowed,
-			},
-		})
+	err := v.err()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
 
-		return
+	renamed, err := s.Manipulator.RenameAuthor(withActor(ctx), from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		if errors.Is(err, storage.ErrDuplicateEntry) {
+			return 0, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	return renamed, nil
+}
 
-	parts := strings.Split(r.URL.Path, "/")
+// RestoreQuoteByID восстанавливает мягко удаленную цитату по ID и возвращает ее
+func (s Service) RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "getcitation.Service.RestoreQuoteByID()"
 
-	idStr := parts[2]
-	if idStr == "" {
-		h.Log.Error(
-			errBadRequest,
-			slog.String("op", op),
-			slog.String("path", r.URL.Path),
-		)
+	quote, err := s.Manipulator.RestoreQuoteByID(withActor(ctx), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		case errors.Is(err, storage.ErrNotDeleted):
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrNotDeleted)
+		case errors.Is(err, storage.ErrDuplicateEntry):
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+		}
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
+	s.Events.Publish(webhook.Event{
+		Type:      webhook.EventQuoteRestored,
+		ID:        quote.ID,
+		Author:    quote.Author,
+		Quote:     quote.Quote,
+		Timestamp: time.Now().UTC(),
+	})
+	return quote, nil
+}
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusBadRequest,
-				Message: errBadRequest,
-			},
-			Message: messageNoID,
-		})
+// LikeQuoteByID увеличивает счетчик лайков цитаты по ID и возвращает его новое значение
+func (s Service) LikeQuoteByID(ctx context.Context, id int) (int, error) {
+	const op = "getcitation.Service.LikeQuoteByID()"
 
-		return
+	likes, err := s.Manipulator.LikeQuoteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	return likes, nil
+}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		h.Log.Error(
-			errBadRequest,
-			slog.String("op", op),
-			slog.Any("error", err),
-			slog.String("path", r.URL.Path),
-		)
+// GetRandomQuote получает случайную цитату из хранилища, пропуская цитаты автора excludeAuthor и ограничиваясь языком lang (если они заданы)
+func (s Service) GetRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error) {
+	const op = "getcitation.Service.GetRandomQuote()"
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
+	quote, err := s.Getter.GetRandomQuote(ctx, excludeAuthor, lang)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return quote, nil
+}
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusBadRequest,
-				Message: errBadRequest,
-			},
-			Message: messageMalformedID,
-		})
+// GetFairRandomQuote получает случайную цитату с равной вероятностью для каждого автора, кроме excludeAuthor, на языке lang (если он задан)
+func (s Service) GetFairRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error) {
+	const op = "getcitation.Service.GetFairRandomQuote()"
 
-		return
+	quote, err := s.Getter.GetFairRandomQuote(ctx, excludeAuthor, lang)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, err)
 	}
+	return quote, nil
+}
 
-	err = h.Manipulator.DeleteQuoteByID(id)
-	if err != nil {
-		if errors.Is(err, ErrNoQuotesFound) {
-			h.Log.Error(
-				errNotFound,
-				slog.String("op", op),
-				slog.Any("error", err),
-				slog.String("path", r.URL.Path),
-			)
+// QuoteLanguages возвращает языки, на которых есть хотя бы одна цитата
+func (s Service) QuoteLanguages(ctx context.Context) ([]string, error) {
+	const op = "getcitation.Service.QuoteLanguages()"
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
+	languages, err := s.Getter.QuoteLanguages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return languages, nil
+}
 
-			json.NewEncoder(w).Encode(Error{
-				Status: Status{
-					Code:    http.StatusNotFound,
-					Message: errNotFound,
-				},
-				Message: messageQuoteNotFoundByID,
-			})
+// GetQuoteByID получает цитату по ID, возвращает ошибку, если цитата не найдена
+func (s Service) GetQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "getcitation.Service.GetQuoteByID()"
 
-			return
+	quote, err := s.Getter.GetQuoteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
 		}
-		h.Log.Error(
-			errInternalServerError,
-			slog.String("op", op),
-			slog.Any("error", err),
-			slog.String("path", r.URL.Path),
-		)
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return quote, nil
+}
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusInternalServerError,
-				Message: errInternalServerError,
-			},
-		})
+// GetQuoteIDBySlug получает ID цитаты по ее slug, возвращает ошибку, если цитата не найдена
+func (s Service) GetQuoteIDBySlug(ctx context.Context, slug string) (int, error) {
+	const op = "getcitation.Service.GetQuoteIDBySlug()"
 
-		return
+	id, err := s.Getter.GetQuoteIDBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	return id, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+// GetQuoteIDByUUID получает ID цитаты по ее UUID, возвращает ошибку, если цитата не найдена
+func (s Service) GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error) {
+	const op = "getcitation.Service.GetQuoteIDByUUID()"
 
-	json.NewEncoder(w).Encode(DeleteQuoteByIDResponse{
-		Status: Status{
-			Code: http.StatusOK,
-		},
-		Message: successDelete,
-	})
+	id, err := s.Getter.GetQuoteIDByUUID(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
 }
 
-// GetRandomQuoteResponse описывает формат ответа при получении случайной цитаты
-type GetRandomQuoteResponse struct {
-	Status Status        `json:"status"`
-	Quote  storage.Quote `json:"quote"`
+// GetQuotesByIDs получает цитаты с указанными ID; отсутствующие в результат не попадают
+func (s Service) GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error) {
+	const op = "getcitation.Service.GetQuotesByIDs()"
+
+	quotes, err := s.Getter.GetQuotesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return quotes, nil
 }
 
-// GetRandomQuote обрабатывает HTTP GET запрос на получение случайной цитаты
-func (h Handlers) GetRandomQuote(w http.ResponseWriter, r *http.Request) {
-	const op = "getcitation.Transport.GetRandomQuote()"
+// GetRecentQuotes получает до limit последних добавленных цитат
+func (s Service) GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error) {
+	const op = "getcitation.Service.GetRecentQuotes()"
 
-	if r.Method != http.MethodGet {
-		h.Log.Error(
-			errMethodNotAllowed,
-			slog.String("op", op),
-			slog.String("path", r.URL.Path),
-		)
+	quotes, err := s.Getter.GetRecentQuotes(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return quotes, nil
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
+// QuoteExists сообщает, есть ли уже цитата с таким автором и текстом
+func (s Service) QuoteExists(ctx context.Context, author string, quote string) (bool, error) {
+	const op = "getcitation.Service.QuoteExists()"
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusMethodNotAllowed,
-				Message: errMethodNotAllowed,
-			},
-		})
+	exists, err := s.Getter.QuoteExists(ctx, author, quote)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return exists, nil
+}
 
-		return
+// GetSimilarQuotes получает цитаты, похожие по тексту на цитату с указанным ID
+func (s Service) GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error) {
+	const op = "getcitation.Service.GetSimilarQuotes()"
+
+	quotes, err := s.Getter.GetSimilarQuotes(ctx, id, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		case errors.Is(err, storage.ErrNotSupported):
+			return nil, fmt.Errorf("%s: %w", op, ErrNotSupported)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	return quotes, nil
+}
+
+// SearchQuotes выполняет полнотекстовый поиск цитат
+func (s Service) SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error) {
+	const op = "getcitation.Service.SearchQuotes()"
 
-	quote, err := h.Getter.GetRandomQuote()
+	quotes, err := s.Getter.SearchQuotes(ctx, query, limit)
 	if err != nil {
-		h.Log.Error(
-			errInternalServerError,
-			slog.String("op", op),
-			slog.Any("error", err),
-			slog.String("path", r.URL.Path),
-		)
+		if errors.Is(err, storage.ErrNotSupported) {
+			return nil, fmt.Errorf("%s: %w", op, ErrNotSupported)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return quotes, nil
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+// GetQuotes возвращает список цитат с учетом фильтра
+func (s Service) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	const op = "getcitation.Service.GetQuotes()"
 
-		json.NewEncoder(w).Encode(Error{
-			Status: Status{
-				Code:    http.StatusInternalServerError,
-				Message: errInternalServerError,
-			},
-		})
+	quotes, err := s.Getter.GetQuotes(ctx, filter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
+		}
+		return nil, err
+	}
+	return quotes, nil
+}
 
-		return
+// CountFilteredQuotes считает цитаты, подходящие под фильтр списка, без учета страницы
+func (s Service) CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error) {
+	const op = "getcitation.Service.CountFilteredQuotes()"
+
+	count, err := s.Getter.CountFilteredQuotes(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	return count, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+// ExportQuotes построчно передает цитаты по фильтру в fn
+func (s Service) ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error {
+	const op = "getcitation.Service.ExportQuotes()"
 
-	json.NewEncoder(w).Encode(GetRandomQuoteResponse{
-		Status: Status{
-			Code: http.StatusOK,
-		},
-		Quote: quote,
-	})
+	err := s.Getter.ExportQuotes(ctx, filter, fn)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
 }
 
-// DBManipulator описывает интерфейс для операций с БД, связанными с цитатами (создание, удаление)
-type DBManipulator interface {
-	CreateQuote(quote storage.Quote) (int, error)
-	DeleteQuoteByID(id int) error
+// GetStats получает сводные показатели по цитатам
+func (s Service) GetStats(ctx context.Context) (storage.Stats, error) {
+	const op = "getcitation.Service.GetStats()"
+
+	stats, err := s.Getter.GetStats(ctx)
+	if err != nil {
+		return storage.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return stats, nil
 }
 
-// DBGetter описывает интерфейс для получения цитат из БД
-type DBGetter interface {
-	GetRandomQuote() (storage.Quote, error)
-	GetQuotes(authorFilter string) ([]storage.Quote, error)
+// GetTextStats получает показатели текста цитат: слова, символы, самую длинную и короткую цитату
+func (s Service) GetTextStats(ctx context.Context) (storage.TextStats, error) {
+	const op = "getcitation.Service.GetTextStats()"
+
+	stats, err := s.Getter.GetTextStats(ctx)
+	if err != nil {
+		return storage.TextStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return stats, nil
 }
 
-// Service реализует бизнес-логику приложения — создание, удаление и получение цитат
-type Service struct {
-	Log    *slog.Logger
-	Config config.Config
+// GetAuthors получает страницу авторов по алфавиту с числом цитат каждого. Непустой prefix оставляет только
+// авторов, чье имя начинается с него без учета регистра.
+func (s Service) GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error) {
+	const op = "getcitation.Service.GetAuthors()"
 
-	Manipulator DBManipulator
-	Getter      DBGetter
+	authors, err := s.Getter.GetAuthors(ctx, prefix, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return authors, nil
 }
 
-// CreateQuote создает новую цитату через слой хранилища и обрабатывает возможные ошибки дубликатов
-func (s Service) CreateQuote(author string, quote string) (int, error) {
-	const op = "getcitation.Service.CreateQuote()"
+// CreateCategory создает категорию цитат и возвращает ее ID
+func (s Service) CreateCategory(ctx context.Context, name string) (int, error) {
+	const op = "getcitation.Service.CreateCategory()"
 
-	id, err := s.Manipulator.CreateQuote(storage.Quote{
-		Author: author,
-		Quote:  quote,
-	})
+	var v validator
+
+	v.required("name", name)
+	v.maxLength("name", name, maxCategoryLength)
+
+	err := v.err()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.Manipulator.CreateCategory(ctx, name)
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateEntry) {
 			return 0, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
@@ -587,41 +4159,52 @@ func (s Service) CreateQuote(author string, quote string) (int, error) {
 	return id, nil
 }
 
-// DeleteQuoteByID удаляет цитату по ID, возвращает ошибку, если цитата не найдена
-func (s Service) DeleteQuoteByID(id int) error {
-	const op = "getcitation.Service.DeleteQuoteByID()"
+// AssignCategory относит цитату к категории
+func (s Service) AssignCategory(ctx context.Context, quoteID int, categoryID int) error {
+	const op = "getcitation.Service.AssignCategory()"
 
-	err := s.Manipulator.DeleteQuoteByID(id)
+	err := s.Manipulator.AssignCategory(ctx, quoteID, categoryID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
 		}
+		if errors.Is(err, storage.ErrNoCategory) {
+			return fmt.Errorf("%s: %w", op, ErrNoCategory)
+		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
-// GetRandomQuote получает случайную цитату из хранилища
-func (s Service) GetRandomQuote() (storage.Quote, error) {
-	const op = "getcitation.Service.GetRandomQuote()"
+// GetCategories получает все категории по алфавиту с числом цитат в каждой
+func (s Service) GetCategories(ctx context.Context) ([]storage.Category, error) {
+	const op = "getcitation.Service.GetCategories()"
 
-	quote, err := s.Getter.GetRandomQuote()
+	categories, err := s.Getter.GetCategories(ctx)
 	if err != nil {
-		return storage.Quote{}, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return quote, nil
+	return categories, nil
 }
 
-// GetQuotes возвращает список цитат с возможным фильтром по автору
-func (s Service) GetQuotes(authorFilter string) ([]storage.Quote, error) {
-	const op = "getcitation.Service.GetQuotes()"
+// GetAuditLog получает записи журнала аудита, начиная с последних
+func (s Service) GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "getcitation.Service.GetAuditLog()"
 
-	quotes, err := s.Getter.GetQuotes(authorFilter)
+	entries, err := s.Getter.GetAuditLog(ctx, limit, offset)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", op, ErrNoQuotesFound)
-		}
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return quotes, nil
+	return entries, nil
+}
+
+// CountQuotes возвращает количество цитат с возможным фильтром по автору
+func (s Service) CountQuotes(ctx context.Context, authorFilter string) (int, error) {
+	const op = "getcitation.Service.CountQuotes()"
+
+	count, err := s.Getter.CountQuotes(ctx, authorFilter)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
 }
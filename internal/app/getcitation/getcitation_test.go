@@ -0,0 +1,937 @@
+package getcitation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// fakeStore — хранилище цитат в памяти для тестов обработчиков. Методы, которых здесь нет, берутся из
+// встроенного интерфейса и паникуют, если тест до них дошёл.
+type fakeStore struct {
+	QuoteStore
+
+	mu     sync.Mutex
+	quotes []storage.Quote
+	keys   map[string]fakeKey
+
+	// random — какие методы выбора случайной цитаты вызывались, по порядку
+	random []string
+	// recentLimit — limit последнего вызова GetRecentQuotes
+	recentLimit int
+	// onGetQuotes, если задана, вызывается в начале GetQuotes: так тесты задерживают запрос на середине
+	onGetQuotes func()
+
+	// err, если задана, возвращается всеми методами вместо результата
+	err error
+}
+
+// fakeKey — запомненный ключ идемпотентности
+type fakeKey struct {
+	id          int
+	fingerprint string
+}
+
+// add добавляет цитаты в хранилище, выдавая им ID по порядку
+func (s *fakeStore) add(quotes ...storage.Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, quote := range quotes {
+		quote.ID = len(s.quotes) + 1
+		s.quotes = append(s.quotes, quote)
+	}
+}
+
+// find возвращает индекс неудалённой цитаты с тем же автором и текстом или -1
+func (s *fakeStore) find(quote storage.Quote) int {
+	for i, q := range s.quotes {
+		if q.DeletedAt == nil && q.Author == quote.Author && q.Quote == quote.Quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *fakeStore) CreateQuote(ctx context.Context, quote storage.Quote) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.find(quote) >= 0 {
+		return 0, storage.ErrDuplicateEntry
+	}
+
+	quote.ID = len(s.quotes) + 1
+	s.quotes = append(s.quotes, quote)
+	return quote.ID, nil
+}
+
+func (s *fakeStore) CreateQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error) {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return 0, false, s.err
+	}
+	if s.keys == nil {
+		s.keys = map[string]fakeKey{}
+	}
+	if stored, ok := s.keys[key]; ok {
+		s.mu.Unlock()
+		if stored.fingerprint != storage.Fingerprint(quote) {
+			return 0, false, storage.ErrKeyReused
+		}
+		return stored.id, true, nil
+	}
+	s.mu.Unlock()
+
+	id, err := s.CreateQuote(ctx, quote)
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.mu.Lock()
+	s.keys[key] = fakeKey{id: id, fingerprint: storage.Fingerprint(quote)}
+	s.mu.Unlock()
+
+	return id, false, nil
+}
+
+// ImportQuotes добавляет цитаты по одной: дубликат получает ID 0, а при strict прерывает импорт
+// ошибкой *storage.RowError без добавления остальных
+func (s *fakeStore) ImportQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	ids := make([]int, len(quotes))
+	added := len(s.quotes)
+	for i, quote := range quotes {
+		if s.find(quote) >= 0 {
+			if strict {
+				s.quotes = s.quotes[:added]
+				return nil, &storage.RowError{Row: i, Err: storage.ErrDuplicateEntry}
+			}
+			continue
+		}
+
+		quote.ID = len(s.quotes) + 1
+		s.quotes = append(s.quotes, quote)
+		ids[i] = quote.ID
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) UpsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, false, s.err
+	}
+	if i := s.find(quote); i >= 0 {
+		return s.quotes[i].ID, true, nil
+	}
+
+	quote.ID = len(s.quotes) + 1
+	s.quotes = append(s.quotes, quote)
+	return quote.ID, false, nil
+}
+
+func (s *fakeStore) GetRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	return s.randomQuote("GetRandomQuote", excludeAuthor, language)
+}
+
+func (s *fakeStore) GetFairRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	return s.randomQuote("GetFairRandomQuote", excludeAuthor, language)
+}
+
+// randomQuote запоминает вызов method и возвращает первую подходящую неудалённую цитату: тесты
+// обработчиков проверяют выбор метода, а распределение проверяется в тестах хранилищ
+func (s *fakeStore) randomQuote(method string, excludeAuthor string, language string) (storage.Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.random = append(s.random, method)
+
+	if s.err != nil {
+		return storage.Quote{}, s.err
+	}
+	for _, quote := range s.quotes {
+		if quote.DeletedAt == nil && quote.Author != excludeAuthor && (language == "" || quote.Language == language) {
+			return quote, nil
+		}
+	}
+	return storage.Quote{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) GetQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return storage.Quote{}, s.err
+	}
+	if id < 1 || id > len(s.quotes) || s.quotes[id-1].DeletedAt != nil {
+		return storage.Quote{}, sql.ErrNoRows
+	}
+	return s.quotes[id-1], nil
+}
+
+func (s *fakeStore) DeleteQuoteByID(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if id < 1 || id > len(s.quotes) || s.quotes[id-1].DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	now := time.Now().UTC()
+	s.quotes[id-1].DeletedAt = &now
+	return nil
+}
+
+func (s *fakeStore) GetQuoteIDBySlug(ctx context.Context, slug string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+	for _, quote := range s.quotes {
+		if quote.DeletedAt == nil && quote.Slug == slug {
+			return quote.ID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (s *fakeStore) GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var quotes []storage.Quote
+	for _, id := range ids {
+		if id >= 1 && id <= len(s.quotes) && s.quotes[id-1].DeletedAt == nil {
+			quotes = append(quotes, s.quotes[id-1])
+		}
+	}
+	return quotes, nil
+}
+
+func (s *fakeStore) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	if s.onGetQuotes != nil {
+		s.onGetQuotes()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	// Как и настоящее хранилище, прерывает выборку, если клиент ушёл
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var quotes []storage.Quote
+	for _, quote := range s.quotes {
+		if quote.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if len(filter.Authors) > 0 && !slices.Contains(filter.Authors, quote.Author) {
+			continue
+		}
+		if quote.ID <= filter.After {
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	if filter.Offset >= len(quotes) {
+		return nil, nil
+	}
+	quotes = quotes[filter.Offset:]
+	if filter.Limit > 0 && len(quotes) > filter.Limit {
+		quotes = quotes[:filter.Limit]
+	}
+	return quotes, nil
+}
+
+func (s *fakeStore) CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error) {
+	filter.Limit, filter.Offset, filter.After = 0, 0, 0
+
+	quotes, err := s.GetQuotes(ctx, filter)
+	return len(quotes), err
+}
+
+// GetRecentQuotes возвращает до limit неудалённых цитат в обратном порядке добавления
+func (s *fakeStore) GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recentLimit = limit
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	quotes := []storage.Quote{}
+	for i := len(s.quotes) - 1; i >= 0 && len(quotes) < limit; i-- {
+		if s.quotes[i].DeletedAt == nil {
+			quotes = append(quotes, s.quotes[i])
+		}
+	}
+	return quotes, nil
+}
+
+// GetAuthors возвращает авторов неудалённых цитат, чье имя начинается с prefix без учета регистра, по алфавиту
+func (s *fakeStore) GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	counts := map[string]int{}
+	for _, quote := range s.quotes {
+		if quote.DeletedAt == nil && strings.HasPrefix(strings.ToLower(quote.Author), strings.ToLower(prefix)) {
+			counts[quote.Author]++
+		}
+	}
+
+	authors := []storage.AuthorQuotes{}
+	for _, author := range slices.Sorted(maps.Keys(counts)) {
+		authors = append(authors, storage.AuthorQuotes{Author: author, Quotes: counts[author]})
+	}
+
+	authors = authors[min(offset, len(authors)):]
+	return authors[:min(limit, len(authors))], nil
+}
+
+func (s *fakeStore) Ready(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// testConfig возвращает конфиг со значениями по умолчанию из тегов env-default и сервером на свободном
+// порту локального адреса
+func testConfig() config.Config {
+	return config.Config{
+		AppLogMode: "local",
+
+		LogSampleRate: 1,
+
+		ServerHost:              "127.0.0.1",
+		ServerPort:              "0",
+		ServerReadTimeout:       5 * time.Second,
+		ServerWriteTimeout:      10 * time.Second,
+		ServerIdleTimeout:       time.Minute,
+		ServerReadHeaderTimeout: 5 * time.Second,
+		ServerMaxHeaderBytes:    1 << 20,
+		MaxBodyBytes:            1 << 20,
+		MaxImportBytes:          10 << 20,
+
+		TLSMinVersion: "1.2",
+
+		StorageBackend: "sqlite",
+
+		TxRetries:         3,
+		IdempotencyKeyTTL: 24 * time.Hour,
+		QuoteIDType:       config.QuoteIDInt,
+		TrackViews:        true,
+
+		PageSizeDefault: 100,
+		PageSizeMax:     1000,
+		MaxFilterLength: 256,
+
+		WebhookTimeout: 5 * time.Second,
+		WebhookRetries: 3,
+		WebhookBackoff: time.Second,
+
+		StreamKeepAlive: 15 * time.Second,
+		StreamBuffer:    16,
+	}
+}
+
+// testLogger возвращает логгер, который ничего не пишет
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestApp собирает приложение поверх store так же, как main, и возвращает его обработчик запросов
+// целиком, со всеми промежуточными слоями. Сокет приложения сразу закрывается: запросы идут через httptest.
+func newTestApp(t *testing.T, cfg config.Config, store QuoteStore) http.Handler {
+	t.Helper()
+
+	app, err := New(store, cfg, BuildInfo{Version: "test"}, testLogger())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	app.Server.Listener.Close()
+	app.Server.Handlers.Started.Store(true)
+
+	return app.Server.HTTPServer.Handler
+}
+
+// serve выполняет запрос к handler. Тело, если оно есть, отправляется как application/json.
+// headers — пары имя, значение.
+func serve(handler http.Handler, method string, target string, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	r := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// decode разбирает JSON-тело ответа в v
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	err := json.Unmarshal(w.Body.Bytes(), v)
+	if err != nil {
+		t.Fatalf("json.Unmarshal(%q) error = %v", w.Body.String(), err)
+	}
+}
+
+func TestCreateQuoteIdempotencyKey(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	body := `{"author":"Lev Tolstoy","quote":"All happy families are alike"}`
+
+	first := serve(handler, http.MethodPost, "/quotes", body, headerIdempotencyKey, "key-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("first POST status = %d, want %d: %s", first.Code, http.StatusOK, first.Body)
+	}
+	var created CreateQuoteResponse
+	decode(t, first, &created)
+
+	replay := serve(handler, http.MethodPost, "/quotes", body, headerIdempotencyKey, "key-1")
+	if replay.Code != http.StatusOK {
+		t.Fatalf("replayed POST status = %d, want %d: %s", replay.Code, http.StatusOK, replay.Body)
+	}
+	var replayed CreateQuoteResponse
+	decode(t, replay, &replayed)
+	if replayed.ID != created.ID {
+		t.Errorf("replayed POST id = %d, want %d", replayed.ID, created.ID)
+	}
+
+	reused := serve(handler, http.MethodPost, "/quotes", `{"author":"Lev Tolstoy","quote":"War and peace"}`, headerIdempotencyKey, "key-1")
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST with reused key status = %d, want %d: %s", reused.Code, http.StatusUnprocessableEntity, reused.Body)
+	}
+	var response Error
+	decode(t, reused, &response)
+	if response.Message != messageKeyReused {
+		t.Errorf("POST with reused key message = %q, want %q", response.Message, messageKeyReused)
+	}
+}
+
+func TestListenAddressInUse(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer busy.Close()
+
+	_, port, _ := net.SplitHostPort(busy.Addr().String())
+
+	cfg := testConfig()
+	cfg.ServerPort = port
+
+	_, err = listen(cfg)
+	if !errors.Is(err, ErrAddressInUse) {
+		t.Fatalf("listen() error = %v, want %v", err, ErrAddressInUse)
+	}
+
+	_, err = New(&fakeStore{}, cfg, BuildInfo{}, testLogger())
+	if !errors.Is(err, ErrAddressInUse) {
+		t.Fatalf("New() error = %v, want %v", err, ErrAddressInUse)
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	cfg := testConfig()
+	cfg.ServerSocket = filepath.Join(t.TempDir(), "getcitation.sock")
+
+	listener, err := listen(cfg)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+
+	// Пока сокет слушается, второй процесс получает понятную ошибку, а не удаляет чужой файл
+	_, err = listen(cfg)
+	if !errors.Is(err, ErrAddressInUse) {
+		t.Fatalf("second listen() error = %v, want %v", err, ErrAddressInUse)
+	}
+
+	// Файл, оставшийся после аварийного завершения, удаляется, и сокет открывается заново.
+	// Go удаляет файл сокета при Close, поэтому отключаем это, чтобы файл остался.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	listener, err = listen(cfg)
+	if err != nil {
+		t.Fatalf("listen() over stale socket error = %v", err)
+	}
+	listener.Close()
+}
+
+func TestGetQuotesClientCancelled(t *testing.T) {
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Lev Tolstoy", Quote: "All happy families are alike"})
+
+	handler := newTestApp(t, testConfig(), store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/quotes", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	// Ответ ушедшему клиенту не пишется: ни кода, ни тела
+	if w.Body.Len() != 0 {
+		t.Errorf("GET /quotes with cancelled context wrote %q, want nothing", w.Body)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "" {
+		t.Errorf("GET /quotes with cancelled context set Content-Type %q, want none", contentType)
+	}
+}
+
+func TestGetRandomQuoteFair(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantCode   int
+		wantMethod string
+	}{
+		{"", http.StatusOK, "GetRandomQuote"},
+		{"?fair=false", http.StatusOK, "GetRandomQuote"},
+		{"?fair=true", http.StatusOK, "GetFairRandomQuote"},
+		{"?fair=1", http.StatusOK, "GetFairRandomQuote"},
+		{"?fair=maybe", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			store := &fakeStore{}
+			store.add(storage.Quote{Author: "Confucius", Quote: "Life is simple"})
+
+			w := serve(newTestApp(t, testConfig(), store), http.MethodGet, "/quotes/random"+tt.query, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			var want []string
+			if tt.wantMethod != "" {
+				want = []string{tt.wantMethod}
+			}
+			if !slices.Equal(store.random, want) {
+				t.Errorf("storage calls = %v, want %v", store.random, want)
+			}
+		})
+	}
+}
+
+func TestGetQuotesPageLimit(t *testing.T) {
+	store := &fakeStore{}
+	for i := range 5 {
+		store.add(storage.Quote{Author: "Confucius", Quote: fmt.Sprintf("Quote %d", i)})
+	}
+
+	cfg := testConfig()
+	cfg.PageSizeDefault = 2
+	cfg.PageSizeMax = 3
+	handler := newTestApp(t, cfg, store)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantCode   int
+		wantQuotes int
+	}{
+		{"omitted", "", http.StatusOK, 2},
+		{"valid", "?limit=1", http.StatusOK, 1},
+		{"max", "?limit=3", http.StatusOK, 3},
+		{"over max", "?limit=4", http.StatusBadRequest, 0},
+		{"zero", "?limit=0", http.StatusBadRequest, 0},
+		{"negative", "?limit=-1", http.StatusBadRequest, 0},
+		{"not a number", "?limit=all", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, "/quotes"+tt.query, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			if tt.wantCode != http.StatusOK {
+				var response Error
+				decode(t, w, &response)
+
+				want := fmt.Sprintf(messageMalformedPageLimit, cfg.PageSizeMax)
+				if response.Message != want {
+					t.Errorf("message = %q, want %q", response.Message, want)
+				}
+				return
+			}
+
+			var response GetQuotesResponse
+			decode(t, w, &response)
+			if len(response.Quotes) != tt.wantQuotes {
+				t.Errorf("returned %d quotes, want %d", len(response.Quotes), tt.wantQuotes)
+			}
+		})
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"Application/JSON", true},
+		{"", false},
+		{"text/plain", false},
+		{"application/x-www-form-urlencoded", false},
+		{"application/jsonp", false},
+		{"application/json; charset", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+			r.Header.Set("Content-Type", tt.contentType)
+
+			if got := isJSON(r); got != tt.want {
+				t.Errorf("isJSON(%q) = %v, want %v", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEndpointsRequireJSON(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPost, "/quotes", `{"author":"Confucius","quote":"Life is simple"}`},
+		{http.MethodPost, "/categories", `{"name":"wisdom"}`},
+		{http.MethodPost, "/quotes/1/categories", `{"category_id":1}`},
+		{http.MethodPatch, "/authors", `{"from":"Confucius","to":"Kong Qiu"}`},
+		{http.MethodPost, "/admin/readonly", `{"enabled":true}`},
+	}
+
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	for _, tt := range tests {
+		for _, contentType := range []string{"text/plain", "application/x-www-form-urlencoded", ""} {
+			t.Run(tt.method+" "+tt.target+" "+contentType, func(t *testing.T) {
+				r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+				if contentType != "" {
+					r.Header.Set("Content-Type", contentType)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != http.StatusUnsupportedMediaType {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnsupportedMediaType, w.Body)
+				}
+
+				var response Error
+				decode(t, w, &response)
+				if response.Status.Message != errUnsupportedMedia || response.Message != messageJSONRequired {
+					t.Errorf("response = %+v, want %q with %q", response, errUnsupportedMedia, messageJSONRequired)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateQuoteAcceptsJSONCharset(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	w := serve(handler, http.MethodPost, "/quotes", `{"author":"Confucius","quote":"Life is simple"}`, "Content-Type", "application/json; charset=utf-8")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxBodyBytes = 64
+	cfg.MaxImportBytes = 128
+
+	handler := newTestApp(t, cfg, &fakeStore{})
+
+	large := `{"author":"Confucius","quote":"` + strings.Repeat("a", 256) + `"}`
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		limit  int64
+	}{
+		{http.MethodPost, "/quotes", large, cfg.MaxBodyBytes},
+		{http.MethodPost, "/categories", `{"name":"` + strings.Repeat("a", 256) + `"}`, cfg.MaxBodyBytes},
+		{http.MethodPatch, "/authors", `{"from":"Confucius","to":"` + strings.Repeat("a", 256) + `"}`, cfg.MaxBodyBytes},
+		{http.MethodPost, "/quotes/import", `[` + strings.Repeat(`{"author":"Confucius","quote":"Life is simple"},`, 10) + `{}]`, cfg.MaxImportBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			w := serve(handler, tt.method, tt.target, tt.body)
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if want := fmt.Sprintf(messageBodyTooLarge, tt.limit); response.Status.Message != errBodyTooLarge || response.Message != want {
+				t.Errorf("response = %+v, want %q with %q", response, errBodyTooLarge, want)
+			}
+		})
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		w := serve(handler, http.MethodPost, "/quotes", `{"author":"Confucius","quote":"Life"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+	})
+}
+
+func TestGetRecentQuotes(t *testing.T) {
+	store := &fakeStore{}
+	for i := range 3 {
+		store.add(storage.Quote{Author: "Confucius", Quote: fmt.Sprintf("Quote %d", i)})
+	}
+
+	handler := newTestApp(t, testConfig(), store)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantLimit int
+		wantIDs   []int
+	}{
+		{"default limit", "", http.StatusOK, defaultRecentLimit, []int{3, 2, 1}},
+		{"limit", "?limit=2", http.StatusOK, 2, []int{3, 2}},
+		{"clamped", "?limit=100000", http.StatusOK, maxRecentLimit, []int{3, 2, 1}},
+		{"zero", "?limit=0", http.StatusBadRequest, 0, nil},
+		{"not a number", "?limit=ten", http.StatusBadRequest, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.recentLimit = 0
+
+			w := serve(handler, http.MethodGet, "/quotes/recent"+tt.query, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if store.recentLimit != tt.wantLimit {
+				t.Errorf("storage limit = %d, want %d", store.recentLimit, tt.wantLimit)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var response GetQuotesResponse
+			decode(t, w, &response)
+
+			var ids []int
+			for _, quote := range response.Quotes {
+				ids = append(ids, quote.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("returned IDs %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestGetRecentQuotesEmpty(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	w := serve(handler, http.MethodGet, "/quotes/recent", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"quotes":[]`) {
+		t.Errorf("body = %s, want an empty quotes array", w.Body)
+	}
+}
+
+func TestGetQuotesCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		quotes    int
+		wantPages [][]int
+	}{
+		{"partial last page", 5, [][]int{{1, 2}, {3, 4}, {5}}},
+		// Последняя полная страница не должна отдавать next_cursor на пустую страницу
+		{"full last page", 4, [][]int{{1, 2}, {3, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			for i := range tt.quotes {
+				store.add(storage.Quote{Author: "Confucius", Quote: fmt.Sprintf("Quote %d", i)})
+			}
+
+			handler := newTestApp(t, testConfig(), store)
+
+			var pages [][]int
+			for after := 0; len(pages) <= len(tt.wantPages); {
+				w := serve(handler, http.MethodGet, fmt.Sprintf("/quotes?limit=2&after=%d", after), "")
+				if w.Code != http.StatusOK {
+					t.Fatalf("after=%d status = %d, want %d: %s", after, w.Code, http.StatusOK, w.Body)
+				}
+
+				var response GetQuotesResponse
+				decode(t, w, &response)
+
+				var ids []int
+				for _, quote := range response.Quotes {
+					ids = append(ids, quote.ID)
+				}
+				pages = append(pages, ids)
+
+				if response.NextCursor == 0 {
+					break
+				}
+				if response.NextCursor != ids[len(ids)-1] {
+					t.Errorf("next_cursor = %d, want the last ID %d", response.NextCursor, ids[len(ids)-1])
+				}
+				after = response.NextCursor
+			}
+
+			if !slices.EqualFunc(pages, tt.wantPages, slices.Equal) {
+				t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestGetQuotesCursorRejected(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	tests := []struct {
+		query       string
+		wantMessage string
+	}{
+		{"?after=-1", messageMalformedAfter},
+		{"?after=first", messageMalformedAfter},
+		{"?after=2&offset=4", messageCursorConflict},
+		{"?after=2&sort=popular", messageCursorConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, "/quotes"+tt.query, "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if response.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", response.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestGetAuthorsPrefix(t *testing.T) {
+	store := &fakeStore{}
+	store.add(
+		storage.Quote{Author: "Seneca", Quote: "Luck is what happens"},
+		storage.Quote{Author: "Seneca", Quote: "While we teach, we learn"},
+		storage.Quote{Author: "sentinel", Quote: "Watch"},
+		storage.Quote{Author: "Confucius", Quote: "Life is simple"},
+	)
+
+	handler := newTestApp(t, testConfig(), store)
+
+	tests := []struct {
+		query string
+		want  []storage.AuthorQuotes
+	}{
+		{"?prefix=SEN", []storage.AuthorQuotes{{Author: "Seneca", Quotes: 2}, {Author: "sentinel", Quotes: 1}}},
+		{"?prefix=seneca", []storage.AuthorQuotes{{Author: "Seneca", Quotes: 2}}},
+		{"?prefix=Sen&limit=1", []storage.AuthorQuotes{{Author: "Seneca", Quotes: 2}}},
+		{"?prefix=Plato", []storage.AuthorQuotes{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, "/authors"+tt.query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var response GetAuthorsResponse
+			decode(t, w, &response)
+			if response.Authors == nil || !slices.Equal(response.Authors, tt.want) {
+				t.Errorf("authors = %v, want %v", response.Authors, tt.want)
+			}
+		})
+	}
+
+	t.Run("empty is an array", func(t *testing.T) {
+		w := serve(handler, http.MethodGet, "/authors?prefix=Plato", "")
+		if !strings.Contains(w.Body.String(), `"authors":[]`) {
+			t.Errorf("body = %s, want an empty authors array", w.Body)
+		}
+	})
+}
@@ -0,0 +1,487 @@
+package getcitation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Итоги импорта отдельной строки
+const (
+	ImportInserted  string = "inserted"
+	ImportDuplicate string = "duplicate"
+	ImportError     string = "error"
+)
+
+// Параметры импорта
+const (
+	maxImportRows  int    = 1000
+	importContinue string = "continue"
+	importStop     string = "stop"
+	importLenient  string = "lenient"
+	importStrict   string = "strict"
+	mediaTypeCSV   string = "text/csv"
+	mediaTypeJSON  string = "application/json"
+)
+
+// Сообщения ответов импорта
+const (
+	messageImportMedia      string = "Content-Type must be application/json or text/csv"
+	messageMalformedImport  string = "Request body must be a JSON array of quotes or CSV rows author,quote[,language[,source]] with an optional header"
+	messageImportEmpty      string = "Import must contain at least one row"
+	messageImportTooLarge   string = "Import must contain at most %d rows"
+	messageMalformedOnError string = "on_error parameter must be continue or stop"
+	messageMalformedMode    string = "mode parameter must be lenient or strict"
+	messageImportStrictStop string = "on_error parameter cannot be combined with mode=strict"
+	messageImportRejected   string = "Import rejected, no quotes were added: the row is invalid or already exists"
+)
+
+// reasonCSVFields — причина ошибки строки CSV с неверным числом полей
+const reasonCSVFields string = "expected author,quote[,language[,source]]"
+
+// Названия колонок заголовка CSV
+const (
+	csvAuthor   string = "author"
+	csvQuote    string = "quote"
+	csvLanguage string = "language"
+	csvSource   string = "source"
+)
+
+// errCSVHeader — заголовок CSV с повторяющейся колонкой или без author и quote
+var errCSVHeader = errors.New("CSV header must name author and quote once each, language and source are optional")
+
+// utf8BOM — метка порядка байтов, с которой Excel начинает файлы CSV в UTF-8
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvColumns — порядок колонок CSV. Первые required колонок обязательны, reason — причина ошибки строки
+// с неверным числом полей.
+type csvColumns struct {
+	names    []string
+	required int
+	reason   string
+}
+
+// defaultCSVColumns — порядок колонок CSV без заголовка
+var defaultCSVColumns = csvColumns{
+	names:    []string{csvAuthor, csvQuote, csvLanguage, csvSource},
+	required: 2,
+	reason:   reasonCSVFields,
+}
+
+// ImportRow — строка импорта. Line — номер строки в CSV или позиция (с 1) в массиве JSON.
+// Непустой Reason означает, что строку не удалось разобрать.
+type ImportRow struct {
+	Line     int
+	Author   string
+	Quote    string
+	Language string
+	Source   string
+	Reason   string
+}
+
+// ImportOptions — режим импорта. StopOnError останавливает импорт на первой строке с ошибкой,
+// сохраняя добавленное до нее (on_error=stop). Strict отменяет импорт целиком, если хоть одна строка
+// с ошибкой или дубликат (mode=strict).
+type ImportOptions struct {
+	StopOnError bool
+	Strict      bool
+}
+
+// ImportResult — итог импорта строки: inserted с ID новой цитаты, duplicate или error с причиной
+type ImportResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	ID     int    `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportQuotesResponse описывает ответ на импорт: сводку и итог каждой обработанной строки.
+// Stopped означает, что импорт остановлен на первой ошибке (on_error=stop) и следующие строки не обработаны.
+// Ответ на отклоненный строгий импорт (mode=strict) содержит только строку, из-за которой он отменен.
+type ImportQuotesResponse struct {
+	Status     Status         `json:"status"`
+	Message    string         `json:"message,omitempty"`
+	Inserted   int            `json:"inserted"`
+	Duplicates int            `json:"duplicates"`
+	Errors     int            `json:"errors"`
+	Stopped    bool           `json:"stopped"`
+	Rows       []ImportResult `json:"rows"`
+}
+
+// parseImportJSON разбирает массив цитат в формате тела POST /quotes
+func parseImportJSON(body io.Reader) ([]ImportRow, error) {
+	var quotes []CreateQuoteRequest
+
+	err := json.NewDecoder(body).Decode(&quotes)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]ImportRow, 0, len(quotes))
+	for i, quote := range quotes {
+		rows = append(rows, ImportRow{
+			Line:     i + 1,
+			Author:   quote.Author,
+			Quote:    quote.Quote,
+			Language: quote.Language,
+			Source:   quote.Source,
+		})
+	}
+	return rows, nil
+}
+
+// parseImportCSV разбирает CSV: author,quote и необязательные language и source. Ведущий UTF-8 BOM (его
+// добавляет Excel) пропускается. Если первая строка состоит только из названий колонок author, quote,
+// language и source, она считается заголовком и задает порядок колонок. Строка с другим числом полей не прерывает разбор,
+// а получает причину ошибки.
+func parseImportCSV(body io.Reader) ([]ImportRow, error) {
+	buffered := bufio.NewReader(body)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+
+	columns := defaultCSVColumns
+
+	var rows []ImportRow
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if first {
+			header, ok, err := parseCSVHeader(record)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				columns = header
+				continue
+			}
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{Line: line}
+
+		if len(record) < columns.required || len(record) > len(columns.names) {
+			row.Reason = columns.reason
+			rows = append(rows, row)
+			continue
+		}
+
+		for i, value := range record {
+			switch columns.names[i] {
+			case csvAuthor:
+				row.Author = value
+			case csvQuote:
+				row.Quote = value
+			case csvLanguage:
+				row.Language = value
+			case csvSource:
+				row.Source = value
+			}
+		}
+
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseCSVHeader сообщает, является ли строка заголовком, и возвращает заданный им порядок колонок.
+// Заголовком считается строка, все поля которой — названия колонок (без учета регистра и пробелов по краям).
+// Цитата "author,quote" от автора author тоже попадет под это правило, но в реальных выгрузках это заголовок.
+func parseCSVHeader(record []string) (csvColumns, bool, error) {
+	names := make([]string, 0, len(record))
+	seen := make(map[string]bool, len(record))
+
+	for _, field := range record {
+		name := strings.ToLower(strings.TrimSpace(field))
+		switch name {
+		case csvAuthor, csvQuote, csvLanguage, csvSource:
+		default:
+			return csvColumns{}, false, nil
+		}
+		if seen[name] {
+			return csvColumns{}, false, errCSVHeader
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if !seen[csvAuthor] || !seen[csvQuote] {
+		return csvColumns{}, false, errCSVHeader
+	}
+
+	// Необязательные колонки language и source можно опустить в строке, только если они стоят в конце
+	// заголовка, иначе строки без них нельзя отличить от строк с пропущенной обязательной колонкой
+	required := len(names)
+	for required > 0 && (names[required-1] == csvLanguage || names[required-1] == csvSource) {
+		required--
+	}
+
+	return csvColumns{
+		names:    names,
+		required: required,
+		reason:   "expected " + strings.Join(names, ","),
+	}, true, nil
+}
+
+// ImportQuotes обрабатывает HTTP POST запрос на импорт цитат из массива JSON или CSV. Каждая строка
+// проверяется как при добавлении цитаты; дубликаты пропускаются, не прерывая импорт. С on_error=stop
+// импорт останавливается на первой строке с ошибкой, уже добавленные цитаты остаются. С mode=strict
+// импорт выполняется по принципу «все или ничего»: первая строка с ошибкой или дубликат отменяют его
+// целиком, и в ответе 422 возвращается эта строка.
+func (h Handlers) ImportQuotes(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.ImportQuotes()"
+
+	if r.Method != http.MethodPost {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	var options ImportOptions
+
+	switch r.URL.Query().Get("on_error") {
+	case "", importContinue:
+	case importStop:
+		options.StopOnError = true
+	default:
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("on_error", r.URL.Query().Get("on_error")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedOnError,
+		})
+
+		return
+	}
+
+	switch r.URL.Query().Get("mode") {
+	case "", importLenient:
+	case importStrict:
+		options.Strict = true
+	default:
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("mode", r.URL.Query().Get("mode")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageMalformedMode,
+		})
+
+		return
+	}
+
+	if options.Strict && r.URL.Query().Has("on_error") {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("on_error", r.URL.Query().Get("on_error")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: messageImportStrictStop,
+		})
+
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var parse func(io.Reader) ([]ImportRow, error)
+
+	switch mediaType {
+	case mediaTypeJSON:
+		parse = parseImportJSON
+	case mediaTypeCSV:
+		parse = parseImportCSV
+	default:
+		h.Log.Error(
+			errUnsupportedMedia,
+			slog.String("op", op),
+			slog.String("content_type", r.Header.Get("Content-Type")),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+			Status: Status{
+				Code:    http.StatusUnsupportedMediaType,
+				Message: errUnsupportedMedia,
+			},
+			Message: messageImportMedia,
+		})
+
+		return
+	}
+
+	limitBody(w, r, h.Config.MaxImportBytes)
+
+	rows, err := parse(r.Body)
+	if h.rejectLargeBody(w, r, op, err) {
+		return
+	}
+	if err != nil {
+		message := messageMalformedImport
+		if errors.Is(err, errCSVHeader) {
+			message = errCSVHeader.Error()
+		}
+
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: message,
+		})
+
+		return
+	}
+	defer r.Body.Close()
+
+	if len(rows) == 0 || len(rows) > maxImportRows {
+		message := messageImportEmpty
+		if len(rows) > maxImportRows {
+			message = fmt.Sprintf(messageImportTooLarge, maxImportRows)
+		}
+
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Int("rows", len(rows)),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: message,
+		})
+
+		return
+	}
+
+	results, err := h.Manipulator.ImportQuotes(r.Context(), rows, options)
+	if errors.Is(err, ErrImportRejected) {
+		h.Log.Error(
+			errUnprocessable,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		response := ImportQuotesResponse{
+			Status: Status{
+				Code:    http.StatusUnprocessableEntity,
+				Message: errUnprocessable,
+			},
+			Message: messageImportRejected,
+			Stopped: true,
+			Rows:    results,
+		}
+		response.count()
+
+		h.writeJSON(w, http.StatusUnprocessableEntity, response)
+		return
+	}
+	if err != nil {
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, code, Error{
+			Status: Status{
+				Code:    code,
+				Message: message,
+			},
+			Debug: h.errorDebug(op, err),
+		})
+
+		return
+	}
+
+	response := ImportQuotesResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Stopped: len(results) < len(rows),
+		Rows:    results,
+	}
+	response.count()
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// count заполняет сводку ответа по итогам строк
+func (r *ImportQuotesResponse) count() {
+	for _, result := range r.Rows {
+		switch result.Status {
+		case ImportInserted:
+			r.Inserted++
+		case ImportDuplicate:
+			r.Duplicates++
+		case ImportError:
+			r.Errors++
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package getcitation
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	const bom = "\ufeff"
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []ImportRow
+		wantErr error
+	}{
+		{
+			name: "header",
+			body: "author,quote\nConfucius,Life is simple\n",
+			want: []ImportRow{{Line: 2, Author: "Confucius", Quote: "Life is simple"}},
+		},
+		{
+			name: "BOM and header",
+			body: bom + "author,quote\nConfucius,Life is simple\n",
+			want: []ImportRow{{Line: 2, Author: "Confucius", Quote: "Life is simple"}},
+		},
+		{
+			name: "BOM without header",
+			body: bom + "Confucius,Life is simple\nLev Tolstoy,All happy families are alike\n",
+			want: []ImportRow{
+				{Line: 1, Author: "Confucius", Quote: "Life is simple"},
+				{Line: 2, Author: "Lev Tolstoy", Quote: "All happy families are alike"},
+			},
+		},
+		{
+			name: "no header",
+			body: "Confucius,Life is simple,en,Analects\n",
+			want: []ImportRow{{Line: 1, Author: "Confucius", Quote: "Life is simple", Language: "en", Source: "Analects"}},
+		},
+		{
+			name: "header with spaces and capitals",
+			body: bom + " Author , QUOTE \nConfucius,Life is simple\n",
+			want: []ImportRow{{Line: 2, Author: "Confucius", Quote: "Life is simple"}},
+		},
+		{
+			name: "reordered header",
+			body: "quote,language,author\nLife is simple,en,Confucius\n",
+			want: []ImportRow{{Line: 2, Author: "Confucius", Quote: "Life is simple", Language: "en"}},
+		},
+		{
+			name: "quoted comma and newline",
+			body: "author,quote\nConfucius,\"Life is simple, but\nwe insist on making it complicated\"\nLev Tolstoy,Everyone thinks\n",
+			want: []ImportRow{
+				{Line: 2, Author: "Confucius", Quote: "Life is simple, but\nwe insist on making it complicated"},
+				{Line: 4, Author: "Lev Tolstoy", Quote: "Everyone thinks"},
+			},
+		},
+		{
+			name: "wrong field count",
+			body: "Confucius\nLev Tolstoy,Everyone thinks\n",
+			want: []ImportRow{
+				{Line: 1, Reason: reasonCSVFields},
+				{Line: 2, Author: "Lev Tolstoy", Quote: "Everyone thinks"},
+			},
+		},
+		{
+			name:    "duplicate header column",
+			body:    "author,quote,author\nConfucius,Life is simple,Confucius\n",
+			wantErr: errCSVHeader,
+		},
+		{
+			name:    "header without quote",
+			body:    "author,language\nConfucius,en\n",
+			wantErr: errCSVHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseImportCSV(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseImportCSV() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(rows, tt.want) {
+				t.Errorf("parseImportCSV() = %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportQuotesCSVWithBOM(t *testing.T) {
+	store := &fakeStore{}
+	handler := newTestApp(t, testConfig(), store)
+
+	body := "\ufeffauthor,quote\nConfucius,\"Life is simple, really\"\n"
+
+	w := serve(handler, http.MethodPost, "/quotes/import", body, "Content-Type", "text/csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var response ImportQuotesResponse
+	decode(t, w, &response)
+	if response.Inserted != 1 || response.Errors != 0 {
+		t.Errorf("response = %+v, want one inserted row", response)
+	}
+
+	want := []storage.Quote{{ID: 1, Author: "Confucius", Quote: "Life is simple, really"}}
+	if !slices.Equal(store.quotes, want) {
+		t.Errorf("stored quotes = %+v, want %+v", store.quotes, want)
+	}
+}
+
+func TestImportQuotesModes(t *testing.T) {
+	// Строки 2–5: новая цитата, цитата из хранилища, строка без автора, новая цитата
+	const mixed = "author,quote\nConfucius,Life is simple\nLev Tolstoy,All happy families are alike\n,Nobody said this\nSeneca,Luck is what happens\n"
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantCode   int
+		wantStatus []string
+		wantLines  []int
+		wantAdded  int
+	}{
+		{
+			name:       "lenient",
+			query:      "",
+			body:       mixed,
+			wantCode:   http.StatusOK,
+			wantStatus: []string{ImportInserted, ImportDuplicate, ImportError, ImportInserted},
+			wantLines:  []int{2, 3, 4, 5},
+			wantAdded:  2,
+		},
+		{
+			name:       "strict rejects the invalid row",
+			query:      "?mode=strict",
+			body:       mixed,
+			wantCode:   http.StatusUnprocessableEntity,
+			wantStatus: []string{ImportError},
+			wantLines:  []int{4},
+		},
+		{
+			name:       "strict rejects a stored duplicate",
+			query:      "?mode=strict",
+			body:       "author,quote\nConfucius,Life is simple\nLev Tolstoy,All happy families are alike\n",
+			wantCode:   http.StatusUnprocessableEntity,
+			wantStatus: []string{ImportDuplicate},
+			wantLines:  []int{3},
+		},
+		{
+			name:       "strict rejects a repeat within the file",
+			query:      "?mode=strict",
+			body:       "author,quote\nConfucius,Life is simple\nConfucius,Life is simple\n",
+			wantCode:   http.StatusUnprocessableEntity,
+			wantStatus: []string{ImportDuplicate},
+			wantLines:  []int{3},
+		},
+		{
+			name:       "strict without problems",
+			query:      "?mode=strict",
+			body:       "author,quote\nConfucius,Life is simple\nSeneca,Luck is what happens\n",
+			wantCode:   http.StatusOK,
+			wantStatus: []string{ImportInserted, ImportInserted},
+			wantLines:  []int{2, 3},
+			wantAdded:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			store.add(storage.Quote{Author: "Lev Tolstoy", Quote: "All happy families are alike"})
+
+			handler := newTestApp(t, testConfig(), store)
+
+			w := serve(handler, http.MethodPost, "/quotes/import"+tt.query, tt.body, "Content-Type", "text/csv")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			var response ImportQuotesResponse
+			decode(t, w, &response)
+
+			var statuses []string
+			var lines []int
+			for _, row := range response.Rows {
+				statuses = append(statuses, row.Status)
+				lines = append(lines, row.Line)
+			}
+			if !slices.Equal(statuses, tt.wantStatus) || !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("rows = %+v, want statuses %v on lines %v", response.Rows, tt.wantStatus, tt.wantLines)
+			}
+
+			if added := len(store.quotes) - 1; added != tt.wantAdded {
+				t.Errorf("added %d quotes, want %d", added, tt.wantAdded)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+
+	"golang.org/x/text/language"
+)
+
+// canonicalLanguage приводит тег языка к канонической форме BCP 47 ("EN-us" -> "en-US"), пустая строка остается пустой.
+// Тег должен быть заранее проверен validateQuote
+func canonicalLanguage(lang string) string {
+	if lang == "" {
+		return ""
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang
+	}
+	return tag.String()
+}
+
+// matchLanguage выбирает из языков, на которых есть цитаты, лучше всего подходящий под заголовок Accept-Language.
+// Возвращает пустую строку, если заголовок некорректен или ни один язык не подходит с достаточной уверенностью
+func matchLanguage(acceptLanguage string, available []string) string {
+	if acceptLanguage == "" || len(available) == 0 {
+		return ""
+	}
+
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return ""
+	}
+
+	tags := make([]language.Tag, 0, len(available))
+	for _, lang := range available {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+	if confidence < language.High {
+		return ""
+	}
+	return tags[index].String()
+}
+
+// preferredLanguage возвращает язык цитат, подходящий под заголовок Accept-Language запроса, или пустую строку.
+// Ошибка получения списка языков не мешает выдать цитату: выбор просто идет без учета языка
+func (h Handlers) preferredLanguage(r *http.Request) string {
+	const op = "getcitation.Transport.preferredLanguage()"
+
+	acceptLanguage := r.Header.Get("Accept-Language")
+	if acceptLanguage == "" {
+		return ""
+	}
+
+	available, err := h.Getter.QuoteLanguages(r.Context())
+	if err != nil {
+		h.Log.Warn(
+			"не удалось получить языки цитат",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return ""
+	}
+	return matchLanguage(acceptLanguage, available)
+}
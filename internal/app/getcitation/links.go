@@ -0,0 +1,61 @@
+package getcitation
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// PageLinks — ссылки на соседние страницы списка, чтобы клиент мог листать его, не вычисляя offset.
+// Next нет на последней странице, Prev — на первой.
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// pageLinks строит ссылки страниц по URL запроса и общему числу записей. Остальные параметры
+// запроса (author, sort и т.п.) сохраняются, меняются только limit и offset. Ссылки относительные
+// (путь и параметры), поэтому остаются верными за прокси и с ROUTE_PREFIX.
+func pageLinks(u *url.URL, limit int, offset int, total int) PageLinks {
+	page := func(offset int) string {
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+
+		return (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
+	}
+
+	links := PageLinks{
+		Self:  page(offset),
+		First: page(0),
+	}
+	if offset > 0 {
+		links.Prev = page(max(offset-limit, 0))
+	}
+	if offset+limit < total {
+		links.Next = page(offset + limit)
+	}
+	return links
+}
+
+// cursorLinks строит ссылки страниц выборки по курсору after. Prev нет: назад по курсору не листают,
+// Next есть, только если next больше нуля (ID последней цитаты страницы, после которой есть еще цитаты).
+func cursorLinks(u *url.URL, limit int, after int, next int) PageLinks {
+	page := func(after int) string {
+		query := u.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("after", strconv.Itoa(after))
+
+		return (&url.URL{Path: u.Path, RawQuery: query.Encode()}).String()
+	}
+
+	links := PageLinks{
+		Self:  page(after),
+		First: page(0),
+	}
+	if next > 0 {
+		links.Next = page(next)
+	}
+	return links
+}
@@ -0,0 +1,100 @@
+package getcitation
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestPageLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		want   PageLinks
+	}{
+		{"first", 0, PageLinks{
+			Self:  "/quotes?author=Confucius&limit=2&offset=0",
+			First: "/quotes?author=Confucius&limit=2&offset=0",
+			Next:  "/quotes?author=Confucius&limit=2&offset=2",
+		}},
+		{"middle", 2, PageLinks{
+			Self:  "/quotes?author=Confucius&limit=2&offset=2",
+			First: "/quotes?author=Confucius&limit=2&offset=0",
+			Prev:  "/quotes?author=Confucius&limit=2&offset=0",
+			Next:  "/quotes?author=Confucius&limit=2&offset=4",
+		}},
+		{"last", 4, PageLinks{
+			Self:  "/quotes?author=Confucius&limit=2&offset=4",
+			First: "/quotes?author=Confucius&limit=2&offset=0",
+			Prev:  "/quotes?author=Confucius&limit=2&offset=2",
+		}},
+		{"offset between pages", 1, PageLinks{
+			Self:  "/quotes?author=Confucius&limit=2&offset=1",
+			First: "/quotes?author=Confucius&limit=2&offset=0",
+			Prev:  "/quotes?author=Confucius&limit=2&offset=0",
+			Next:  "/quotes?author=Confucius&limit=2&offset=3",
+		}},
+	}
+
+	u, err := url.Parse("/quotes?author=Confucius&limit=2&offset=7")
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageLinks(u, 2, tt.offset, 5); got != tt.want {
+				t.Errorf("pageLinks(offset=%d) = %+v, want %+v", tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetQuotesFollowsNextLinks(t *testing.T) {
+	store := &fakeStore{}
+	for i := range 5 {
+		store.add(storage.Quote{Author: "Confucius", Quote: fmt.Sprintf("Quote %d", i)})
+		store.add(storage.Quote{Author: "Lev Tolstoy", Quote: fmt.Sprintf("Quote %d", i)})
+	}
+
+	handler := newTestApp(t, testConfig(), store)
+
+	var pages, quotes int
+	for target := "/quotes?author=Confucius&limit=2"; target != ""; pages++ {
+		if pages > 5 {
+			t.Fatalf("next links do not end, last = %q", target)
+		}
+
+		w := serve(handler, http.MethodGet, target, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d: %s", target, w.Code, http.StatusOK, w.Body)
+		}
+
+		var response GetQuotesResponse
+		decode(t, w, &response)
+		if response.Total == nil || *response.Total != 5 {
+			t.Fatalf("GET %s total = %v, want 5", target, response.Total)
+		}
+		if response.Links == nil {
+			t.Fatalf("GET %s has no links", target)
+		}
+		if (pages == 0) != (response.Links.Prev == "") {
+			t.Errorf("GET %s prev = %q on page %d", target, response.Links.Prev, pages)
+		}
+
+		for _, quote := range response.Quotes {
+			if quote.Author != "Confucius" {
+				t.Errorf("GET %s returned a quote by %q, want the author filter kept", target, quote.Author)
+			}
+		}
+		quotes += len(response.Quotes)
+		target = response.Links.Next
+	}
+
+	if pages != 3 || quotes != 5 {
+		t.Errorf("followed %d pages with %d quotes, want 3 pages with 5 quotes", pages, quotes)
+	}
+}
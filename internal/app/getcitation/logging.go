@@ -0,0 +1,62 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"getcitation/internal/utils"
+)
+
+// statusRecorder запоминает код ответа для журнала запросов. Unwrap нужен http.ResponseController,
+// чтобы потоковые обработчики (SSE, выгрузка) по-прежнему могли сбрасывать буфер и менять дедлайны.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// LogRequests пишет в журнал каждый запрос: метод, путь, код ответа, длительность и IP клиента.
+// IP определяется через utils.ClientIP с учетом TRUSTED_PROXIES.
+func (h Handlers) LogRequests(next http.Handler) http.Handler {
+	const op = "getcitation.Transport.LogRequests()"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		h.Log.Info(
+			"запрос",
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", utils.ClientIP(r, h.Config.TrustedProxies)),
+		)
+	})
+}
@@ -0,0 +1,48 @@
+package getcitation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"getcitation/internal/storage"
+)
+
+// includeMeta — значение параметра include, добавляющее к цитате производные поля
+const includeMeta string = "meta"
+
+// QuoteMeta — производные поля цитаты. Отдаются отдельным объектом, чтобы формат самой цитаты не менялся.
+type QuoteMeta struct {
+	Length int `json:"length"`
+	Words  int `json:"words"`
+}
+
+// NewQuoteMeta вычисляет производные поля цитаты: длину текста в символах и число слов
+func NewQuoteMeta(quote storage.Quote) QuoteMeta {
+	return QuoteMeta{
+		Length: utf8.RuneCountInString(quote.Quote),
+		Words:  len(strings.Fields(quote.Quote)),
+	}
+}
+
+// parseIncludeMeta разбирает параметр include (значения через запятую) и сообщает, запрошено ли meta.
+// Неизвестные значения считаются ошибкой, чтобы опечатка не оставалась незамеченной.
+func parseIncludeMeta(query url.Values) (bool, error) {
+	const op = "getcitation.parseIncludeMeta()"
+
+	meta := false
+
+	for _, raw := range query["include"] {
+		for _, value := range strings.Split(raw, ",") {
+			switch strings.TrimSpace(value) {
+			case includeMeta:
+				meta = true
+			case "":
+			default:
+				return false, fmt.Errorf("%s: unknown include value %q", op, value)
+			}
+		}
+	}
+	return meta, nil
+}
@@ -0,0 +1,174 @@
+package getcitation
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Сообщения перечитывания списка запрещенных слов
+const (
+	messageFiltersNotConfigured string = "BANNED_WORDS_PATH is not set, there is no banned words file to reload"
+	messageFiltersReloadFailed  string = "Failed to read the banned words file, the previous list is kept"
+)
+
+// ReloadFiltersResponse описывает ответ на перечитывание списка запрещенных слов: сколько слов в новом списке
+type ReloadFiltersResponse struct {
+	Status Status `json:"status"`
+	Words  int    `json:"words"`
+}
+
+// BannedWords — список запрещенных слов, с которыми нельзя добавить цитату. Нулевое значение
+// ничего не запрещает.
+type BannedWords struct {
+	words map[string]struct{}
+}
+
+// LoadBannedWords читает список запрещенных слов из файла: по слову на строку, пустые строки и
+// строки, начинающиеся с #, пропускаются. Пустой путь дает пустой список.
+func LoadBannedWords(path string) (BannedWords, error) {
+	const op = "getcitation.LoadBannedWords()"
+
+	if path == "" {
+		return BannedWords{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return BannedWords{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer file.Close()
+
+	words := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words[strings.ToLower(word)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return BannedWords{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return BannedWords{words: words}, nil
+}
+
+// Len возвращает количество слов в списке
+func (b BannedWords) Len() int {
+	return len(b.words)
+}
+
+// Find возвращает первое запрещенное слово в тексте или пустую строку. Текст сравнивается по
+// целым словам без учета регистра, поэтому запрещенное слово внутри другого (ass в class) не находится.
+func (b BannedWords) Find(text string) string {
+	if len(b.words) == 0 {
+		return ""
+	}
+
+	for _, word := range strings.FieldsFunc(text, isWordSeparator) {
+		if _, ok := b.words[strings.ToLower(word)]; ok {
+			return word
+		}
+	}
+	return ""
+}
+
+// isWordSeparator сообщает, разделяет ли символ слова: все, кроме букв, цифр и диакритических знаков
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+}
+
+// AdminReloadFilters обрабатывает HTTP POST запрос на перечитывание файла запрещенных слов (BANNED_WORDS_PATH)
+// без перезапуска. Новый список подменяет старый целиком, поэтому проверки, которые уже идут, заканчиваются
+// со старым списком. Если файл не прочитался, остается старый список. Как и другие изменения, при включенной
+// аутентификации доступен только роли admin.
+func (h Handlers) AdminReloadFilters(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.AdminReloadFilters()"
+
+	if r.Method != http.MethodPost {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+
+	if h.Config.BannedWordsPath == "" {
+		h.Log.Error(
+			errConflict,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusConflict, Error{
+			Status: Status{
+				Code:    http.StatusConflict,
+				Message: errConflict,
+			},
+			Message: messageFiltersNotConfigured,
+		})
+
+		return
+	}
+
+	words, err := LoadBannedWords(h.Config.BannedWordsPath)
+	if err != nil {
+		h.Log.Error(
+			errInternalServerError,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusInternalServerError, Error{
+			Status: Status{
+				Code:    http.StatusInternalServerError,
+				Message: errInternalServerError,
+			},
+			Message: messageFiltersReloadFailed,
+		})
+
+		return
+	}
+
+	previous := h.BannedWords.Swap(&words)
+
+	var actor string
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		actor = claims.Subject
+	}
+
+	h.Log.Warn(
+		"список запрещенных слов перечитан",
+		slog.String("op", op),
+		slog.Int("words", words.Len()),
+		slog.Int("previous_words", previous.Len()),
+		slog.String("actor", actor),
+	)
+
+	h.writeJSON(w, http.StatusOK, ReloadFiltersResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Words: words.Len(),
+	})
+}
@@ -0,0 +1,745 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// OpenAPI описывает документ OpenAPI 3, который отдается на /openapi.json
+type OpenAPI struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       OpenAPIInfo           `json:"info"`
+	Servers    []OpenAPIServer       `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components OpenAPIComponents     `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// OpenAPIInfo описывает общие сведения об API
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIServer описывает базовый адрес, относительно которого заданы пути
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIComponents содержит переиспользуемые схемы, на которые ссылаются операции
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme описывает способ аутентификации
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem сопоставляет HTTP-метод (в нижнем регистре) с операцией
+type PathItem map[string]Operation
+
+// Operation описывает одну операцию над маршрутом
+type Operation struct {
+	Summary     string              `json:"summary"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security переопределяет требования документа; пустой список означает, что токен не нужен
+	Security *[]map[string][]string `json:"security,omitempty"`
+	// Servers переопределяет базовый адрес документа для этой операции
+	Servers []OpenAPIServer `json:"servers,omitempty"`
+}
+
+// Parameter описывает параметр пути, запроса или заголовка
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody описывает тело запроса
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response описывает ответ с конкретным кодом
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType связывает тип содержимого со схемой
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema — подмножество JSON Schema, используемое в OpenAPI 3.0
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+// openAPIBuilder собирает документ, регистрируя схемы Go-типов в components по мере их использования
+type openAPIBuilder struct {
+	schemas map[string]*Schema
+}
+
+// schema возвращает схему для значения v. Именованные структуры регистрируются в components и
+// возвращаются ссылкой, поэтому документ всегда совпадает с реальными структурами ответов.
+func (b openAPIBuilder) schema(v any) *Schema {
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+// schemaOf строит схему по reflect.Type с учетом json-тегов
+func (b openAPIBuilder) schemaOf(t reflect.Type) *Schema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := *b.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return &schema
+		}
+		schema.Nullable = true
+		return &schema
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object"}
+
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.schemas[name]; !ok {
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			b.schemas[name] = schema
+
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+
+				tag := strings.Split(field.Tag.Get("json"), ",")[0]
+				if tag == "-" {
+					continue
+				}
+				if tag == "" {
+					tag = field.Name
+				}
+
+				schema.Properties[tag] = b.schemaOf(field.Type)
+			}
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &Schema{}
+}
+
+// jsonContent оборачивает схему в тип содержимого application/json
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+// textContent описывает тело ответа обычным текстом
+func textContent() map[string]MediaType {
+	return map[string]MediaType{
+		"text/plain": {Schema: &Schema{Type: "string"}},
+	}
+}
+
+// newOpenAPI собирает документ OpenAPI для всех маршрутов сервиса. Схема аутентификации
+// добавляется, только если она включена; префикс маршрутов задается через servers. Тип параметра {id}
+// зависит от QUOTE_ID_TYPE.
+func newOpenAPI(jwtEnabled bool, prefix string, quoteIDType string) OpenAPI {
+	b := openAPIBuilder{schemas: map[string]*Schema{}}
+
+	errorResponse := func(description string) Response {
+		return Response{Description: description, Content: jsonContent(b.schema(Error{}))}
+	}
+
+	idParameter := Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer"}}
+	if quoteIDType == config.QuoteIDUUID {
+		idParameter.Schema = &Schema{Type: "string", Format: "uuid"}
+	}
+	authorParameter := Parameter{Name: "author", In: "query", Description: "Фильтр по автору", Schema: &Schema{Type: "string"}}
+	authorsParameter := Parameter{Name: "author", In: "query", Description: "Фильтр по авторам; параметр можно повторить", Schema: &Schema{Type: "array", Items: &Schema{Type: "string"}}}
+	includeParameter := Parameter{Name: "include", In: "query", Description: "meta — добавить производные поля цитаты (длина в символах, число слов)", Schema: &Schema{Type: "string"}}
+
+	paths := map[string]PathItem{
+		"/quotes": {
+			"head": {
+				Summary:    "Заголовки списка цитат (ETag) без тела",
+				Parameters: []Parameter{authorsParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Список цитат"},
+					"304": {Description: "Список не изменился"},
+					"400": {Description: "Некорректные параметры"},
+					"500": {Description: "Внутренняя ошибка"},
+				},
+			},
+			"get": {
+				Summary: "Список цитат",
+				Parameters: []Parameter{
+					authorsParameter,
+					{Name: "include_deleted", In: "query", Description: "Включить мягко удаленные цитаты", Schema: &Schema{Type: "boolean"}},
+					{Name: "sort", In: "query", Description: "Порядок сортировки: popular — по числу лайков, most_viewed — по числу просмотров", Schema: &Schema{Type: "string"}},
+					{Name: "limit", In: "query", Description: "Размер страницы, от 1 до PAGE_SIZE_MAX (по умолчанию PAGE_SIZE_DEFAULT)", Schema: &Schema{Type: "integer"}},
+					{Name: "offset", In: "query", Description: "Сколько цитат пропустить (по умолчанию 0)", Schema: &Schema{Type: "integer"}},
+					{Name: "category", In: "query", Description: "Только цитаты категории с этим ID", Schema: &Schema{Type: "integer"}},
+					{Name: "after", In: "query", Description: "Курсор: вернуть цитаты с ID больше after по возрастанию ID (0 — с начала); несовместим с offset и sort, следующий курсор — next_cursor ответа", Schema: &Schema{Type: "integer"}},
+					{Name: "fields", In: "query", Description: "Поля цитат через запятую (id, uuid, slug, author, quote, likes, views, language, source, deleted_at); остальные поля в ответ не попадают", Schema: &Schema{Type: "string"}},
+					{Name: "If-None-Match", In: "header", Description: "ETag ранее полученного списка", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Список цитат", Content: jsonContent(b.schema(GetQuotesResponse{}))},
+					"304": {Description: "Список не изменился"},
+					"400": errorResponse("Некорректные параметры"),
+					"404": errorResponse("Цитаты не найдены"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+			"post": {
+				Summary: "Добавление цитаты",
+				Parameters: []Parameter{
+					{Name: headerIdempotencyKey, In: "header", Description: "Ключ идемпотентности", Schema: &Schema{Type: "string"}},
+					{Name: "upsert", In: "query", Description: "На дубликат вернуть ID существующей цитаты с existing=true вместо 409; несовместим с Idempotency-Key", Schema: &Schema{Type: "boolean"}},
+				},
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(b.schema(CreateQuoteRequest{}))},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата добавлена, а при upsert=true — такая цитата уже есть (existing=true)", Content: jsonContent(b.schema(CreateQuoteResponse{}))},
+					"201": {Description: "Цитата добавлена (только при upsert=true)", Content: jsonContent(b.schema(CreateQuoteResponse{}))},
+					"400": {Description: "Некорректное тело запроса или поля, не прошедшие проверку (перечислены в errors)", Content: jsonContent(b.schema(ValidationErrorResponse{}))},
+					"409": errorResponse("Такая цитата уже существует (без upsert=true)"),
+					"415": errorResponse("Тело запроса не объявлено как application/json"),
+					"413": errorResponse("Тело запроса больше MAX_BODY_BYTES"),
+					"422": errorResponse("Автор или текст содержат запрещённое слово, или Idempotency-Key уже использован с другим телом"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/{id}": {
+			"get": {
+				Summary:    "Цитата по ID",
+				Parameters: []Parameter{idParameter, includeParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата", Content: jsonContent(b.schema(GetQuoteByIDResponse{}))},
+					"400": errorResponse("Некорректный ID или параметр include"),
+					"404": errorResponse("Цитата не найдена"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+			"delete": {
+				Summary:    "Удаление цитаты по ID",
+				Parameters: []Parameter{idParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата удалена", Content: jsonContent(b.schema(DeleteQuoteByIDResponse{}))},
+					"204": {Description: "Цитаты больше нет, удалена она этим запросом или раньше (при IDEMPOTENT_DELETE=true)"},
+					"400": errorResponse("Некорректный ID"),
+					"404": errorResponse("Цитата не найдена (при IDEMPOTENT_DELETE=false)"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/slug/{slug}": {
+			"get": {
+				Summary: "Цитата по slug",
+				Parameters: []Parameter{
+					{Name: "slug", In: "path", Required: true, Description: "Читаемый идентификатор цитаты, например marcus-aurelius-the-happiness-of-your-3", Schema: &Schema{Type: "string"}},
+					includeParameter,
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата", Content: jsonContent(b.schema(GetQuoteByIDResponse{}))},
+					"400": errorResponse("Некорректный параметр include"),
+					"404": errorResponse("Цитата не найдена"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/{id}/restore": {
+			"post": {
+				Summary:    "Восстановление мягко удаленной цитаты",
+				Parameters: []Parameter{idParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата восстановлена", Content: jsonContent(b.schema(RestoreQuoteByIDResponse{}))},
+					"400": errorResponse("Некорректный ID"),
+					"404": errorResponse("Цитата не найдена"),
+					"409": errorResponse("Цитата не удалена или уже создана заново"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/{id}/like": {
+			"post": {
+				Summary:    "Лайк цитаты",
+				Parameters: []Parameter{idParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Новое число лайков", Content: jsonContent(b.schema(LikeQuoteByIDResponse{}))},
+					"400": errorResponse("Некорректный ID"),
+					"404": errorResponse("Цитата не найдена"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/{id}/categories": {
+			"post": {
+				Summary:     "Отнесение цитаты к категории (повторное отнесение ничего не меняет)",
+				Parameters:  []Parameter{idParameter},
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(b.schema(AssignCategoryRequest{}))},
+				Responses: map[string]Response{
+					"200": {Description: "Цитата отнесена к категории", Content: jsonContent(b.schema(AssignCategoryResponse{}))},
+					"400": errorResponse("Некорректный ID или category_id"),
+					"404": errorResponse("Цитата или категория не найдена"),
+					"415": errorResponse("Тело запроса не объявлено как application/json"),
+					"413": errorResponse("Тело запроса больше MAX_BODY_BYTES"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/categories": {
+			"get": {
+				Summary: "Категории по алфавиту с числом цитат в каждой",
+				Responses: map[string]Response{
+					"200": {Description: "Список категорий", Content: jsonContent(b.schema(GetCategoriesResponse{}))},
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+			"post": {
+				Summary:     "Создание категории",
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(b.schema(CreateCategoryRequest{}))},
+				Responses: map[string]Response{
+					"200": {Description: "ID созданной категории", Content: jsonContent(b.schema(CreateCategoryResponse{}))},
+					"400": {Description: "Некорректное тело запроса или поля, не прошедшие проверку (перечислены в errors)", Content: jsonContent(b.schema(ValidationErrorResponse{}))},
+					"409": errorResponse("Категория с таким названием уже есть"),
+					"415": errorResponse("Тело запроса не объявлено как application/json"),
+					"413": errorResponse("Тело запроса больше MAX_BODY_BYTES"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/ready": {
+			"get": {
+				Summary:  "Проба готовности: доступно ли хранилище",
+				Security: &[]map[string][]string{},
+				Responses: map[string]Response{
+					"200": {Description: "Хранилище доступно", Content: jsonContent(b.schema(ReadyResponse{}))},
+					"503": errorResponse("Соединение с БД потеряно и восстанавливается или, при READY_CHECK_MIGRATIONS=true, схема БД отстает от миграций"),
+				},
+			},
+		},
+		"/version": {
+			"get": {
+				Summary:  "Сведения о сборке: версия, коммит и время сборки",
+				Security: &[]map[string][]string{},
+				Responses: map[string]Response{
+					"200": {Description: "Сведения о запущенном бинарнике", Content: jsonContent(b.schema(VersionResponse{}))},
+				},
+			},
+		},
+		"/stats": {
+			"get": {
+				Summary: "Сводные показатели: число цитат и авторов, самый плодовитый автор, состояние пула соединений с БД",
+				Responses: map[string]Response{
+					"200": {Description: "Показатели", Content: jsonContent(b.schema(StatsResponse{}))},
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/stats/text": {
+			"get": {
+				Summary: "Показатели текста цитат: число слов и символов, средняя длина, самая длинная и самая короткая цитата",
+				Responses: map[string]Response{
+					"200": {Description: "Показатели", Content: jsonContent(b.schema(TextStatsResponse{}))},
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/audit": {
+			"get": {
+				Summary: "Журнал аудита изменений цитат, начиная с последних записей (только для роли admin)",
+				Parameters: []Parameter{
+					{Name: "limit", In: "query", Description: "Размер страницы, от 1 до PAGE_SIZE_MAX (по умолчанию PAGE_SIZE_DEFAULT)", Schema: &Schema{Type: "integer"}},
+					{Name: "offset", In: "query", Description: "Сколько записей пропустить (по умолчанию 0)", Schema: &Schema{Type: "integer"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Записи журнала", Content: jsonContent(b.schema(AuditLogResponse{}))},
+					"400": errorResponse("Некорректный limit или offset"),
+					"403": errorResponse("Нужна роль admin"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/all": {
+			"delete": {
+				Summary: "Удаление всех цитат (включая мягко удаленные) со сбросом счетчика ID",
+				Parameters: []Parameter{
+					{Name: "confirm", In: "query", Required: true, Description: "Подтверждение, должно быть true", Schema: &Schema{Type: "boolean"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Число удаленных цитат", Content: jsonContent(b.schema(PurgeQuotesResponse{}))},
+					"400": errorResponse("Нет подтверждения confirm=true"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/authors": {
+			"get": {
+				Summary: "Авторы по алфавиту с числом цитат каждого, постранично",
+				Parameters: []Parameter{
+					{Name: "limit", In: "query", Description: "Размер страницы, положительное число; больше PAGE_SIZE_MAX уменьшается до него (по умолчанию PAGE_SIZE_DEFAULT)", Schema: &Schema{Type: "integer"}},
+					{Name: "offset", In: "query", Description: "Сколько авторов пропустить (по умолчанию 0)", Schema: &Schema{Type: "integer"}},
+					{Name: "prefix", In: "query", Description: "Только авторы, чье имя начинается с prefix, без учета регистра", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Страница авторов; has_more — есть ли следующая", Content: jsonContent(b.schema(GetAuthorsResponse{}))},
+					"400": errorResponse("Некорректный limit или offset"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+			"patch": {
+				Summary:     "Переименование автора у всех его цитат (мягко удаленные не затрагиваются)",
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(b.schema(RenameAuthorRequest{}))},
+				Responses: map[string]Response{
+					"200": {Description: "Число переименованных цитат", Content: jsonContent(b.schema(RenameAuthorResponse{}))},
+					"400": {Description: "Некорректное тело запроса или поля, не прошедшие проверку (перечислены в errors)", Content: jsonContent(b.schema(ValidationErrorResponse{}))},
+					"404": errorResponse("Цитат автора from нет"),
+					"409": errorResponse("У автора to уже есть одна из переименуемых цитат"),
+					"415": errorResponse("Тело запроса не объявлено как application/json"),
+					"413": errorResponse("Тело запроса больше MAX_BODY_BYTES"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/search": {
+			"get": {
+				Summary: "Полнотекстовый поиск (только PostgreSQL)",
+				Parameters: []Parameter{
+					{Name: "q", In: "query", Required: true, Description: "Поисковый запрос: слова, фразы в кавычках, OR, исключение через -", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "До 100 цитат по убыванию релевантности", Content: jsonContent(b.schema(GetQuotesResponse{}))},
+					"400": errorResponse("Не задан параметр q"),
+					"500": errorResponse("Внутренняя ошибка"),
+					"501": errorResponse("Бэкенд хранилища не поддерживает полнотекстовый поиск"),
+				},
+			},
+		},
+		"/quotes/recent": {
+			"get": {
+				Summary: "Последние добавленные цитаты",
+				Parameters: []Parameter{
+					{Name: "limit", In: "query", Description: "Сколько цитат вернуть (по умолчанию 10, больше 100 урезается до 100)", Schema: &Schema{Type: "integer"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Цитаты от самой новой к старой; пустой список, если цитат нет", Content: jsonContent(b.schema(GetQuotesResponse{}))},
+					"400": errorResponse("Некорректный limit"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/batch": {
+			"get": {
+				Summary: "Несколько цитат по списку ID",
+				Parameters: []Parameter{
+					{Name: "ids", In: "query", Required: true, Description: "ID цитат через запятую, не больше 100", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Найденные цитаты по возрастанию ID; отсутствующие пропускаются", Content: jsonContent(b.schema(GetQuotesResponse{}))},
+					"400": errorResponse("Параметр ids пуст или некорректен"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/exists": {
+			"get": {
+				Summary: "Проверка, есть ли уже цитата с таким автором и текстом",
+				Parameters: []Parameter{
+					{Name: "author", In: "query", Required: true, Description: "Автор", Schema: &Schema{Type: "string"}},
+					{Name: "quote", In: "query", Required: true, Description: "Текст цитаты", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "exists: true, если добавление такой цитаты вернет 409", Content: jsonContent(b.schema(QuoteExistsResponse{}))},
+					"400": errorResponse("Не задан author или quote"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/import": {
+			"post": {
+				Summary: "Импорт цитат из массива JSON или CSV с итогом по каждой строке",
+				Parameters: []Parameter{
+					{Name: "on_error", In: "query", Description: "continue (по умолчанию) — продолжать после строки с ошибкой, stop — остановиться на ней", Schema: &Schema{Type: "string"}},
+					{Name: "mode", In: "query", Description: "lenient (по умолчанию) — пропускать дубликаты и строки с ошибками, strict — отменить весь импорт на первой такой строке; с on_error не сочетается", Schema: &Schema{Type: "string"}},
+				},
+				RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{
+					mediaTypeJSON: {Schema: b.schema([]CreateQuoteRequest{})},
+					mediaTypeCSV:  {Schema: &Schema{Type: "string"}},
+				}},
+				Responses: map[string]Response{
+					"200": {Description: "Сводка и итог каждой обработанной строки: inserted, duplicate или error", Content: jsonContent(b.schema(ImportQuotesResponse{}))},
+					"400": errorResponse("Тело не разбирается, заголовок CSV некорректен, тело пустое, длиннее 1000 строк, некорректны on_error или mode"),
+					"415": errorResponse("Тело не объявлено как application/json или text/csv"),
+					"413": errorResponse("Тело запроса больше MAX_IMPORT_BYTES"),
+					"422": {Description: "Строгий импорт отменен, ничего не добавлено; в rows — строка с ошибкой или дубликат", Content: jsonContent(b.schema(ImportQuotesResponse{}))},
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/{id}/similar": {
+			"get": {
+				Summary: "Похожие по тексту цитаты (только PostgreSQL)",
+				Parameters: []Parameter{
+					idParameter,
+					{Name: "limit", In: "query", Description: "Сколько цитат вернуть, от 1 до 50 (по умолчанию 5)", Schema: &Schema{Type: "integer"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Похожие цитаты по убыванию сходства", Content: jsonContent(b.schema(GetSimilarQuotesResponse{}))},
+					"400": errorResponse("Некорректный ID или limit"),
+					"404": errorResponse("Цитата не найдена"),
+					"500": errorResponse("Внутренняя ошибка"),
+					"501": errorResponse("Бэкенд хранилища не поддерживает поиск похожих цитат"),
+				},
+			},
+		},
+		"/quotes/random": {
+			"get": {
+				Summary: "Случайная цитата",
+				Parameters: []Parameter{
+					{Name: "fair", In: "query", Description: "Равная вероятность для каждого автора вместо равной для каждой цитаты", Schema: &Schema{Type: "boolean"}},
+					{Name: "exclude_author", In: "query", Description: "Не выбирать цитаты этого автора", Schema: &Schema{Type: "string"}},
+					{Name: "Accept-Language", In: "header", Description: "Предпочитаемые языки: цитата выбирается на лучше всего подходящем из них, если такие есть", Schema: &Schema{Type: "string"}},
+					includeParameter,
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Случайная цитата", Content: jsonContent(b.schema(GetRandomQuoteResponse{}))},
+					"400": errorResponse("Некорректный параметр fair или include"),
+					"404": errorResponse("Цитат нет (в том числе после исключения автора)"),
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+			"head": {
+				Summary: "Проверка наличия цитат (без тела и без учета просмотра)",
+				Responses: map[string]Response{
+					"200": {Description: "Цитаты есть"},
+					"404": {Description: "Цитат нет"},
+					"500": {Description: "Внутренняя ошибка"},
+				},
+			},
+		},
+		"/quotes/random.txt": {
+			"get": {
+				Summary: "Случайная цитата обычным текстом для терминалов и скриптов",
+				Parameters: []Parameter{
+					{Name: "Accept-Language", In: "header", Description: "Предпочитаемые языки, как у /quotes/random", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Строка вида \"цитата\" — автор", Content: textContent()},
+					"404": {Description: "Цитат нет", Content: textContent()},
+					"500": {Description: "Внутренняя ошибка", Content: textContent()},
+				},
+			},
+		},
+		"/quotes/random.svg": {
+			"get": {
+				Summary: "Случайная цитата картинкой SVG для README и писем",
+				Parameters: []Parameter{
+					{Name: "width", In: "query", Description: "Ширина картинки в пикселях, от 200 до 2000 (по умолчанию 600); текст переносится по ней", Schema: &Schema{Type: "integer"}},
+					{Name: "theme", In: "query", Description: "Тема оформления: light (по умолчанию) или dark", Schema: &Schema{Type: "string"}},
+					{Name: "bg", In: "query", Description: "Цвет фона без #, например fff или 1e90ff", Schema: &Schema{Type: "string"}},
+					{Name: "fg", In: "query", Description: "Цвет текста цитаты без #", Schema: &Schema{Type: "string"}},
+					{Name: "accent", In: "query", Description: "Цвет автора и рамки без #", Schema: &Schema{Type: "string"}},
+					{Name: "Accept-Language", In: "header", Description: "Предпочитаемые языки, как у /quotes/random", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Картинка с цитатой и автором", Content: map[string]MediaType{"image/svg+xml": {Schema: &Schema{Type: "string"}}}},
+					"400": {Description: "Некорректные width, theme или цвета", Content: textContent()},
+					"404": {Description: "Цитат нет", Content: textContent()},
+					"500": {Description: "Внутренняя ошибка", Content: textContent()},
+				},
+			},
+		},
+		"/quotes/feed.rss": {
+			"get": {
+				Summary: "Лента RSS 2.0 последних добавленных цитат",
+				Parameters: []Parameter{
+					{Name: "limit", In: "query", Description: "Сколько цитат вернуть (по умолчанию 10, больше 100 урезается до 100)", Schema: &Schema{Type: "integer"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Лента: автор в title, цитата в description", Content: map[string]MediaType{"application/rss+xml": {Schema: &Schema{Type: "string"}}}},
+					"400": {Description: "Некорректный limit", Content: textContent()},
+					"500": {Description: "Внутренняя ошибка", Content: textContent()},
+				},
+			},
+		},
+		"/quotes/count": {
+			"get": {
+				Summary:    "Количество цитат",
+				Parameters: []Parameter{authorParameter},
+				Responses: map[string]Response{
+					"200": {Description: "Количество цитат", Content: jsonContent(b.schema(CountQuotesResponse{}))},
+					"500": errorResponse("Внутренняя ошибка"),
+				},
+			},
+		},
+		"/quotes/export": {
+			"get": {
+				Summary: "Выгрузка цитат в формате NDJSON",
+				Parameters: []Parameter{
+					authorsParameter,
+					{Name: "format", In: "query", Description: "Формат выгрузки, поддерживается только ndjson", Schema: &Schema{Type: "string"}},
+				},
+				Responses: map[string]Response{
+					"200": {Description: "Одна цитата на строку", Content: map[string]MediaType{
+						"application/x-ndjson": {Schema: b.schema(storage.Quote{})},
+					}},
+					"400": errorResponse("Некорректные параметры"),
+				},
+			},
+		},
+		"/quotes/stream": {
+			"get": {
+				Summary: "Поток новых цитат (Server-Sent Events)",
+				Responses: map[string]Response{
+					"200": {Description: "Поток событий quote", Content: map[string]MediaType{
+						"text/event-stream": {Schema: &Schema{Type: "string"}},
+					}},
+				},
+			},
+		},
+		"/admin/readonly": {
+			"get": {
+				Summary: "Включен ли режим только для чтения",
+				Responses: map[string]Response{
+					"200": {Description: "Текущее состояние режима", Content: jsonContent(b.schema(ReadOnlyResponse{}))},
+				},
+			},
+			"post": {
+				Summary:     "Включение или выключение режима только для чтения: изменяющие запросы получают 503, чтение работает",
+				RequestBody: &RequestBody{Required: true, Content: jsonContent(b.schema(ReadOnlyRequest{}))},
+				Responses: map[string]Response{
+					"200": {Description: "Новое состояние режима", Content: jsonContent(b.schema(ReadOnlyResponse{}))},
+					"400": errorResponse("Тело не содержит read_only"),
+					"415": errorResponse("Тело не объявлено как application/json"),
+					"413": errorResponse("Тело запроса больше MAX_BODY_BYTES"),
+				},
+			},
+		},
+		"/admin/reload-filters": {
+			"post": {
+				Summary: "Перечитать файл запрещенных слов без перезапуска",
+				Responses: map[string]Response{
+					"200": {Description: "Сколько слов в новом списке", Content: jsonContent(b.schema(ReloadFiltersResponse{}))},
+					"409": errorResponse("BANNED_WORDS_PATH не задан"),
+					"500": errorResponse("Файл не прочитался, действует прежний список"),
+				},
+			},
+		},
+		"/openapi.json": {
+			"get": {
+				Summary: "Этот документ",
+				Responses: map[string]Response{
+					"200": {Description: "Документ OpenAPI 3"},
+				},
+			},
+		},
+	}
+
+	doc := OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:   "getcitation",
+			Version: "1.0.0",
+		},
+		Paths: paths,
+		Components: OpenAPIComponents{
+			Schemas: b.schemas,
+		},
+	}
+
+	if prefix != "" {
+		doc.Servers = []OpenAPIServer{{URL: prefix}}
+
+		// Проба готовности и сведения о сборке не префиксуются.
+		for _, path := range []string{"/ready", "/version"} {
+			operation := paths[path]["get"]
+			operation.Servers = []OpenAPIServer{{URL: "/"}}
+			paths[path]["get"] = operation
+		}
+	}
+
+	if jwtEnabled {
+		doc.Components.SecuritySchemes = map[string]SecurityScheme{
+			"bearer": {
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+				Description:  "HS256; изменяющие методы и include_deleted требуют role=admin",
+			},
+		}
+		doc.Security = []map[string][]string{{"bearer": {}}}
+	}
+
+	return doc
+}
+
+// GetOpenAPI обрабатывает HTTP GET запрос на получение документа OpenAPI
+func (h Handlers) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetOpenAPI()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, h.OpenAPI)
+}
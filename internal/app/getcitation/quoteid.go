@@ -0,0 +1,78 @@
+package getcitation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"getcitation/internal/utils/config"
+)
+
+// Ошибки разбора ID цитаты из пути
+var (
+	errMalformedQuoteID   = errors.New("malformed quote ID")
+	errMalformedQuoteUUID = errors.New("malformed quote UUID")
+)
+
+// parseQuoteID разбирает ID цитаты из пути. При QUOTE_ID_TYPE=uuid принимается только UUID, который
+// переводится во внутренний ID (ErrNoQuotesFound, если такой цитаты нет); последовательные ID при этом
+// отклоняются, иначе цитаты можно было бы по-прежнему перебирать по порядку.
+func (h Handlers) parseQuoteID(ctx context.Context, raw string) (int, error) {
+	const op = "getcitation.Transport.parseQuoteID()"
+
+	if h.Config.QuoteIDType != config.QuoteIDUUID {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w: %w", op, errMalformedQuoteID, err)
+		}
+		return id, nil
+	}
+
+	parsed, err := uuid.Parse(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w: %w", op, errMalformedQuoteUUID, err)
+	}
+
+	id, err := h.Getter.GetQuoteIDByUUID(ctx, parsed.String())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// writeQuoteIDError отвечает на ошибку parseQuoteID: 400 на некорректный ID, 404, если цитаты с таким UUID
+// нет, и код внутренней ошибки, если хранилище не ответило.
+func (h Handlers) writeQuoteIDError(w http.ResponseWriter, r *http.Request, op string, err error) {
+	code, status, message := http.StatusBadRequest, errBadRequest, messageMalformedID
+
+	switch {
+	case errors.Is(err, errMalformedQuoteUUID):
+		message = messageMalformedUUID
+	case errors.Is(err, ErrNoQuotesFound):
+		code, status, message = http.StatusNotFound, errNotFound, messageQuoteNotFoundByID
+	case !errors.Is(err, errMalformedQuoteID):
+		code, status = internalStatus(err)
+		message = ""
+	}
+
+	h.Log.Error(
+		status,
+		slog.String("op", op),
+		slog.Any("error", err),
+		slog.String("path", r.URL.Path),
+	)
+
+	h.writeJSON(w, code, Error{
+		Status: Status{
+			Code:    code,
+			Message: status,
+		},
+		Message: message,
+		Debug:   h.errorDebug(op, err),
+	})
+}
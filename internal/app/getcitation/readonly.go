@@ -0,0 +1,156 @@
+package getcitation
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// Сообщения режима только для чтения
+const (
+	messageReadOnly          string = "Service is in read-only mode for maintenance, only reads are accepted"
+	messageMalformedReadOnly string = "Request body must be {\"read_only\": true|false}"
+)
+
+// ReadOnlyRequest описывает формат запроса на переключение режима только для чтения
+type ReadOnlyRequest struct {
+	ReadOnly *bool `json:"read_only"`
+}
+
+// ReadOnlyResponse описывает текущее состояние режима только для чтения
+type ReadOnlyResponse struct {
+	Status   Status `json:"status"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// ReadOnly отклоняет изменяющие запросы с 503, пока включен режим только для чтения (READ_ONLY_MODE
+// при запуске или POST /admin/readonly на ходу), а чтение пропускает как обычно. Маршруты exempt
+// (переключатель режима) доступны всегда, иначе режим нельзя было бы выключить без перезапуска.
+func (h Handlers) ReadOnly(next http.Handler, exempt ...string) http.Handler {
+	const op = "getcitation.Transport.ReadOnly()"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isReadOnly(r.Method) || !h.ReadOnlyMode.Load() || slices.Contains(exempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Log.Warn(
+			"изменение отклонено в режиме только для чтения",
+			slog.String("op", op),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		)
+
+		w.Header().Set("Cache-Control", cacheControlNoStore)
+
+		h.writeJSON(w, http.StatusServiceUnavailable, Error{
+			Status: Status{
+				Code:    http.StatusServiceUnavailable,
+				Message: errServiceUnavailable,
+			},
+			Message: messageReadOnly,
+		})
+	})
+}
+
+// AdminReadOnly обрабатывает HTTP запросы к режиму только для чтения: GET возвращает текущее состояние,
+// POST включает или выключает режим. Как и другие изменения, POST при включенной аутентификации
+// доступен только роли admin. Состояние не сохраняется: после перезапуска режим снова берется из READ_ONLY_MODE.
+func (h Handlers) AdminReadOnly(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.AdminReadOnly()"
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		// Только текущее состояние, оно отдается ниже
+
+	case http.MethodPost:
+		if !isJSON(r) {
+			h.Log.Error(
+				errUnsupportedMedia,
+				slog.String("op", op),
+				slog.String("content_type", r.Header.Get("Content-Type")),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusUnsupportedMediaType, Error{
+				Status: Status{
+					Code:    http.StatusUnsupportedMediaType,
+					Message: errUnsupportedMedia,
+				},
+				Message: messageJSONRequired,
+			})
+
+			return
+		}
+
+		limitBody(w, r, h.Config.MaxBodyBytes)
+
+		var req ReadOnlyRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if h.rejectLargeBody(w, r, op, err) {
+			return
+		}
+		if err != nil || req.ReadOnly == nil {
+			h.Log.Error(
+				errBadRequest,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(w, http.StatusBadRequest, Error{
+				Status: Status{
+					Code:    http.StatusBadRequest,
+					Message: errBadRequest,
+				},
+				Message: messageMalformedReadOnly,
+			})
+
+			return
+		}
+		defer r.Body.Close()
+
+		var actor string
+		if claims, ok := ClaimsFromContext(r.Context()); ok {
+			actor = claims.Subject
+		}
+
+		if h.ReadOnlyMode.Swap(*req.ReadOnly) != *req.ReadOnly {
+			h.Log.Warn(
+				"режим только для чтения переключен",
+				slog.String("op", op),
+				slog.Bool("read_only", *req.ReadOnly),
+				slog.String("actor", actor),
+			)
+		}
+
+	default:
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodHead, http.MethodPost)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+
+	h.writeJSON(w, http.StatusOK, ReadOnlyResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		ReadOnly: h.ReadOnlyMode.Load(),
+	})
+}
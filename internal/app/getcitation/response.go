@@ -0,0 +1,235 @@
+package getcitation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// jsonIndent — отступ JSON в ответах на запросы с ?pretty=true
+const jsonIndent string = "  "
+
+// Версии формата JSON-ответов. В v1 код и текст статуса вложены в объект status, в v2 они вынесены
+// на верхний уровень ответа: {"code": 404, "status": "Not Found", ...}.
+const (
+	responseV1 int = 1
+	responseV2 int = 2
+)
+
+// mediaTypeV2 — тип содержимого, которым клиент запрашивает ответы v2 в заголовке Accept и который
+// приходит в Content-Type таких ответов
+const mediaTypeV2 string = "application/vnd.getcitation.v2+json"
+
+// vendorMediaTypePrefix — начало типов содержимого вида application/vnd.getcitation.vN+json
+const vendorMediaTypePrefix string = "application/vnd.getcitation."
+
+// jsonWriter помечает ответ, JSON которого нужно выводить с отступами или в другой версии формата
+// (см. ResponseFormat)
+type jsonWriter struct {
+	http.ResponseWriter
+	pretty  bool
+	version int
+}
+
+// Unwrap дает http.ResponseController добраться до исходного ResponseWriter (Flush в SSE и т.п.)
+func (w jsonWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// responseVersion выбирает версию формата ответа: параметр v (1 или 2) важнее заголовка Accept,
+// в котором ищется тип application/vnd.getcitation.vN+json. Без того и другого — v1. Неизвестная
+// версия — ошибка.
+func responseVersion(r *http.Request) (int, error) {
+	const op = "getcitation.responseVersion()"
+
+	if raw := r.URL.Query().Get("v"); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil || (version != responseV1 && version != responseV2) {
+			return 0, fmt.Errorf("%s: unsupported version %q", op, raw)
+		}
+		return version, nil
+	}
+
+	for _, value := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(value, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+			if !strings.HasPrefix(mediaType, vendorMediaTypePrefix) {
+				continue
+			}
+			if mediaType == mediaTypeV2 {
+				return responseV2, nil
+			}
+			if mediaType == vendorMediaTypePrefix+"v1+json" {
+				return responseV1, nil
+			}
+			return 0, fmt.Errorf("%s: unsupported media type %q", op, mediaType)
+		}
+	}
+
+	return responseV1, nil
+}
+
+// ResponseFormat выбирает формат JSON-ответов запроса: с отступами для ?pretty=true (удобно читать глазами,
+// по умолчанию JSON компактный) и версию формата по ?v= или заголовку Accept (см. responseVersion).
+// На неизвестную версию отвечает 406. Должен стоять непосредственно перед обработчиками: writeJSON узнает
+// о формате по типу ResponseWriter, а промежуточные обертки (например, http.TimeoutHandler) его скрывают.
+func (h Handlers) ResponseFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const op = "getcitation.Transport.ResponseFormat()"
+
+		w.Header().Add("Vary", "Accept")
+
+		pretty, _ := strconv.ParseBool(r.URL.Query().Get("pretty"))
+
+		version, err := responseVersion(r)
+		if err != nil {
+			h.Log.Error(
+				errNotAcceptable,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeJSON(jsonWriter{ResponseWriter: w, pretty: pretty, version: responseV1}, http.StatusNotAcceptable, Error{
+				Status: Status{
+					Code:    http.StatusNotAcceptable,
+					Message: errNotAcceptable,
+				},
+				Message: messageUnsupportedVersion,
+			})
+
+			return
+		}
+
+		if pretty || version != responseV1 {
+			w = jsonWriter{ResponseWriter: w, pretty: pretty, version: version}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// flattenStatus переводит ответ v1 в v2: объект status заменяется полями code и status (текст статуса,
+// только если он есть) на верхнем уровне. Ответы без объекта status возвращаются как есть.
+func flattenStatus(body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return body, nil
+	}
+
+	raw, ok := fields["status"]
+	if !ok {
+		return body, nil
+	}
+
+	var status Status
+	err = json.Unmarshal(raw, &status)
+	if err != nil {
+		return body, nil
+	}
+
+	delete(fields, "status")
+
+	fields["code"], err = json.Marshal(status.Code)
+	if err != nil {
+		return nil, err
+	}
+	if status.Message != "" {
+		fields["status"], err = json.Marshal(status.Message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// writeJSON отправляет v в формате JSON с кодом code. Ответ сначала целиком кодируется в буфер, поэтому
+// у него есть Content-Length (без него ответ уходит частями, что не любят некоторые клиенты и прокси),
+// а ошибка кодирования превращается в настоящий 500 — код ответа к этому моменту еще не отправлен.
+func (h Handlers) writeJSON(w http.ResponseWriter, code int, v any) {
+	const op = "getcitation.Transport.writeJSON()"
+
+	format, _ := w.(jsonWriter)
+
+	body, err := json.Marshal(v)
+	if err == nil && format.version == responseV2 {
+		body, err = flattenStatus(body)
+	}
+	if err != nil {
+		h.Log.Error(
+			errInternalServerError,
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+
+		body, _ = json.Marshal(Error{
+			Status: Status{
+				Code:    http.StatusInternalServerError,
+				Message: errInternalServerError,
+			},
+		})
+		if format.version == responseV2 {
+			body, _ = flattenStatus(body)
+		}
+		code = http.StatusInternalServerError
+	}
+
+	var buf bytes.Buffer
+
+	if format.pretty {
+		json.Indent(&buf, body, "", jsonIndent)
+	} else {
+		buf.Write(body)
+	}
+	buf.WriteByte('\n')
+
+	contentType := "application/json"
+	if format.version == responseV2 {
+		contentType = mediaTypeV2
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(code)
+
+	w.Write(buf.Bytes())
+}
+
+// setAllow перечисляет в заголовке Allow методы, которые поддерживает маршрут. По RFC 9110 (ранее RFC 7231)
+// заголовок обязателен в ответах 405.
+func setAllow(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+}
+
+// errorDebug возвращает детали ошибки для ответа или nil, если их нельзя раскрывать клиенту
+// (см. config.Config.InternalErrorsExposed)
+func (h Handlers) errorDebug(op string, err error) *ErrorDebug {
+	if err == nil || !h.Config.InternalErrorsExposed() {
+		return nil
+	}
+	return &ErrorDebug{
+		Op:    op,
+		Error: err.Error(),
+	}
+}
+
+// writeText отправляет text обычным текстом с кодом code и переводом строки в конце — для ответов,
+// которые читают из терминала и скриптов
+func (h Handlers) writeText(w http.ResponseWriter, code int, text string) {
+	body := text + "\n"
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(code)
+
+	io.WriteString(w, body)
+}
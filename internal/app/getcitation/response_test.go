@@ -0,0 +1,68 @@
+package getcitation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestPrettyResponses(t *testing.T) {
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Confucius", Quote: "Life is simple"})
+
+	handler := newTestApp(t, testConfig(), store)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantCode   int
+		wantPretty bool
+	}{
+		{"default", "/quotes", http.StatusOK, false},
+		{"pretty", "/quotes?pretty=true", http.StatusOK, true},
+		{"pretty as 1", "/quotes?pretty=1", http.StatusOK, true},
+		{"pretty=false", "/quotes?pretty=false", http.StatusOK, false},
+		{"not a boolean", "/quotes?pretty=yes", http.StatusOK, false},
+		{"error", "/quotes/99?pretty=true", http.StatusNotFound, true},
+		{"compact error", "/quotes/99", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, tt.target, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			body := w.Body.Bytes()
+			if !json.Valid(body) {
+				t.Fatalf("body is not valid JSON: %s", body)
+			}
+
+			var compact bytes.Buffer
+			err := json.Compact(&compact, body)
+			if err != nil {
+				t.Fatalf("json.Compact() error = %v", err)
+			}
+
+			var want bytes.Buffer
+			if tt.wantPretty {
+				json.Indent(&want, compact.Bytes(), "", jsonIndent)
+			} else {
+				want.Write(compact.Bytes())
+			}
+			want.WriteByte('\n')
+
+			if got := w.Body.String(); got != want.String() {
+				t.Errorf("body = %q, want %q", got, want.String())
+			}
+			if pretty := strings.Contains(w.Body.String(), "\n"+jsonIndent+`"`); pretty != tt.wantPretty {
+				t.Errorf("indented = %v, want %v: %s", pretty, tt.wantPretty, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package getcitation
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+)
+
+// clientErrors — сообщения, с которыми обработчики пишут в журнал ошибки клиента (4xx)
+var clientErrors = []string{
+	errMethodNotAllowed,
+	errBadRequest,
+	errNotFound,
+	errConflict,
+	errUnauthorized,
+	errForbidden,
+	errUnsupportedMedia,
+	errUnprocessable,
+	errBodyTooLarge,
+}
+
+// sampledHandler пропускает в журнал в среднем одну из rate записей об ошибках клиента, чтобы поток
+// некорректных запросов не забивал хранилище логов. Запись выбирается случайно, а не по счетчику:
+// иначе при четном rate из пар «ошибка обработчика + строка журнала запросов» оставалась бы
+// всегда одна и та же. Ошибкой клиента считается запись обработчика
+// с сообщением из clientErrors или запись журнала запросов с кодом 4xx; 5xx и прочие записи
+// пишутся всегда. Оставленные записи помечаются атрибутом sample_rate.
+type sampledHandler struct {
+	slog.Handler
+	rate int
+}
+
+// sampleClientErrors оборачивает логгер выборкой ошибок клиента 1 из rate. При rate не больше 1
+// возвращает логгер как есть.
+func sampleClientErrors(log *slog.Logger, rate int) *slog.Logger {
+	if rate <= 1 {
+		return log
+	}
+
+	return slog.New(sampledHandler{
+		Handler: log.Handler(),
+		rate:    rate,
+	})
+}
+
+func (h sampledHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !isClientError(record) {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	if rand.N(h.rate) != 0 {
+		return nil
+	}
+
+	record.AddAttrs(slog.Int("sample_rate", h.rate))
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h sampledHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return sampledHandler{Handler: h.Handler.WithAttrs(attrs), rate: h.rate}
+}
+
+func (h sampledHandler) WithGroup(name string) slog.Handler {
+	return sampledHandler{Handler: h.Handler.WithGroup(name), rate: h.rate}
+}
+
+// isClientError сообщает, описывает ли запись ошибку клиента
+func isClientError(record slog.Record) bool {
+	if slices.Contains(clientErrors, record.Message) {
+		return true
+	}
+
+	clientError := false
+
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != "status" || attr.Value.Kind() != slog.KindInt64 {
+			return true
+		}
+
+		status := attr.Value.Int64()
+		clientError = status >= 400 && status < 500
+		return false
+	})
+	return clientError
+}
@@ -0,0 +1,86 @@
+package getcitation
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// countingHandler считает записи журнала по сообщениям и помнит, сколько из них помечено sample_rate
+type countingHandler struct {
+	mu      *sync.Mutex
+	counts  map[string]int
+	sampled map[string]int
+}
+
+func newCountingHandler() countingHandler {
+	return countingHandler{mu: &sync.Mutex{}, counts: map[string]int{}, sampled: map[string]int{}}
+}
+
+func (h countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h countingHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.counts[record.Message]++
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "sample_rate" {
+			h.sampled[record.Message]++
+		}
+		return true
+	})
+	return nil
+}
+
+func (h countingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h countingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestSampleClientErrors(t *testing.T) {
+	const (
+		rate    = 10
+		records = 20000
+	)
+
+	counter := newCountingHandler()
+	log := sampleClientErrors(slog.New(counter), rate)
+
+	for range records {
+		log.Error(errBadRequest, slog.String("op", "test"))
+		log.Info("request", slog.Int("status", http.StatusNotFound))
+		log.Error(errInternalServerError, slog.String("op", "test"))
+		log.Info("request", slog.Int("status", http.StatusInternalServerError))
+	}
+
+	// Биномиальное распределение: ожидание records/rate, стандартное отклонение около 42,
+	// допуск — больше пяти отклонений
+	const want, tolerance = records / rate, 250
+
+	if got := counter.counts[errBadRequest]; got < want-tolerance || got > want+tolerance {
+		t.Errorf("logged %d of %d client errors, want about %d", got, records, want)
+	}
+	if got := counter.sampled[errBadRequest]; got != counter.counts[errBadRequest] {
+		t.Errorf("%d of %d sampled records carry sample_rate, want all", got, counter.counts[errBadRequest])
+	}
+	if got := counter.counts[errInternalServerError]; got != records || counter.sampled[errInternalServerError] != 0 {
+		t.Errorf("logged %d server errors (%d marked sampled), want all %d unmarked", got, counter.sampled[errInternalServerError], records)
+	}
+
+	// Записи журнала запросов: 404 проходит выборку, 500 пишется всегда
+	if got := counter.counts["request"]; got < records+want-tolerance || got > records+want+tolerance {
+		t.Errorf("logged %d request records, want all %d with status 500 and about %d with status 404", got, records, want)
+	}
+}
+
+func TestSampleClientErrorsDisabled(t *testing.T) {
+	for _, rate := range []int{0, 1} {
+		log := slog.New(newCountingHandler())
+
+		if got := sampleClientErrors(log, rate); got != log {
+			t.Errorf("sampleClientErrors(rate=%d) wrapped the logger, want it returned as is", rate)
+		}
+	}
+}
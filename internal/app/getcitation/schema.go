@@ -0,0 +1,160 @@
+package getcitation
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// createQuoteSchemaJSON — JSON Schema тела POST /quotes. Схема — источник истины для формы запроса:
+// ограничения длины в ней совпадают с maxAuthorLength и maxQuoteLength.
+//
+//go:embed schemas/create_quote.json
+var createQuoteSchemaJSON []byte
+
+// createQuoteSchema — скомпилированная схема тела POST /quotes
+var createQuoteSchema = mustCompileSchema("create_quote.json", createQuoteSchemaJSON)
+
+// schemaPrinter форматирует причины ошибок проверки по схеме
+var schemaPrinter = message.NewPrinter(language.English)
+
+// mustCompileSchema компилирует встроенную схему. Схема поставляется вместе с бинарником,
+// поэтому ошибка компиляции — ошибка сборки, а не окружения.
+func mustCompileSchema(url string, raw []byte) *jsonschema.Schema {
+	const op = "getcitation.mustCompileSchema()"
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s: %v", op, url, err))
+	}
+
+	compiler := jsonschema.NewCompiler()
+
+	err = compiler.AddResource(url, doc)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s: %v", op, url, err))
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s: %v", op, url, err))
+	}
+	return schema
+}
+
+// validateSchema проверяет тело запроса по схеме до декодирования в структуру, поэтому ловит и то,
+// что encoding/json пропустил бы молча (например, число вместо строки). Некорректный JSON
+// возвращается как *JSONError, нарушения схемы — как *ValidationError, где поле — JSON Pointer
+// до значения (например, /author).
+func validateSchema(schema *jsonschema.Schema, body []byte) error {
+	const op = "getcitation.validateSchema()"
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, newJSONError(err, body))
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	schemaErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var v validator
+	v.schema(schemaErr)
+
+	// Причины от схемы приходят в порядке обхода свойств объекта, который меняется от запуска к запуску
+	slices.SortStableFunc(v.fields, func(a, b FieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return fmt.Errorf("%s: %w", op, v.err())
+}
+
+// schema собирает конечные причины ошибки проверки по схеме. Обязательные поля, которых нет,
+// перечисляются по одному, чтобы у каждого был свой путь.
+func (v *validator) schema(err *jsonschema.ValidationError) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			v.schema(cause)
+		}
+		return
+	}
+
+	if required, ok := err.ErrorKind.(*kind.Required); ok {
+		for _, missing := range required.Missing {
+			v.fields = append(v.fields, FieldError{Field: pointer(err.InstanceLocation) + pointer([]string{missing}), Reason: "required"})
+		}
+		return
+	}
+
+	v.fields = append(v.fields, FieldError{Field: pointer(err.InstanceLocation), Reason: err.ErrorKind.LocalizedString(schemaPrinter)})
+}
+
+// pointer собирает JSON Pointer из сегментов пути
+func pointer(location []string) string {
+	var sb strings.Builder
+
+	for _, segment := range location {
+		sb.WriteString("/")
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(segment))
+	}
+	return sb.String()
+}
+
+// JSONError описывает тело запроса, которое не разбирается как JSON или не подходит по типам: где
+// разбор остановился и почему. Offset — смещение в байтах от начала тела, -1, если оно неизвестно.
+// Текст ошибки предназначен клиенту.
+type JSONError struct {
+	Offset int64
+	Reason string
+}
+
+func (e *JSONError) Error() string {
+	if e.Offset < 0 {
+		return "invalid JSON: " + e.Reason
+	}
+	return fmt.Sprintf("invalid JSON at offset %d: %s", e.Offset, e.Reason)
+}
+
+// newJSONError переводит ошибку разбора body из encoding/json в *JSONError с позицией и, для
+// несовпадения типов, ожидаемым типом поля.
+func newJSONError(err error, body []byte) *JSONError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &JSONError{Offset: syntaxErr.Offset, Reason: syntaxErr.Error()}
+
+	case errors.As(err, &typeErr):
+		reason := fmt.Sprintf("expected %s", typeErr.Type)
+		if typeErr.Field != "" {
+			reason += " for field " + typeErr.Field
+		}
+		return &JSONError{Offset: typeErr.Offset, Reason: reason + ", got " + typeErr.Value}
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &JSONError{Offset: int64(len(body)), Reason: "unexpected end of input"}
+
+	case errors.Is(err, io.EOF):
+		return &JSONError{Offset: 0, Reason: "body is empty"}
+
+	default:
+		return &JSONError{Offset: -1, Reason: err.Error()}
+	}
+}
@@ -0,0 +1,68 @@
+package getcitation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCreateQuoteMalformedJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"trailing comma", `{"author":"a",}`, "invalid JSON at offset 15: invalid character '}' looking for beginning of object key string"},
+		{"bad literal", `{"author": tru}`, "invalid JSON at offset 15: invalid character '}' in literal true (expecting 'e')"},
+		{"truncated object", `{"author":"a"`, "invalid JSON at offset 13: unexpected end of input"},
+		{"truncated array", `[1,2`, "invalid JSON at offset 4: unexpected end of input"},
+		{"whitespace only", ` `, "invalid JSON at offset 0: body is empty"},
+		{"trailing value", `{"author":"a","quote":"b"}{}`, "invalid JSON: invalid character after top-level value"},
+	}
+
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, http.MethodPost, "/quotes", tt.body, "Content-Type", "application/json")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if response.Status.Message != errBadRequest || response.Message != tt.want {
+				t.Errorf("response = %+v, want %q with %q", response, errBadRequest, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJSONErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"number for string", `{"author":42,"quote":"b"}`, "invalid JSON at offset 12: expected string for field author, got number"},
+		{"object for string", `{"author":"a","quote":{"text":"b"}}`, "invalid JSON at offset 23: expected string for field quote, got object"},
+		{"array for object", `["a","b"]`, "invalid JSON at offset 1: expected getcitation.CreateQuoteRequest, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateQuoteRequest
+			err := json.Unmarshal([]byte(tt.body), &req)
+
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("json.Unmarshal() error = %v, want *json.UnmarshalTypeError", err)
+			}
+
+			if got := newJSONError(err, []byte(tt.body)).Error(); got != tt.want {
+				t.Errorf("newJSONError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package getcitation
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// seedQuotesJSON — встроенный набор цитат, которым заполняется пустая БД при SEED_ON_EMPTY=true
+//
+//go:embed seed/quotes.json
+var seedQuotesJSON []byte
+
+// seedQuote — цитата встроенного набора
+type seedQuote struct {
+	Author   string `json:"author"`
+	Quote    string `json:"quote"`
+	Language string `json:"language"`
+	Source   string `json:"source"`
+}
+
+// Seed добавляет встроенный набор цитат, если в БД нет ни одной цитаты, и возвращает число добавленных.
+// Цитаты проходят тот же путь, что и импорт: проверку, запрещенные слова и одну транзакцию хранилища.
+// Если цитаты уже есть, Seed ничего не делает и возвращает 0.
+func (a App) Seed(ctx context.Context) (int, error) {
+	const op = "getcitation.Seed()"
+
+	handlers := a.Server.Handlers
+
+	count, err := handlers.Getter.CountQuotes(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if count > 0 {
+		a.Log.Info(
+			"в БД уже есть цитаты, встроенный набор не добавляется",
+			slog.String("op", op),
+			slog.Int("quotes", count),
+		)
+		return 0, nil
+	}
+
+	var quotes []seedQuote
+	err = json.Unmarshal(seedQuotesJSON, &quotes)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows := make([]ImportRow, 0, len(quotes))
+	for i, quote := range quotes {
+		rows = append(rows, ImportRow{
+			Line:     i + 1,
+			Author:   quote.Author,
+			Quote:    quote.Quote,
+			Language: quote.Language,
+			Source:   quote.Source,
+		})
+	}
+
+	results, err := handlers.Manipulator.ImportQuotes(ctx, rows, ImportOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seeded := 0
+	for _, result := range results {
+		if result.Status == ImportInserted {
+			seeded++
+			continue
+		}
+
+		a.Log.Warn(
+			"цитата встроенного набора пропущена",
+			slog.String("op", op),
+			slog.Int("line", result.Line),
+			slog.String("status", result.Status),
+			slog.String("reason", result.Reason),
+		)
+	}
+
+	a.Log.Info(
+		"БД заполнена встроенным набором цитат",
+		slog.String("op", op),
+		slog.Int("quotes", seeded),
+	)
+	return seeded, nil
+}
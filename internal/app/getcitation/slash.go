@@ -0,0 +1,41 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// TrimSlash перенаправляет запросы с завершающим / на тот же путь без него: канонический вид всех
+// маршрутов — без завершающего /. Иначе /quotes/ попадал бы в шаблон /quotes/{id} с пустым ID и
+// получал 400 вместо списка цитат. GET и HEAD перенаправляются с 301, остальные методы — с 308,
+// чтобы клиент повторил запрос тем же методом и с тем же телом. Параметры запроса сохраняются.
+func (h Handlers) TrimSlash(next http.Handler) http.Handler {
+	const op = "getcitation.Transport.TrimSlash()"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || !strings.HasSuffix(r.URL.Path, "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Ведущие / тоже схлопываются: Location вида //example.com браузер понял бы как другой хост
+		target := *r.URL
+		target.Path = "/" + strings.Trim(r.URL.Path, "/")
+		target.RawPath = ""
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+
+		h.Log.Debug(
+			"перенаправление на путь без завершающего /",
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+			slog.String("location", target.Path),
+		)
+
+		http.Redirect(w, r, target.RequestURI(), code)
+	})
+}
@@ -0,0 +1,104 @@
+package getcitation
+
+import (
+	"net/http"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+// canonicalPaths — маршруты в каноническом виде, без завершающего /
+var canonicalPaths = []string{
+	"/quotes",
+	"/quotes/1",
+	"/quotes/all",
+	"/quotes/random",
+	"/quotes/random.txt",
+	"/quotes/random.svg",
+	"/quotes/stream",
+	"/quotes/count",
+	"/quotes/search",
+	"/quotes/batch",
+	"/quotes/exists",
+	"/quotes/import",
+	"/quotes/recent",
+	"/quotes/feed.rss",
+	"/quotes/export",
+	"/quotes/1/restore",
+	"/quotes/1/like",
+	"/quotes/1/similar",
+	"/quotes/1/categories",
+	"/quotes/slug/life-is-simple",
+	"/stats",
+	"/stats/text",
+	"/authors",
+	"/categories",
+	"/audit",
+	"/openapi.json",
+	"/admin/readonly",
+	"/admin/reload-filters",
+	"/ready",
+	"/version",
+}
+
+func TestTrimSlashRedirects(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	for _, path := range canonicalPaths {
+		t.Run(path, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, path+"/?limit=5", "")
+			if w.Code != http.StatusMovedPermanently {
+				t.Fatalf("GET %s/ status = %d, want %d", path, w.Code, http.StatusMovedPermanently)
+			}
+			if location := w.Header().Get("Location"); location != path+"?limit=5" {
+				t.Errorf("GET %s/ Location = %q, want %q", path, location, path+"?limit=5")
+			}
+		})
+	}
+}
+
+func TestTrimSlashMethods(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	tests := []struct {
+		method       string
+		target       string
+		wantCode     int
+		wantLocation string
+	}{
+		{http.MethodHead, "/quotes/", http.StatusMovedPermanently, "/quotes"},
+		{http.MethodPost, "/quotes/", http.StatusPermanentRedirect, "/quotes"},
+		{http.MethodDelete, "/quotes/1/", http.StatusPermanentRedirect, "/quotes/1"},
+		{http.MethodGet, "/quotes///", http.StatusMovedPermanently, "/quotes"},
+		// Несколько ведущих / не должны превратиться в ссылку на другой хост
+		{http.MethodGet, "//example.com/", http.StatusMovedPermanently, "/example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			w := serve(handler, tt.method, tt.target, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if location := w.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestCanonicalPathsNotRedirected(t *testing.T) {
+	store := &fakeStore{}
+	store.add(storage.Quote{Author: "Confucius", Quote: "Life is simple"})
+
+	handler := newTestApp(t, testConfig(), store)
+
+	for _, path := range []string{"/quotes", "/quotes/1", "/quotes/recent", "/openapi.json", "/ready", "/version"} {
+		t.Run(path, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, path, "")
+			if w.Code != http.StatusOK {
+				t.Errorf("GET %s status = %d, want %d: %s", path, w.Code, http.StatusOK, w.Body)
+			}
+		})
+	}
+}
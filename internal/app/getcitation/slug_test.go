@@ -0,0 +1,68 @@
+package getcitation
+
+import (
+	"net/http"
+	"testing"
+
+	"getcitation/internal/storage"
+)
+
+func TestGetQuoteBySlug(t *testing.T) {
+	store := &fakeStore{}
+	store.add(
+		storage.Quote{Author: "Seneca", Quote: "Luck is what happens", Slug: "seneca-luck-is-what-happens-1"},
+		storage.Quote{Author: "Confucius", Quote: "Life is simple", Slug: "confucius-life-is-simple-2"},
+	)
+
+	handler := newTestApp(t, testConfig(), store)
+
+	w := serve(handler, http.MethodGet, "/quotes/slug/confucius-life-is-simple-2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var response GetQuoteByIDResponse
+	decode(t, w, &response)
+	if response.Quote.ID != 2 || response.Quote.Author != "Confucius" {
+		t.Errorf("quote = %+v, want Confucius with id 2", response.Quote)
+	}
+
+	// Slug, совпадающий с именем действия над цитатой, все равно ищется как slug
+	for _, slug := range []string{"unknown-quote-3", "like"} {
+		if w := serve(handler, http.MethodGet, "/quotes/slug/"+slug, ""); w.Code != http.StatusNotFound {
+			t.Errorf("GET /quotes/slug/%s status = %d, want %d: %s", slug, w.Code, http.StatusNotFound, w.Body)
+		}
+	}
+
+	w = serve(handler, http.MethodDelete, "/quotes/slug/confucius-life-is-simple-2", "")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("DELETE status = %d, Allow = %q, want %d with %q", w.Code, w.Header().Get("Allow"), http.StatusMethodNotAllowed, http.MethodGet)
+	}
+}
+
+func TestQuoteActionRoutes(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	// Каждое действие попадает в свой обработчик: это видно по методу, который он разрешает
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/quotes/1/restore", http.MethodPost},
+		{"/quotes/1/like", http.MethodPost},
+		{"/quotes/1/similar", http.MethodGet},
+		{"/quotes/1/categories", http.MethodPost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := serve(handler, http.MethodPut, tt.path, "")
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusMethodNotAllowed, w.Body)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.allow {
+				t.Errorf("Allow = %q, want %q", allow, tt.allow)
+			}
+		})
+	}
+}
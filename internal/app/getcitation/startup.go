@@ -0,0 +1,101 @@
+package getcitation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"getcitation/internal/storage"
+)
+
+// Пределы паузы между проверками хранилища при запуске: пауза удваивается от минимальной до максимальной
+const (
+	startupMinBackoff time.Duration = 100 * time.Millisecond
+	startupMaxBackoff time.Duration = 5 * time.Second
+)
+
+// startupRetryAfter — через сколько секунд клиенту стоит повторить запрос, пока сервис запускается
+const startupRetryAfter int = 1
+
+// messageStarting — сообщение ответа 503 до готовности хранилища
+const messageStarting string = "Service is starting, retry later"
+
+// StartupGate отвечает 503 с Retry-After на все запросы, включая /ready, пока хранилище не ответило
+// после запуска (см. WaitForStorage). Так балансировщик при поэтапном обновлении не шлет трафик на
+// экземпляр, который принял соединение, но еще не может его обслужить.
+func (h Handlers) StartupGate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.Started.Load() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(startupRetryAfter))
+		w.Header().Set("Cache-Control", cacheControlNoStore)
+
+		h.writeJSON(w, http.StatusServiceUnavailable, Error{
+			Status: Status{
+				Code:    http.StatusServiceUnavailable,
+				Message: errServiceUnavailable,
+			},
+			Message: messageStarting,
+		})
+	})
+}
+
+// WaitForStorage проверяет хранилище с растущей паузой, пока оно не ответит или не отменится ctx,
+// и после первой успешной проверки открывает StartupGate. Отстающая схема БД (READY_CHECK_MIGRATIONS=true)
+// тоже открывает ворота: хранилище отвечает, а о миграциях сообщает /ready.
+func (h Handlers) WaitForStorage(ctx context.Context) {
+	const op = "getcitation.Transport.WaitForStorage()"
+
+	backoff := startupMinBackoff
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, readyTimeout)
+		err := h.Readiness.Ready(checkCtx)
+		cancel()
+
+		if err == nil {
+			h.Started.Store(true)
+
+			h.Log.Info(
+				"хранилище доступно, сервис принимает запросы",
+				slog.String("op", op),
+			)
+			return
+		}
+
+		// Хранилище отвечает, но схема отстает: ворота открываются, чтобы /ready объяснил причину 503,
+		// а не отвечал, что сервис все еще запускается
+		var migrationErr *storage.MigrationError
+		if errors.As(err, &migrationErr) {
+			h.Started.Store(true)
+
+			h.Log.Warn(
+				"хранилище доступно, но миграции не применены: /ready отвечает 503",
+				slog.String("op", op),
+				slog.Any("error", err),
+			)
+			return
+		}
+
+		h.Log.Warn(
+			"хранилище недоступно, запросы отклоняются с 503",
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.Duration("retry_in", backoff),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, startupMaxBackoff)
+	}
+}
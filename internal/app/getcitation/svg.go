@@ -0,0 +1,240 @@
+package getcitation
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"getcitation/internal/storage"
+)
+
+// Размеры карточки цитаты в SVG. Ширина символа оценивается долей размера шрифта: точно ее знает только
+// браузер, поэтому строки переносятся с запасом.
+const (
+	svgDefaultWidth   int     = 600
+	svgMinWidth       int     = 200
+	svgMaxWidth       int     = 2000
+	svgPadding        int     = 24
+	svgFontSize       int     = 18
+	svgAuthorFontSize int     = 14
+	svgLineHeight     int     = 26
+	svgCharWidth      float64 = 0.55
+)
+
+// Сообщения ответов SVG
+const (
+	messageMalformedWidth string = "width parameter must be an integer between %d and %d"
+	messageMalformedTheme string = "theme parameter must be light or dark"
+	messageMalformedColor string = "bg, fg and accent parameters must be hex colors like fff or 1e90ff"
+)
+
+// svgTheme — цвета карточки: фон, текст цитаты и автор с рамкой
+type svgTheme struct {
+	Background string
+	Foreground string
+	Accent     string
+}
+
+// svgThemes — встроенные темы, выбираемые параметром theme
+var svgThemes = map[string]svgTheme{
+	"light": {Background: "#ffffff", Foreground: "#24292f", Accent: "#57606a"},
+	"dark":  {Background: "#0d1117", Foreground: "#e6edf3", Accent: "#8b949e"},
+}
+
+// hexColor — цвет в параметрах bg, fg и accent: 3 или 6 шестнадцатеричных цифр без #
+var hexColor = regexp.MustCompile(`^(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// svgOptions — оформление карточки цитаты
+type svgOptions struct {
+	Width int
+	Theme svgTheme
+}
+
+// parseSVGOptions разбирает параметры width, theme и цвета bg, fg, accent, которые перекрывают цвета темы.
+// Текст ошибки предназначен клиенту.
+func parseSVGOptions(query url.Values) (svgOptions, error) {
+	options := svgOptions{
+		Width: svgDefaultWidth,
+		Theme: svgThemes["light"],
+	}
+
+	if raw := query.Get("width"); raw != "" {
+		width, err := strconv.Atoi(raw)
+		if err != nil || width < svgMinWidth || width > svgMaxWidth {
+			return svgOptions{}, fmt.Errorf(messageMalformedWidth, svgMinWidth, svgMaxWidth)
+		}
+		options.Width = width
+	}
+
+	if name := query.Get("theme"); name != "" {
+		theme, ok := svgThemes[name]
+		if !ok {
+			return svgOptions{}, errors.New(messageMalformedTheme)
+		}
+		options.Theme = theme
+	}
+
+	for param, color := range map[string]*string{
+		"bg":     &options.Theme.Background,
+		"fg":     &options.Theme.Foreground,
+		"accent": &options.Theme.Accent,
+	} {
+		raw := query.Get(param)
+		if raw == "" {
+			continue
+		}
+		if !hexColor.MatchString(raw) {
+			return svgOptions{}, errors.New(messageMalformedColor)
+		}
+		*color = "#" + raw
+	}
+
+	return options, nil
+}
+
+// wrapText разбивает текст на строки не длиннее limit символов по пробелам. Слово длиннее строки
+// режется на части, чтобы не вылезать за край карточки.
+func wrapText(text string, limit int) []string {
+	var lines []string
+	var line strings.Builder
+
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > limit {
+			if line.Len() > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:limit]))
+			word = string(runes[limit:])
+		}
+
+		length := utf8.RuneCountInString(line.String())
+		if length > 0 && length+1+utf8.RuneCountInString(word) > limit {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// renderQuoteSVG рисует карточку с текстом цитаты, перенесенным по ширине, и автором. Текст экранируется,
+// поэтому цитата с <, & или кавычками не ломает разметку и не внедряет в нее элементы.
+func renderQuoteSVG(quote storage.Quote, options svgOptions) string {
+	limit := max(int(float64(options.Width-2*svgPadding)/(svgCharWidth*float64(svgFontSize))), 1)
+	lines := wrapText("“"+quote.Quote+"”", limit)
+
+	height := 2*svgPadding + len(lines)*svgLineHeight + svgLineHeight
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		options.Width, height, options.Width, height, html.EscapeString(quote.Quote+" — "+quote.Author))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, `<rect x="0.5" y="0.5" width="%d" height="%d" rx="6" fill="%s" stroke="%s"/>`,
+		options.Width-1, height-1, options.Theme.Background, options.Theme.Accent)
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, `<text font-family="Georgia, serif" font-size="%d" fill="%s">`, svgFontSize, options.Theme.Foreground)
+	b.WriteString("\n")
+	for i, line := range lines {
+		fmt.Fprintf(&b, `<tspan x="%d" y="%d">%s</tspan>`, svgPadding, svgPadding+svgFontSize+i*svgLineHeight, html.EscapeString(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("</text>\n")
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end" font-family="Helvetica, Arial, sans-serif" font-size="%d" fill="%s">— %s</text>`,
+		options.Width-svgPadding, height-svgPadding, svgAuthorFontSize, options.Theme.Accent, html.EscapeString(quote.Author))
+	b.WriteString("\n</svg>\n")
+
+	return b.String()
+}
+
+// GetRandomQuoteSVG обрабатывает HTTP GET запрос на случайную цитату в виде картинки SVG для README и писем.
+// Ошибки, как у /quotes/random.txt, приходят обычным текстом.
+func (h Handlers) GetRandomQuoteSVG(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetRandomQuoteSVG()"
+
+	if r.Method != http.MethodGet {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet)
+		h.writeText(w, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	options, err := parseSVGOptions(r.URL.Query())
+	if err != nil {
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lang := h.preferredLanguage(r)
+
+	quote, err := h.Getter.GetRandomQuote(r.Context(), "", lang)
+	if errors.Is(err, ErrNoQuotesFound) && lang != "" {
+		quote, err = h.Getter.GetRandomQuote(r.Context(), "", "")
+	}
+	if err != nil {
+		if errors.Is(err, ErrNoQuotesFound) {
+			h.Log.Error(
+				errNotFound,
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.String("path", r.URL.Path),
+			)
+
+			h.writeText(w, http.StatusNotFound, messageQuotesNotFound)
+			return
+		}
+		code, message := internalStatus(err)
+
+		h.Log.Error(
+			message,
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeText(w, code, message)
+		return
+	}
+
+	body := renderQuoteSVG(quote, options)
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+	w.Header().Add("Vary", "Accept-Language")
+	if quote.Language != "" {
+		w.Header().Set("Content-Language", quote.Language)
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+
+	w.Write([]byte(body))
+}
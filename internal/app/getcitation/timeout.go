@@ -0,0 +1,38 @@
+package getcitation
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// Timeout ограничивает время обработки запроса значением REQUEST_TIMEOUT. По истечении клиент получает
+// 503 в обычном формате ошибки, а контекст запроса отменяется, и запросы к БД прерываются. Потоковые
+// маршруты (exempt) не ограничиваются: http.TimeoutHandler буферизует ответ и не дает сбрасывать его
+// клиенту по частям. С нулевым REQUEST_TIMEOUT пропускает все запросы как есть.
+func (h Handlers) Timeout(next http.Handler, exempt ...string) http.Handler {
+	if h.Config.RequestTimeout <= 0 {
+		return next
+	}
+
+	body, _ := json.Marshal(Error{
+		Status: Status{
+			Code:    http.StatusServiceUnavailable,
+			Message: errServiceUnavailable,
+		},
+		Message: messageRequestTimeout,
+	})
+	limited := http.TimeoutHandler(next, h.Config.RequestTimeout, string(body))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(exempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// TimeoutHandler пишет тело ошибки без Content-Type. Если обработчик успел ответить сам,
+		// его заголовки копируются поверх этого, так что Content-Type ответа остается прежним.
+		w.Header().Set("Content-Type", "application/json")
+		limited.ServeHTTP(w, r)
+	})
+}
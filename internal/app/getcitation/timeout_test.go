@@ -0,0 +1,76 @@
+package getcitation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.RequestTimeout = 20 * time.Millisecond
+
+	h := Handlers{Log: testLogger(), Config: cfg}
+
+	cancelled := make(chan error, 1)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			cancelled <- r.Context().Err()
+		case <-time.After(200 * time.Millisecond):
+			cancelled <- nil
+			w.Write([]byte("finished"))
+		}
+	})
+
+	handler := h.Timeout(slow, "/quotes/stream")
+
+	t.Run("slow handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", contentType)
+		}
+
+		var response Error
+		decode(t, w, &response)
+		if response.Status.Message != errServiceUnavailable || response.Message != messageRequestTimeout {
+			t.Errorf("response = %+v, want %q with %q", response, errServiceUnavailable, messageRequestTimeout)
+		}
+
+		if err := <-cancelled; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("handler context error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("exempt route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes/stream", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "finished" {
+			t.Fatalf("response = %d %q, want 200 %q", w.Code, w.Body, "finished")
+		}
+		if err := <-cancelled; err != nil {
+			t.Errorf("handler context error = %v, want nil", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := Handlers{Log: testLogger(), Config: testConfig()}
+
+		w := httptest.NewRecorder()
+		h.Timeout(slow).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "finished" {
+			t.Fatalf("response = %d %q, want 200 %q", w.Code, w.Body, "finished")
+		}
+		<-cancelled
+	})
+}
@@ -0,0 +1,71 @@
+package getcitation
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateQuoteUpsert(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	body := `{"author":"Confucius","quote":"Life is simple"}`
+
+	created := serve(handler, http.MethodPost, "/quotes?upsert=true", body)
+	if created.Code != http.StatusCreated {
+		t.Fatalf("upsert of a new quote status = %d, want %d: %s", created.Code, http.StatusCreated, created.Body)
+	}
+	var first CreateQuoteResponse
+	decode(t, created, &first)
+	if first.Existing || first.ID == 0 || first.Status.Code != http.StatusCreated {
+		t.Errorf("upsert of a new quote = %+v, want a new id with status %d", first, http.StatusCreated)
+	}
+
+	repeated := serve(handler, http.MethodPost, "/quotes?upsert=true", body)
+	if repeated.Code != http.StatusOK {
+		t.Fatalf("upsert of an existing quote status = %d, want %d: %s", repeated.Code, http.StatusOK, repeated.Body)
+	}
+	var second CreateQuoteResponse
+	decode(t, repeated, &second)
+	if !second.Existing || second.ID != first.ID {
+		t.Errorf("upsert of an existing quote = %+v, want id %d with existing", second, first.ID)
+	}
+
+	// Без upsert дубликат по-прежнему отклоняется, а новая цитата добавляется с 200
+	if w := serve(handler, http.MethodPost, "/quotes", body); w.Code != http.StatusConflict {
+		t.Errorf("duplicate without upsert status = %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+	if w := serve(handler, http.MethodPost, "/quotes?upsert=false", `{"author":"Seneca","quote":"Luck"}`); w.Code != http.StatusOK {
+		t.Errorf("new quote with upsert=false status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+}
+
+func TestCreateQuoteUpsertRejected(t *testing.T) {
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	body := `{"author":"Confucius","quote":"Life is simple"}`
+
+	tests := []struct {
+		name    string
+		target  string
+		headers []string
+		message string
+	}{
+		{"malformed", "/quotes?upsert=maybe", nil, messageMalformedUpsert},
+		{"with idempotency key", "/quotes?upsert=true", []string{headerIdempotencyKey, "key-1"}, messageUpsertConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, http.MethodPost, tt.target, body, tt.headers...)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+
+			var response Error
+			decode(t, w, &response)
+			if response.Message != tt.message {
+				t.Errorf("message = %q, want %q", response.Message, tt.message)
+			}
+		})
+	}
+}
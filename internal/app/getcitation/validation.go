@@ -0,0 +1,170 @@
+package getcitation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Ограничения полей цитаты — совпадают с размерами столбцов таблицы quotes
+const (
+	maxAuthorLength int = 100
+	maxQuoteLength  int = 250
+	// maxLanguageLength — длина столбца language, с запасом для тегов BCP 47 с расширениями
+	maxLanguageLength int = 35
+	maxSourceLength   int = 500
+)
+
+// maxCategoryLength — длина столбца name таблицы categories
+const maxCategoryLength int = 100
+
+// Причины, по которым поле не прошло проверку
+const (
+	reasonRequired string = "must not be empty"
+	reasonTooLong  string = "must be at most %d characters"
+	reasonLanguage string = "must be a valid BCP 47 language tag"
+)
+
+// FieldError описывает поле запроса, не прошедшее проверку, и причину
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError перечисляет все поля запроса, не прошедшие проверку, а не только первое
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, field.Field+" "+field.Reason)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// validator собирает ошибки проверки полей
+type validator struct {
+	fields []FieldError
+}
+
+// required проверяет, что поле содержит что-то кроме пробелов
+func (v *validator) required(field string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fields = append(v.fields, FieldError{Field: field, Reason: reasonRequired})
+	}
+}
+
+// maxLength проверяет длину поля в символах
+func (v *validator) maxLength(field string, value string, limit int) {
+	if utf8.RuneCountInString(value) > limit {
+		v.fields = append(v.fields, FieldError{Field: field, Reason: fmt.Sprintf(reasonTooLong, limit)})
+	}
+}
+
+// languageTag проверяет, что непустое поле является корректным тегом языка BCP 47
+func (v *validator) languageTag(field string, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := language.Parse(value); err != nil {
+		v.fields = append(v.fields, FieldError{Field: field, Reason: reasonLanguage})
+	}
+}
+
+// err возвращает *ValidationError со всеми собранными ошибками или nil, если их нет
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// validateQuote проверяет поля новой цитаты
+func validateQuote(author string, quote string, lang string, source string) error {
+	var v validator
+
+	v.required("author", author)
+	v.maxLength("author", author, maxAuthorLength)
+	v.required("quote", quote)
+	v.maxLength("quote", quote, maxQuoteLength)
+	v.maxLength("language", lang, maxLanguageLength)
+	v.languageTag("language", lang)
+	v.maxLength("source", source, maxSourceLength)
+
+	return v.err()
+}
+
+// messageBodyTooLarge — сообщение об ответе 413 на слишком большое тело запроса: лимит в байтах
+const messageBodyTooLarge string = "Request body must be at most %d bytes"
+
+// limitBody ограничивает тело запроса limit байтами (MAX_BODY_BYTES или MAX_IMPORT_BYTES): чтение сверх
+// лимита вернет *http.MaxBytesError, а сервер закроет соединение после ответа, не дочитывая тело.
+func limitBody(w http.ResponseWriter, r *http.Request, limit int64) {
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+}
+
+// rejectLargeBody отвечает 413, если err — ошибка чтения тела сверх limitBody, и сообщает, отправлен ли ответ
+func (h Handlers) rejectLargeBody(w http.ResponseWriter, r *http.Request, op string, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+
+	h.Log.Error(
+		errBodyTooLarge,
+		slog.String("op", op),
+		slog.Int64("limit", maxBytesErr.Limit),
+		slog.String("path", r.URL.Path),
+	)
+
+	h.writeJSON(w, http.StatusRequestEntityTooLarge, Error{
+		Status: Status{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: errBodyTooLarge,
+		},
+		Message: fmt.Sprintf(messageBodyTooLarge, maxBytesErr.Limit),
+	})
+
+	return true
+}
+
+// messageFilterTooLong — сообщение об ответе 400 на слишком длинный фильтр: имя параметра и MAX_FILTER_LENGTH
+const messageFilterTooLong string = "%s parameter must be at most %d characters"
+
+// rejectLongFilter отвечает 400, если хоть одно значение параметра param длиннее MAX_FILTER_LENGTH символов,
+// и сообщает, отправлен ли ответ. Проверка идет до обращения к БД; в журнал пишется только длина значения,
+// а не оно само.
+func (h Handlers) rejectLongFilter(w http.ResponseWriter, r *http.Request, op string, param string, values ...string) bool {
+	for _, value := range values {
+		// Длина в байтах не меньше длины в символах, поэтому короткие значения не пересчитываются
+		if len(value) <= h.Config.MaxFilterLength || utf8.RuneCountInString(value) <= h.Config.MaxFilterLength {
+			continue
+		}
+
+		h.Log.Error(
+			errBadRequest,
+			slog.String("op", op),
+			slog.String("param", param),
+			slog.Int("length", len(value)),
+			slog.String("path", r.URL.Path),
+		)
+
+		h.writeJSON(w, http.StatusBadRequest, Error{
+			Status: Status{
+				Code:    http.StatusBadRequest,
+				Message: errBadRequest,
+			},
+			Message: fmt.Sprintf(messageFilterTooLong, param, h.Config.MaxFilterLength),
+		})
+
+		return true
+	}
+	return false
+}
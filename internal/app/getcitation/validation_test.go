@@ -0,0 +1,108 @@
+package getcitation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuote(t *testing.T) {
+	tooLong := func(field string, limit int) FieldError {
+		return FieldError{Field: field, Reason: fmt.Sprintf(reasonTooLong, limit)}
+	}
+
+	tests := []struct {
+		name   string
+		author string
+		quote  string
+		lang   string
+		source string
+		want   []FieldError
+	}{
+		{"valid", "Confucius", "Life is simple", "en", "Analects", nil},
+		{"valid without optional fields", "Confucius", "Life is simple", "", "", nil},
+		{"long in bytes but not in characters", strings.Repeat("я", maxAuthorLength), "Life is simple", "", "", nil},
+		{
+			"all empty",
+			"", "", "", "",
+			[]FieldError{{"author", reasonRequired}, {"quote", reasonRequired}},
+		},
+		{
+			"only spaces",
+			"  ", "\t", "", "",
+			[]FieldError{{"author", reasonRequired}, {"quote", reasonRequired}},
+		},
+		{
+			"every field too long",
+			strings.Repeat("a", maxAuthorLength+1), strings.Repeat("q", maxQuoteLength+1), "en-" + strings.Repeat("x", maxLanguageLength), strings.Repeat("s", maxSourceLength+1),
+			[]FieldError{tooLong("author", maxAuthorLength), tooLong("quote", maxQuoteLength), tooLong("language", maxLanguageLength), {"language", reasonLanguage}, tooLong("source", maxSourceLength)},
+		},
+		{
+			"empty author and bad language",
+			"", "Life is simple", "not a tag", "",
+			[]FieldError{{"author", reasonRequired}, {"language", reasonLanguage}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateQuote(tt.author, tt.quote, tt.lang, tt.source)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("validateQuote() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("validateQuote() error = %v, want *ValidationError", err)
+			}
+			if !slices.Equal(validationErr.Fields, tt.want) {
+				t.Errorf("validateQuote() fields = %v, want %v", validationErr.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateQuoteReportsEveryInvalidField(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		// Пустые строки отклоняет уже JSON Schema, поля в ответе — указатели JSON
+		{"schema", `{"author":"","quote":""}`, []string{"/author", "/quote"}},
+		// Пробелы и некорректный тег языка проходят схему, но не проверку сервиса
+		{"service", `{"author":" ","quote":" ","language":"not a tag"}`, []string{"author", "quote", "language"}},
+	}
+
+	handler := newTestApp(t, testConfig(), &fakeStore{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, http.MethodPost, "/quotes", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+
+			var response ValidationErrorResponse
+			decode(t, w, &response)
+
+			if response.Message != messageValidationFailed {
+				t.Errorf("message = %q, want %q", response.Message, messageValidationFailed)
+			}
+
+			var fields []string
+			for _, field := range response.Errors {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, tt.want) {
+				t.Errorf("errors = %v, want fields %v", response.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package getcitation
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo описывает собранный бинарник. Version, Commit и BuildTime подставляются при сборке через
+// -ldflags (см. cmd/getcitation); если их не передали, Commit и BuildTime берутся из сведений VCS,
+// которые go build встраивает сам.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Значения BuildInfo, если сведения о сборке недоступны
+const (
+	buildVersionDev string = "dev"
+	buildUnknown    string = "unknown"
+)
+
+// WithDefaults дополняет незаданные поля сведениями VCS из бинарника, а оставшиеся пустыми — заглушками
+func (b BuildInfo) WithDefaults() BuildInfo {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && b.Commit == "":
+				b.Commit = setting.Value
+			case setting.Key == "vcs.time" && b.BuildTime == "":
+				b.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if b.Version == "" {
+		b.Version = buildVersionDev
+	}
+	if b.Commit == "" {
+		b.Commit = buildUnknown
+	}
+	if b.BuildTime == "" {
+		b.BuildTime = buildUnknown
+	}
+	b.GoVersion = runtime.Version()
+
+	return b
+}
+
+// LogValue выводит сведения о сборке в журнал одной группой
+func (b BuildInfo) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("version", b.Version),
+		slog.String("commit", b.Commit),
+		slog.String("build_time", b.BuildTime),
+		slog.String("go_version", b.GoVersion),
+	)
+}
+
+// VersionResponse описывает формат ответа со сведениями о сборке
+type VersionResponse struct {
+	Status Status    `json:"status"`
+	Build  BuildInfo `json:"build"`
+}
+
+// GetVersion обрабатывает HTTP GET запрос сведений о сборке: версия, коммит и время сборки. Помогает
+// после выкладки убедиться, какой именно бинарник запущен.
+func (h Handlers) GetVersion(w http.ResponseWriter, r *http.Request) {
+	const op = "getcitation.Transport.GetVersion()"
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.Log.Error(
+			errMethodNotAllowed,
+			slog.String("op", op),
+			slog.String("path", r.URL.Path),
+		)
+
+		setAllow(w, http.MethodGet, http.MethodHead)
+		h.writeJSON(w, http.StatusMethodNotAllowed, Error{
+			Status: Status{
+				Code:    http.StatusMethodNotAllowed,
+				Message: errMethodNotAllowed,
+			},
+		})
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheControlNoStore)
+
+	h.writeJSON(w, http.StatusOK, VersionResponse{
+		Status: Status{
+			Code: http.StatusOK,
+		},
+		Build: h.Build,
+	})
+}
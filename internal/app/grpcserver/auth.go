@@ -0,0 +1,71 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"getcitation/internal/app/getcitation"
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/pb"
+)
+
+// mutating — методы, изменяющие цитаты. Как и изменяющие HTTP методы, они доступны только роли admin.
+var mutating = map[string]bool{
+	pb.Quotes_CreateQuote_FullMethodName: true,
+	pb.Quotes_DeleteQuote_FullMethodName: true,
+}
+
+// isMutating сообщает, что метод изменяет данные
+func isMutating(method string) bool {
+	return mutating[method]
+}
+
+// Authenticate — перехватчик, проверяющий bearer-токен (HS256, секрет JWT_SECRET) из метаданных вызова
+// authorization и кладущий его claims в контекст. Как и в HTTP API, чтение доступно любой роли, а
+// изменяющие методы — только роли admin. Без JWT_SECRET пропускает все вызовы.
+func (h Handlers) Authenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	const op = "grpcserver.Handlers.Authenticate()"
+
+	if h.Config.JWTSecret == "" {
+		return handler(ctx, req)
+	}
+
+	var token string
+	found := false
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, found = strings.CutPrefix(values[0], "Bearer ")
+	}
+
+	claims, err := jwt.Parse(strings.TrimSpace(token), []byte(h.Config.JWTSecret), time.Now())
+	if !found || err != nil {
+		h.Log.Error(
+			"неверный или отсутствующий токен",
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.String("method", info.FullMethod),
+		)
+		return nil, status.Error(codes.Unauthenticated, "bearer token is missing or invalid")
+	}
+
+	if isMutating(info.FullMethod) && claims.Role != getcitation.RoleAdmin {
+		h.Log.Error(
+			"недостаточно прав",
+			slog.String("op", op),
+			slog.String("subject", claims.Subject),
+			slog.String("role", claims.Role),
+			slog.String("method", info.FullMethod),
+		)
+		return nil, status.Error(codes.PermissionDenied, "this operation requires the admin role")
+	}
+
+	return handler(getcitation.ContextWithClaims(ctx, claims), req)
+}
@@ -0,0 +1,133 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"getcitation/internal/app/getcitation"
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/pb"
+	"getcitation/internal/storage"
+)
+
+const testSecret = "test-secret"
+
+// withToken добавляет к исходящему вызову bearer-токен с claims, подписанный secret
+func withToken(t *testing.T, claims jwt.Claims, secret string) context.Context {
+	t.Helper()
+
+	token, err := jwt.Sign(claims, []byte(secret))
+	if err != nil {
+		t.Fatalf("jwt.Sign() error = %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthenticate(t *testing.T) {
+	hour := time.Now().Add(time.Hour).Unix()
+
+	admin := jwt.Claims{Subject: "alice", Role: getcitation.RoleAdmin, ExpiresAt: hour}
+	reader := jwt.Claims{Subject: "bob", Role: "reader", ExpiresAt: hour}
+
+	tests := []struct {
+		name       string
+		ctx        func(t *testing.T) context.Context
+		wantRead   codes.Code
+		wantMutate codes.Code
+	}{
+		{"no token", func(t *testing.T) context.Context {
+			return context.Background()
+		}, codes.Unauthenticated, codes.Unauthenticated},
+		{"not bearer", func(t *testing.T) context.Context {
+			token, _ := jwt.Sign(admin, []byte(testSecret))
+			return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token "+token)
+		}, codes.Unauthenticated, codes.Unauthenticated},
+		{"wrong secret", func(t *testing.T) context.Context {
+			return withToken(t, admin, "other-secret")
+		}, codes.Unauthenticated, codes.Unauthenticated},
+		{"expired", func(t *testing.T) context.Context {
+			return withToken(t, jwt.Claims{Role: getcitation.RoleAdmin, ExpiresAt: time.Now().Add(-time.Minute).Unix()}, testSecret)
+		}, codes.Unauthenticated, codes.Unauthenticated},
+		{"reader", func(t *testing.T) context.Context {
+			return withToken(t, reader, testSecret)
+		}, codes.OK, codes.PermissionDenied},
+		{"admin", func(t *testing.T) context.Context {
+			return withToken(t, admin, testSecret)
+		}, codes.OK, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.JWTSecret = testSecret
+
+			client := newTestClient(t, Handlers{
+				Log:         testLogger(),
+				Config:      cfg,
+				Manipulator: fakeManipulator{},
+				Getter:      fakeGetter{quotes: []storage.Quote{{ID: 1, Author: "Seneca", Quote: "While we teach, we learn"}}},
+			})
+
+			ctx := tt.ctx(t)
+
+			_, err := client.ListQuotes(ctx, &pb.ListQuotesRequest{})
+			if got := status.Code(err); got != tt.wantRead {
+				t.Errorf("ListQuotes() code = %s, want %s", got, tt.wantRead)
+			}
+			_, err = client.GetRandomQuote(ctx, &pb.GetRandomQuoteRequest{})
+			if got := status.Code(err); got != tt.wantRead {
+				t.Errorf("GetRandomQuote() code = %s, want %s", got, tt.wantRead)
+			}
+
+			_, err = client.CreateQuote(ctx, &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+			if got := status.Code(err); got != tt.wantMutate {
+				t.Errorf("CreateQuote() code = %s, want %s", got, tt.wantMutate)
+			}
+			_, err = client.DeleteQuote(ctx, &pb.DeleteQuoteRequest{Id: 1})
+			if got := status.Code(err); got != tt.wantMutate {
+				t.Errorf("DeleteQuote() code = %s, want %s", got, tt.wantMutate)
+			}
+		})
+	}
+}
+
+func TestAuthenticatePassesClaims(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWTSecret = testSecret
+
+	var claims jwt.Claims
+
+	client := newTestClient(t, Handlers{
+		Log:         testLogger(),
+		Config:      cfg,
+		Manipulator: fakeManipulator{claims: &claims},
+	})
+
+	ctx := withToken(t, jwt.Claims{Subject: "alice", Role: getcitation.RoleAdmin}, testSecret)
+
+	_, err := client.CreateQuote(ctx, &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	if claims.Subject != "alice" {
+		t.Errorf("service saw subject %q, want %q", claims.Subject, "alice")
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	client := newTestClient(t, Handlers{
+		Log:         testLogger(),
+		Config:      testConfig(),
+		Manipulator: fakeManipulator{},
+	})
+
+	_, err := client.CreateQuote(context.Background(), &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+	if err != nil {
+		t.Errorf("CreateQuote() without JWT_SECRET error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,218 @@
+// Пакет grpcserver предоставляет gRPC API поверх того же сервиса цитат, что и HTTP API.
+// Описание API — api/quotes.proto, сгенерированный код — internal/pb.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"getcitation/internal/app/getcitation"
+	"getcitation/internal/pb"
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// App содержит gRPC сервер и его слушающий сокет
+type App struct {
+	GRPCServer *grpc.Server
+	Listener   net.Listener
+	Log        *slog.Logger
+	Config     config.Config
+}
+
+// New создает gRPC сервер на SERVER_HOST:GRPC_PORT, делегирующий вызовы сервису. readOnly — флаг режима
+// только для чтения, общий с HTTP сервером. Сокет открывается сразу, чтобы ошибка привязки к адресу
+// обнаруживалась до запуска приложения.
+func New(manipulator getcitation.ServiceManipulator, getter getcitation.ServiceGetter, readOnly *atomic.Bool, config config.Config, log *slog.Logger) (App, error) {
+	const op = "grpcserver.New()"
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(config.ServerHost, config.GRPCPort))
+	if err != nil {
+		return App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	server := newServer(Handlers{
+		Log:         log,
+		Config:      config,
+		Manipulator: manipulator,
+		Getter:      getter,
+
+		ReadOnlyMode: readOnly,
+	})
+
+	return App{
+		GRPCServer: server,
+		Listener:   listener,
+		Log:        log,
+		Config:     config,
+	}, nil
+}
+
+// newServer создает gRPC сервер с обработчиками handlers. Каждый вызов сначала проходит аутентификацию,
+// затем проверку режима только для чтения — в том же порядке, что и HTTP запросы.
+func newServer(handlers Handlers) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(handlers.Authenticate, handlers.ReadOnly),
+	)
+
+	pb.RegisterQuotesServer(server, handlers)
+	return server
+}
+
+// Run запускает gRPC сервер и блокирует выполнение до его остановки
+func (a App) Run() error {
+	const op = "grpcserver.Run()"
+
+	err := a.GRPCServer.Serve(a.Listener)
+	if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Shutdown дожидается завершения активных вызовов и останавливает gRPC сервер
+func (a App) Shutdown() error {
+	a.GRPCServer.GracefulStop()
+	return nil
+}
+
+// Handlers реализует pb.QuotesServer через сервис цитат
+type Handlers struct {
+	pb.UnimplementedQuotesServer
+
+	Log    *slog.Logger
+	Config config.Config
+
+	Manipulator getcitation.ServiceManipulator
+	Getter      getcitation.ServiceGetter
+
+	// ReadOnlyMode — флаг режима только для чтения (см. ReadOnly), общий с HTTP сервером
+	ReadOnlyMode *atomic.Bool
+}
+
+// CreateQuote добавляет новую цитату
+func (h Handlers) CreateQuote(ctx context.Context, req *pb.CreateQuoteRequest) (*pb.CreateQuoteResponse, error) {
+	const op = "grpcserver.Handlers.CreateQuote()"
+
+	id, err := h.Manipulator.CreateQuote(ctx, req.GetAuthor(), req.GetQuote(), "", "")
+	if err != nil {
+		return nil, h.toStatus(op, err)
+	}
+
+	return &pb.CreateQuoteResponse{Id: int64(id)}, nil
+}
+
+// DeleteQuote удаляет цитату по ID
+func (h Handlers) DeleteQuote(ctx context.Context, req *pb.DeleteQuoteRequest) (*pb.DeleteQuoteResponse, error) {
+	const op = "grpcserver.Handlers.DeleteQuote()"
+
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	err := h.Manipulator.DeleteQuoteByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, h.toStatus(op, err)
+	}
+
+	return &pb.DeleteQuoteResponse{}, nil
+}
+
+// GetRandomQuote возвращает случайную цитату
+func (h Handlers) GetRandomQuote(ctx context.Context, req *pb.GetRandomQuoteRequest) (*pb.GetRandomQuoteResponse, error) {
+	const op = "grpcserver.Handlers.GetRandomQuote()"
+
+	quote, err := h.Getter.GetRandomQuote(ctx, "", "")
+	if err != nil {
+		return nil, h.toStatus(op, err)
+	}
+
+	return &pb.GetRandomQuoteResponse{Quote: toPB(quote)}, nil
+}
+
+// ListQuotes возвращает страницу цитат, при необходимости только указанного автора. Как и в HTTP API,
+// без limit возвращается PAGE_SIZE_DEFAULT цитат; limit больше PAGE_SIZE_MAX уменьшается до него.
+func (h Handlers) ListQuotes(ctx context.Context, req *pb.ListQuotesRequest) (*pb.ListQuotesResponse, error) {
+	const op = "grpcserver.Handlers.ListQuotes()"
+
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+
+	filter := storage.QuoteFilter{
+		Limit:  h.Config.PageSizeDefault,
+		Offset: int(req.GetOffset()),
+	}
+	if req.GetLimit() > 0 {
+		filter.Limit = min(int(req.GetLimit()), h.Config.PageSizeMax)
+	}
+	if req.GetAuthor() != "" {
+		filter.Authors = []string{req.GetAuthor()}
+	}
+
+	quotes, err := h.Getter.GetQuotes(ctx, filter)
+	if err != nil {
+		return nil, h.toStatus(op, err)
+	}
+
+	resp := &pb.ListQuotesResponse{
+		Quotes: make([]*pb.Quote, 0, len(quotes)),
+	}
+	for _, quote := range quotes {
+		resp.Quotes = append(resp.Quotes, toPB(quote))
+	}
+
+	return resp, nil
+}
+
+// toStatus сопоставляет ошибки сервиса кодам gRPC. Неизвестные ошибки логируются и
+// возвращаются клиенту как Internal без подробностей.
+func (h Handlers) toStatus(op string, err error) error {
+	var validationErr *getcitation.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, getcitation.ErrBannedWord):
+		return status.Error(codes.InvalidArgument, getcitation.ErrBannedWord.Error())
+	case errors.Is(err, getcitation.ErrDuplicateEntry):
+		return status.Error(codes.AlreadyExists, getcitation.ErrDuplicateEntry.Error())
+	case errors.Is(err, getcitation.ErrNoQuotesFound):
+		return status.Error(codes.NotFound, getcitation.ErrNoQuotesFound.Error())
+	case errors.Is(err, storage.ErrUnavailable):
+		return status.Error(codes.Unavailable, storage.ErrUnavailable.Error())
+	}
+
+	h.Log.Error(
+		"внутренняя ошибка",
+		slog.String("op", op),
+		slog.Any("error", err),
+	)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// toPB преобразует цитату хранилища в сообщение gRPC. Время добавления и удаления в сообщение не входит.
+func toPB(quote storage.Quote) *pb.Quote {
+	return &pb.Quote{
+		Id:       int64(quote.ID),
+		Author:   quote.Author,
+		Quote:    quote.Quote,
+		Likes:    int64(quote.Likes),
+		Views:    int64(quote.Views),
+		Language: quote.Language,
+		Source:   quote.Source,
+		Uuid:     quote.UUID,
+		Slug:     quote.Slug,
+	}
+}
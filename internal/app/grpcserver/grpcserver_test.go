@@ -0,0 +1,282 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"getcitation/internal/app/getcitation"
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/pb"
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// fakeManipulator — сервис записи для тестов: возвращает err, если она задана, и запоминает claims,
+// с которыми вызвана CreateQuote. Методы, которых здесь нет, паникуют.
+type fakeManipulator struct {
+	getcitation.ServiceManipulator
+
+	err    error
+	claims *jwt.Claims
+}
+
+func (m fakeManipulator) CreateQuote(ctx context.Context, author string, quote string, lang string, source string) (int, error) {
+	if m.claims != nil {
+		*m.claims, _ = getcitation.ClaimsFromContext(ctx)
+	}
+	if m.err != nil {
+		return 0, m.err
+	}
+	return 1, nil
+}
+
+func (m fakeManipulator) DeleteQuoteByID(ctx context.Context, id int) error {
+	return m.err
+}
+
+// fakeGetter — сервис чтения для тестов: возвращает quotes или err и запоминает последний фильтр
+type fakeGetter struct {
+	getcitation.ServiceGetter
+
+	quotes []storage.Quote
+	err    error
+	filter *storage.QuoteFilter
+}
+
+func (g fakeGetter) GetRandomQuote(ctx context.Context, excludeAuthor string, lang string) (storage.Quote, error) {
+	if g.err != nil {
+		return storage.Quote{}, g.err
+	}
+	return g.quotes[0], nil
+}
+
+func (g fakeGetter) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	if g.filter != nil {
+		*g.filter = filter
+	}
+	return g.quotes, g.err
+}
+
+func testConfig() config.Config {
+	return config.Config{
+		PageSizeDefault: 100,
+		PageSizeMax:     1000,
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestClient запускает gRPC сервер с handlers в памяти через bufconn и возвращает клиента к нему
+func newTestClient(t *testing.T, handlers Handlers) pb.QuotesClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := newServer(handlers)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewQuotesClient(conn)
+}
+
+func TestStatusMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"duplicate", getcitation.ErrDuplicateEntry, codes.AlreadyExists},
+		{"not found", getcitation.ErrNoQuotesFound, codes.NotFound},
+		{"validation", &getcitation.ValidationError{Fields: []getcitation.FieldError{{Field: "author", Reason: "required"}}}, codes.InvalidArgument},
+		{"banned word", getcitation.ErrBannedWord, codes.InvalidArgument},
+		{"unavailable", storage.ErrUnavailable, codes.Unavailable},
+		{"internal", errors.New("disk I/O error"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Сервис оборачивает ошибки хранилища, поэтому сопоставление должно видеть их сквозь обёртку
+			err := fmt.Errorf("getcitation.Service.CreateQuote(): %w", tt.err)
+
+			client := newTestClient(t, Handlers{
+				Log:         testLogger(),
+				Config:      testConfig(),
+				Manipulator: fakeManipulator{err: err},
+				Getter:      fakeGetter{err: err},
+			})
+
+			calls := []struct {
+				rpc  string
+				call func() error
+			}{
+				{"CreateQuote", func() error {
+					_, err := client.CreateQuote(context.Background(), &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+					return err
+				}},
+				{"DeleteQuote", func() error {
+					_, err := client.DeleteQuote(context.Background(), &pb.DeleteQuoteRequest{Id: 1})
+					return err
+				}},
+				{"GetRandomQuote", func() error {
+					_, err := client.GetRandomQuote(context.Background(), &pb.GetRandomQuoteRequest{})
+					return err
+				}},
+				{"ListQuotes", func() error {
+					_, err := client.ListQuotes(context.Background(), &pb.ListQuotesRequest{})
+					return err
+				}},
+			}
+
+			for _, c := range calls {
+				err := c.call()
+				if got := status.Code(err); got != tt.want {
+					t.Errorf("%s() code = %s, want %s (error %v)", c.rpc, got, tt.want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInternalErrorHidesDetails(t *testing.T) {
+	client := newTestClient(t, Handlers{
+		Log:         testLogger(),
+		Config:      testConfig(),
+		Manipulator: fakeManipulator{err: errors.New("disk I/O error")},
+	})
+
+	_, err := client.DeleteQuote(context.Background(), &pb.DeleteQuoteRequest{Id: 1})
+	if got := status.Convert(err).Message(); got != "internal error" {
+		t.Errorf("DeleteQuote() message = %q, want %q", got, "internal error")
+	}
+}
+
+func TestDeleteQuoteRejectsBadID(t *testing.T) {
+	client := newTestClient(t, Handlers{
+		Log:         testLogger(),
+		Config:      testConfig(),
+		Manipulator: fakeManipulator{},
+	})
+
+	_, err := client.DeleteQuote(context.Background(), &pb.DeleteQuoteRequest{Id: 0})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("DeleteQuote(0) code = %s, want %s", got, codes.InvalidArgument)
+	}
+}
+
+func TestListQuotesLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *pb.ListQuotesRequest
+		wantLimit int
+		wantCode  codes.Code
+	}{
+		{"default", &pb.ListQuotesRequest{}, 100, codes.OK},
+		{"explicit", &pb.ListQuotesRequest{Limit: 10, Offset: 20}, 10, codes.OK},
+		{"clamped", &pb.ListQuotesRequest{Limit: 5000}, 1000, codes.OK},
+		{"negative limit", &pb.ListQuotesRequest{Limit: -1}, 0, codes.InvalidArgument},
+		{"negative offset", &pb.ListQuotesRequest{Offset: -1}, 0, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter storage.QuoteFilter
+
+			client := newTestClient(t, Handlers{
+				Log:    testLogger(),
+				Config: testConfig(),
+				Getter: fakeGetter{filter: &filter},
+			})
+
+			_, err := client.ListQuotes(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("ListQuotes() code = %s, want %s (error %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			if filter.Limit != tt.wantLimit {
+				t.Errorf("filter.Limit = %d, want %d", filter.Limit, tt.wantLimit)
+			}
+			if filter.Offset != int(tt.req.GetOffset()) {
+				t.Errorf("filter.Offset = %d, want %d", filter.Offset, tt.req.GetOffset())
+			}
+		})
+	}
+}
+
+func TestListQuotesFields(t *testing.T) {
+	quote := storage.Quote{
+		ID:       7,
+		UUID:     "0190b6a4-7a3c-7f0e-8d2b-2f1c3a4b5c6d",
+		Slug:     "seneca-while-we-teach-we-learn",
+		Author:   "Seneca",
+		Quote:    "While we teach, we learn",
+		Likes:    3,
+		Views:    42,
+		Language: "en",
+		Source:   "Letters to Lucilius",
+	}
+
+	var filter storage.QuoteFilter
+
+	client := newTestClient(t, Handlers{
+		Log:    testLogger(),
+		Config: testConfig(),
+		Getter: fakeGetter{quotes: []storage.Quote{quote}, filter: &filter},
+	})
+
+	resp, err := client.ListQuotes(context.Background(), &pb.ListQuotesRequest{Author: "Seneca"})
+	if err != nil {
+		t.Fatalf("ListQuotes() error = %v", err)
+	}
+	if len(filter.Authors) != 1 || filter.Authors[0] != "Seneca" {
+		t.Errorf("filter.Authors = %q, want [Seneca]", filter.Authors)
+	}
+	if len(resp.GetQuotes()) != 1 {
+		t.Fatalf("ListQuotes() returned %d quotes, want 1", len(resp.GetQuotes()))
+	}
+
+	got := resp.GetQuotes()[0]
+	want := &pb.Quote{
+		Id:       7,
+		Author:   quote.Author,
+		Quote:    quote.Quote,
+		Likes:    3,
+		Views:    42,
+		Language: quote.Language,
+		Source:   quote.Source,
+		Uuid:     quote.UUID,
+		Slug:     quote.Slug,
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("ListQuotes() quote = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReadOnly — перехватчик, отклоняющий изменяющие методы с UNAVAILABLE, пока включен режим только для
+// чтения. Флаг общий с HTTP API, поэтому READ_ONLY_MODE и POST /admin/readonly действуют на оба API сразу.
+func (h Handlers) ReadOnly(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	const op = "grpcserver.Handlers.ReadOnly()"
+
+	if !isMutating(info.FullMethod) || h.ReadOnlyMode == nil || !h.ReadOnlyMode.Load() {
+		return handler(ctx, req)
+	}
+
+	h.Log.Warn(
+		"изменение отклонено в режиме только для чтения",
+		slog.String("op", op),
+		slog.String("method", info.FullMethod),
+	)
+	return nil, status.Error(codes.Unavailable, "service is in read-only mode for maintenance, only reads are accepted")
+}
@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"getcitation/internal/lib/jwt"
+	"getcitation/internal/pb"
+	"getcitation/internal/storage"
+)
+
+func TestReadOnly(t *testing.T) {
+	readOnly := &atomic.Bool{}
+	readOnly.Store(true)
+
+	client := newTestClient(t, Handlers{
+		Log:          testLogger(),
+		Config:       testConfig(),
+		Manipulator:  fakeManipulator{},
+		Getter:       fakeGetter{quotes: []storage.Quote{{ID: 1, Author: "Seneca", Quote: "While we teach, we learn"}}},
+		ReadOnlyMode: readOnly,
+	})
+
+	ctx := context.Background()
+
+	_, err := client.CreateQuote(ctx, &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("CreateQuote() in read-only mode code = %s, want %s", got, codes.Unavailable)
+	}
+	_, err = client.DeleteQuote(ctx, &pb.DeleteQuoteRequest{Id: 1})
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("DeleteQuote() in read-only mode code = %s, want %s", got, codes.Unavailable)
+	}
+
+	_, err = client.ListQuotes(ctx, &pb.ListQuotesRequest{})
+	if err != nil {
+		t.Errorf("ListQuotes() in read-only mode error = %v, want nil", err)
+	}
+	_, err = client.GetRandomQuote(ctx, &pb.GetRandomQuoteRequest{})
+	if err != nil {
+		t.Errorf("GetRandomQuote() in read-only mode error = %v, want nil", err)
+	}
+
+	// Флаг общий с HTTP сервером: выключение через /admin/readonly сразу снимает запрет и здесь
+	readOnly.Store(false)
+
+	_, err = client.CreateQuote(ctx, &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+	if err != nil {
+		t.Errorf("CreateQuote() after read-only mode error = %v, want nil", err)
+	}
+}
+
+func TestReadOnlyAfterAuthentication(t *testing.T) {
+	cfg := testConfig()
+	cfg.JWTSecret = testSecret
+
+	readOnly := &atomic.Bool{}
+	readOnly.Store(true)
+
+	client := newTestClient(t, Handlers{
+		Log:          testLogger(),
+		Config:       cfg,
+		Manipulator:  fakeManipulator{},
+		ReadOnlyMode: readOnly,
+	})
+
+	// Без прав вызов отклоняется раньше, чем проверяется режим, как и в HTTP API
+	_, err := client.CreateQuote(withToken(t, jwt.Claims{Role: "reader"}, testSecret), &pb.CreateQuoteRequest{Author: "Seneca", Quote: "While we teach, we learn"})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("CreateQuote() as reader code = %s, want %s", got, codes.PermissionDenied)
+	}
+}
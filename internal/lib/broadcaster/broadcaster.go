@@ -0,0 +1,119 @@
+// Пакет broadcaster реализует простой in-process pub/sub для рассылки новых цитат подписчикам.
+package broadcaster
+
+import (
+	"context"
+	"sync"
+
+	"getcitation/internal/storage"
+)
+
+// Broadcaster рассылает опубликованные цитаты всем текущим подписчикам. У каждого подписчика свой
+// буферизованный канал; если подписчик не успевает читать и буфер заполнен, цитата для него отбрасывается,
+// чтобы медленный клиент не блокировал создание цитат.
+type Broadcaster struct {
+	Buffer int
+
+	mu          *sync.Mutex
+	subscribers map[chan storage.Quote]struct{}
+	state       *state
+}
+
+// state — изменяемое состояние Broadcaster, общее для его копий
+type state struct {
+	// closed — Shutdown уже вызван: новые подписчики сразу получают закрытый канал
+	closed bool
+	// active — подписчики, еще не вызвавшие функцию отписки
+	active int
+	// drained закрывается, когда после Shutdown отписался последний подписчик
+	drained chan struct{}
+}
+
+// New создаёт Broadcaster с заданным размером буфера канала подписчика.
+func New(buffer int) Broadcaster {
+	return Broadcaster{
+		Buffer: buffer,
+
+		mu:          &sync.Mutex{},
+		subscribers: map[chan storage.Quote]struct{}{},
+		state:       &state{drained: make(chan struct{})},
+	}
+}
+
+// Subscribe регистрирует нового подписчика. Возвращает канал с цитатами и функцию отписки,
+// которую нужно вызвать, когда подписчик больше не читает канал. Закрытый канал означает, что сервис
+// останавливается (см. Shutdown) и подписчику пора завершиться.
+func (b Broadcaster) Subscribe() (<-chan storage.Quote, func()) {
+	ch := make(chan storage.Quote, b.Buffer)
+
+	b.mu.Lock()
+	if b.state.closed {
+		b.mu.Unlock()
+
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+	b.state.active++
+	b.mu.Unlock()
+
+	var once sync.Once
+
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := b.subscribers[ch]; ok {
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+
+			b.state.active--
+			if b.state.closed && b.state.active == 0 {
+				close(b.state.drained)
+			}
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Shutdown закрывает каналы всех подписчиков, чтобы они завершились, и ждет, пока каждый из них
+// отпишется, но не дольше ctx. После Shutdown новые подписчики сразу получают закрытый канал,
+// а Publish никому ничего не отправляет.
+func (b Broadcaster) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.state.closed {
+		b.state.closed = true
+
+		for ch := range b.subscribers {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+		if b.state.active == 0 {
+			close(b.state.drained)
+		}
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.state.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Publish отправляет цитату всем подписчикам без блокировки.
+func (b Broadcaster) Publish(quote storage.Quote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- quote:
+		default:
+		}
+	}
+}
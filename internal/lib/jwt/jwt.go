@@ -0,0 +1,127 @@
+// Пакет jwt проверяет и выпускает JWT, подписанные HS256. Поддерживается только то, что нужно
+// сервису: подпись общим секретом и стандартные временные claims.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AlgorithmHS256 — единственный поддерживаемый алгоритм подписи.
+const AlgorithmHS256 = "HS256"
+
+var (
+	ErrMalformed   = fmt.Errorf("malformed token")
+	ErrAlgorithm   = fmt.Errorf("unsupported signing algorithm")
+	ErrSignature   = fmt.Errorf("invalid token signature")
+	ErrExpired     = fmt.Errorf("token is expired")
+	ErrNotYetValid = fmt.Errorf("token is not valid yet")
+)
+
+// Claims — полезная нагрузка токена. Временные поля — Unix-время в секундах, 0 означает "не задано".
+type Claims struct {
+	Subject   string `json:"sub,omitempty"`
+	Role      string `json:"role,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// header — заголовок токена.
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+var encoding = base64.RawURLEncoding
+
+// Parse проверяет подпись и сроки действия токена на момент now и возвращает его claims.
+func Parse(token string, secret []byte, now time.Time) (Claims, error) {
+	const op = "jwt.Parse()"
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%s: %w", op, ErrMalformed)
+	}
+
+	var h header
+
+	err := decode(parts[0], &h)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if h.Algorithm != AlgorithmHS256 {
+		return Claims{}, fmt.Errorf("%s: %w: %q", op, ErrAlgorithm, h.Algorithm)
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, ErrMalformed)
+	}
+
+	if !hmac.Equal(signature, sign(parts[0]+"."+parts[1], secret)) {
+		return Claims{}, fmt.Errorf("%s: %w", op, ErrSignature)
+	}
+
+	var claims Claims
+
+	err = decode(parts[1], &claims)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if claims.ExpiresAt != 0 && !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return Claims{}, fmt.Errorf("%s: %w", op, ErrExpired)
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0)) {
+		return Claims{}, fmt.Errorf("%s: %w", op, ErrNotYetValid)
+	}
+
+	return claims, nil
+}
+
+// Sign выпускает токен HS256 с указанными claims.
+func Sign(claims Claims, secret []byte) (string, error) {
+	const op = "jwt.Sign()"
+
+	h, err := json.Marshal(header{Algorithm: AlgorithmHS256, Type: "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	unsigned := encoding.EncodeToString(h) + "." + encoding.EncodeToString(payload)
+
+	return unsigned + "." + encoding.EncodeToString(sign(unsigned, secret)), nil
+}
+
+// sign вычисляет HMAC-SHA256 подпись части токена.
+func sign(unsigned string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+// decode разбирает base64url-часть токена как JSON.
+func decode(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+// now — момент проверки токенов в тестах
+var now = time.Unix(1_700_000_000, 0)
+
+// craft собирает токен из заголовка и полезной нагрузки в JSON и подписывает его secret. Так тесты
+// получают токены, которые Sign выпустить не может.
+func craft(header string, payload string) string {
+	unsigned := encoding.EncodeToString([]byte(header)) + "." + encoding.EncodeToString([]byte(payload))
+	return unsigned + "." + encoding.EncodeToString(sign(unsigned, secret))
+}
+
+// mustSign выпускает токен с claims
+func mustSign(t *testing.T, claims Claims) string {
+	t.Helper()
+
+	token, err := Sign(claims, secret)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return token
+}
+
+func TestParseValid(t *testing.T) {
+	want := Claims{
+		Subject:   "alice",
+		Role:      "admin",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		NotBefore: now.Add(-time.Hour).Unix(),
+		IssuedAt:  now.Add(-time.Hour).Unix(),
+	}
+
+	got, err := Parse(mustSign(t, want), secret, now)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseWithoutTimeClaims(t *testing.T) {
+	_, err := Parse(mustSign(t, Claims{Subject: "alice"}), secret, now)
+	if err != nil {
+		t.Errorf("Parse() error = %v, want nil", err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	valid := mustSign(t, Claims{Subject: "alice", Role: "admin"})
+	parts := strings.Split(valid, ".")
+
+	tamperedPayload := encoding.EncodeToString([]byte(`{"sub":"alice","role":"admin","exp":1}`))
+	noneHeader := encoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"wrong secret", func() string {
+			token, _ := Sign(Claims{Subject: "alice"}, []byte("other-secret"))
+			return token
+		}(), ErrSignature},
+		{"tampered payload", parts[0] + "." + tamperedPayload + "." + parts[2], ErrSignature},
+		{"alg none without signature", noneHeader + "." + parts[1] + ".", ErrAlgorithm},
+		{"alg none with signature", noneHeader + "." + parts[1] + "." + parts[2], ErrAlgorithm},
+		{"alg HS512", craft(`{"alg":"HS512","typ":"JWT"}`, `{"sub":"alice"}`), ErrAlgorithm},
+		{"alg lowercase", craft(`{"alg":"hs256","typ":"JWT"}`, `{"sub":"alice"}`), ErrAlgorithm},
+		{"expired", mustSign(t, Claims{ExpiresAt: now.Add(-time.Second).Unix()}), ErrExpired},
+		{"expires now", mustSign(t, Claims{ExpiresAt: now.Unix()}), ErrExpired},
+		{"not yet valid", mustSign(t, Claims{NotBefore: now.Add(time.Minute).Unix()}), ErrNotYetValid},
+		{"empty", "", ErrMalformed},
+		{"two segments", parts[0] + "." + parts[1], ErrMalformed},
+		{"four segments", valid + "." + parts[2], ErrMalformed},
+		{"bad base64 header", "!!!." + parts[1] + "." + parts[2], ErrMalformed},
+		{"bad base64 signature", parts[0] + "." + parts[1] + ".***", ErrMalformed},
+		{"padded base64 signature", valid + "=", ErrMalformed},
+		{"bad JSON header", craft(`{"alg":`, `{"sub":"alice"}`), ErrMalformed},
+		{"bad JSON payload", craft(`{"alg":"HS256","typ":"JWT"}`, `{"sub":`), ErrMalformed},
+		{"payload not an object", craft(`{"alg":"HS256","typ":"JWT"}`, `"alice"`), ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := Parse(tt.token, secret, now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.want)
+			}
+			if claims != (Claims{}) {
+				t.Errorf("Parse() claims = %+v, want none on error", claims)
+			}
+		})
+	}
+}
+
+func TestParseBadBase64PayloadWithValidSignature(t *testing.T) {
+	header := encoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	unsigned := header + ".!!!"
+	token := unsigned + "." + encoding.EncodeToString(sign(unsigned, secret))
+
+	_, err := Parse(token, secret, now)
+	if !errors.Is(err, ErrMalformed) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrMalformed)
+	}
+}
+
+func TestSignHeader(t *testing.T) {
+	token := mustSign(t, Claims{Subject: "alice"})
+
+	var h header
+
+	err := decode(strings.Split(token, ".")[0], &h)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if h.Algorithm != AlgorithmHS256 || h.Type != "JWT" {
+		t.Errorf("header = %+v, want alg %s and typ JWT", h, AlgorithmHS256)
+	}
+}
@@ -5,17 +5,28 @@ package logger
 import (
 	"fmt"
 	"getcitation/internal/utils"
+	"io"
 	"log/slog"
 	"os"
 )
 
-var ErrUnknownLogMode = fmt.Errorf("неизвестный режим логирования")
+var (
+	ErrUnknownLogMode   = fmt.Errorf("неизвестный режим логирования")
+	ErrUnknownLogOutput = fmt.Errorf("неизвестный вывод логов, ожидается file, stdout или both")
+)
 
 const (
 	devLogPath  = "log/dev/dev.log.json"
 	prodLogPath = "log/log.json"
 )
 
+// Куда пишутся JSON-логи режимов dev и prod.
+const (
+	OutputFile   = "file"
+	OutputStdout = "stdout"
+	OutputBoth   = "both"
+)
+
 // Logger инкапсулирует slog.Logger и файл, в который пишутся логи (если используется).
 type Logger struct {
 	Log  *slog.Logger
@@ -24,12 +35,15 @@ type Logger struct {
 
 // New создаёт новый логгер в зависимости от режима logMode:
 //   - local: логирование в stdout в текстовом формате
-//   - dev: логирование в JSON-файл с уровнем debug
-//   - prod: логирование в JSON-файл с уровнем info
-func New(logMode string) (Logger, error) {
+//   - dev: логирование в JSON с уровнем debug
+//   - prod: логирование в JSON с уровнем info
+//
+// Для dev и prod logOutput выбирает, куда пишется JSON: в файл, в stdout или в оба сразу.
+func New(logMode string, logOutput string) (Logger, error) {
 	const op = "logger.New()"
 
 	var log *slog.Logger
+	var out io.Writer
 	var file *os.File
 	var err error
 
@@ -43,26 +57,26 @@ func New(logMode string) (Logger, error) {
 		))
 
 	case "dev":
-		file, err = utils.GetLogFile(devLogPath)
+		out, file, err = output(devLogPath, logOutput)
 		if err != nil {
-			return Logger{}, err
+			return Logger{}, fmt.Errorf("%s: %w", op, err)
 		}
 
 		log = slog.New(slog.NewJSONHandler(
-			file,
+			out,
 			&slog.HandlerOptions{
 				Level: slog.LevelDebug,
 			},
 		))
 
 	case "prod":
-		file, err = utils.GetLogFile(prodLogPath)
+		out, file, err = output(prodLogPath, logOutput)
 		if err != nil {
-			return Logger{}, err
+			return Logger{}, fmt.Errorf("%s: %w", op, err)
 		}
 
 		log = slog.New(slog.NewJSONHandler(
-			file,
+			out,
 			&slog.HandlerOptions{
 				Level: slog.LevelInfo,
 			},
@@ -78,12 +92,42 @@ func New(logMode string) (Logger, error) {
 	}, nil
 }
 
-// Shutdown корректно закрывает файл логов, если он был открыт. Возвращает ошибку, если файл не удалось закрыть.
+// output открывает вывод JSON-логов. Возвращаемый файл нужно закрыть при остановке; при выводе
+// только в stdout он равен nil — stdout не закрывается.
+func output(path string, logOutput string) (io.Writer, *os.File, error) {
+	switch logOutput {
+	case OutputStdout:
+		return os.Stdout, nil, nil
+
+	case OutputFile, OutputBoth:
+		file, err := utils.GetLogFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if logOutput == OutputBoth {
+			return io.MultiWriter(file, os.Stdout), file, nil
+		}
+		return file, file, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w: %q", ErrUnknownLogOutput, logOutput)
+}
+
+// Shutdown корректно закрывает файл логов, если он был открыт. Перед закрытием записанное сбрасывается
+// на диск, чтобы последние строки журнала не потерялись. Возвращает ошибку, если файл не удалось сбросить
+// или закрыть. После Shutdown логгер использовать нельзя.
 func (l *Logger) Shutdown() error {
 	const op = "logger.Shutdown()"
 
 	if l.File != nil {
-		err := l.File.Close()
+		err := l.File.Sync()
+		if err != nil {
+			l.File.Close()
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = l.File.Close()
 		if err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout подменяет os.Stdout на канал до конца теста и возвращает его концы. Тесты с ним нельзя
+// запускать параллельно.
+func captureStdout(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		r.Close()
+		w.Close()
+	})
+
+	return r, w
+}
+
+// readLog читает файл журнала
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%s) error = %v", path, err)
+	}
+	return string(data)
+}
+
+func TestNewOutputBoth(t *testing.T) {
+	t.Chdir(t.TempDir())
+	r, w := captureStdout(t)
+
+	l, err := New("dev", OutputBoth)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.Log.Info("both sinks")
+
+	err = l.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// Shutdown закрывает только файл: stdout остаётся открытым
+	_, err = w.Write([]byte("still open\n"))
+	if err != nil {
+		t.Fatalf("stdout after Shutdown() error = %v", err)
+	}
+	w.Close()
+
+	stdout, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read stdout error = %v", err)
+	}
+	if !strings.Contains(string(stdout), `"msg":"both sinks"`) {
+		t.Errorf("stdout = %q, want the JSON line", stdout)
+	}
+
+	if file := readLog(t, devLogPath); !strings.Contains(file, `"msg":"both sinks"`) {
+		t.Errorf("log file = %q, want the JSON line", file)
+	}
+}
+
+func TestNewOutputFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	r, w := captureStdout(t)
+
+	l, err := New("prod", OutputFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.Log.Debug("below prod level")
+	l.Log.Info("file only")
+
+	err = l.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	w.Close()
+
+	stdout, _ := io.ReadAll(r)
+	if len(stdout) != 0 {
+		t.Errorf("stdout = %q, want nothing", stdout)
+	}
+
+	file := readLog(t, prodLogPath)
+	if !strings.Contains(file, `"msg":"file only"`) {
+		t.Errorf("log file = %q, want the JSON line", file)
+	}
+	if strings.Contains(file, "below prod level") {
+		t.Errorf("log file = %q, want no debug lines in prod", file)
+	}
+}
+
+func TestNewOutputStdout(t *testing.T) {
+	t.Chdir(t.TempDir())
+	r, w := captureStdout(t)
+
+	l, err := New("dev", OutputStdout)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if l.File != nil {
+		t.Errorf("New() opened %s, want no file", l.File.Name())
+	}
+
+	l.Log.Debug("stdout only")
+
+	err = l.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	w.Close()
+
+	stdout, _ := io.ReadAll(r)
+	if !strings.Contains(string(stdout), `"msg":"stdout only"`) {
+		t.Errorf("stdout = %q, want the JSON line", stdout)
+	}
+	if _, err := os.Stat(devLogPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("os.Stat(%s) error = %v, want the file not to exist", devLogPath, err)
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := New("verbose", OutputFile)
+	if !errors.Is(err, ErrUnknownLogMode) {
+		t.Errorf("New(verbose) error = %v, want %v", err, ErrUnknownLogMode)
+	}
+
+	t.Chdir(t.TempDir())
+
+	_, err = New("dev", "syslog")
+	if !errors.Is(err, ErrUnknownLogOutput) {
+		t.Errorf("New(dev, syslog) error = %v, want %v", err, ErrUnknownLogOutput)
+	}
+}
+
+func TestShutdownKeepsFinalLine(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	l, err := New("prod", OutputFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for range 1000 {
+		l.Log.Info("serving")
+	}
+	l.Log.Info("остановлено")
+
+	err = l.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(readLog(t, prodLogPath), "\n"), "\n")
+	if len(lines) != 1001 {
+		t.Fatalf("log file has %d lines, want 1001", len(lines))
+	}
+	if last := lines[len(lines)-1]; !strings.Contains(last, `"msg":"остановлено"`) {
+		t.Errorf("last log line = %q, want the shutdown event", last)
+	}
+
+	// Файл закрыт: повторный Shutdown сообщает об этом, а не молча теряет записи
+	err = l.Shutdown()
+	if !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second Shutdown() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestShutdownWithoutFile(t *testing.T) {
+	l, err := New("local", OutputFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = l.Shutdown()
+	if err != nil {
+		t.Errorf("Shutdown() without a log file error = %v, want nil", err)
+	}
+}
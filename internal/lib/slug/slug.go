@@ -0,0 +1,92 @@
+// Пакет slug строит человекочитаемые идентификаторы для URL: латиница в нижнем регистре, цифры и дефисы.
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ограничения частей slug: из цитаты берется несколько первых слов, а основа без ID не длиннее maxStemLength
+const (
+	snippetWords  = 4
+	maxStemLength = 80
+)
+
+// cyrillic — транслитерация кириллицы в латиницу (упрощенная, без диакритики)
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// special — латинские буквы, которые не раскладываются на базовую букву и диакритику
+var special = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ł': "l", 'þ': "th", 'ð': "d", 'ı': "i",
+}
+
+// Make строит slug цитаты из автора, первых слов текста и ID: "marcus-aurelius-the-happiness-of-your-3".
+// ID в конце делает slug уникальным, даже если у цитат одинаковые автор и начало текста. Символы, которые
+// не удалось перевести в латиницу (например, иероглифы), пропускаются; если не осталось ничего, slug — "quote-ID".
+func Make(author string, quote string, id int) string {
+	words := strings.Fields(quote)
+	words = words[:min(len(words), snippetWords)]
+
+	stem := normalize(author + " " + strings.Join(words, " "))
+	if len(stem) > maxStemLength {
+		stem = strings.TrimRight(stem[:maxStemLength], "-")
+		if cut := strings.LastIndexByte(stem, '-'); cut > 0 {
+			stem = stem[:cut]
+		}
+	}
+	if stem == "" {
+		stem = "quote"
+	}
+
+	return stem + "-" + strconv.Itoa(id)
+}
+
+// normalize переводит текст в латиницу в нижнем регистре, а все остальное заменяет одиночными дефисами
+func normalize(text string) string {
+	var b strings.Builder
+
+	hyphen := false
+	write := func(s string) {
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteString(s)
+	}
+
+	for _, r := range strings.ToLower(text) {
+		if latin, ok := cyrillic[r]; ok {
+			// Твердый и мягкий знаки не разрывают слово
+			if latin != "" {
+				write(latin)
+			}
+			continue
+		}
+		if latin, ok := special[r]; ok {
+			write(latin)
+			continue
+		}
+
+		// NFKD раскладывает букву с диакритикой (é → e + ´), знаки отбрасываются
+		for _, part := range norm.NFKD.String(string(r)) {
+			switch {
+			case unicode.Is(unicode.Mn, part):
+			case part < unicode.MaxASCII && (unicode.IsLetter(part) || unicode.IsDigit(part)):
+				write(string(part))
+			default:
+				hyphen = true
+			}
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,279 @@
+// Пакет webhook отправляет события об изменении цитат во внешнюю систему.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"getcitation/internal/utils/config"
+)
+
+// Типы событий.
+const (
+	EventQuoteCreated  = "quote.created"
+	EventQuoteDeleted  = "quote.deleted"
+	EventQuoteRestored = "quote.restored"
+)
+
+// HeaderSignature — заголовок с HMAC-SHA256 подписью тела запроса в формате "sha256=<hex>".
+const HeaderSignature = "X-Getcitation-Signature"
+
+var ErrUnexpectedStatus = fmt.Errorf("получатель вернул неожиданный статус")
+
+// Event — тело запроса, которое получает вебхук.
+type Event struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Author    string    `json:"author,omitempty"`
+	Quote     string    `json:"quote,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// QueueSize — сколько событий может ждать доставки. Если получатель не успевает и очередь заполнена,
+// новые события отбрасываются, а не копятся в памяти.
+const QueueSize = 256
+
+// Publisher асинхронно доставляет события на WEBHOOK_URL с повторами. Если URL не задан, события отбрасываются.
+// События доставляет по очереди один фоновый обработчик, поэтому поток мутаций не порождает горутину
+// на каждое событие.
+type Publisher struct {
+	URL     string
+	Secret  string
+	Retries int
+	Backoff time.Duration
+	Client  *http.Client
+	Log     *slog.Logger
+
+	queue chan delivery
+
+	// mu защищает closed: после закрытия очереди Publish в неё больше не пишет
+	mu     *sync.RWMutex
+	closed *bool
+
+	// stop отменяется, когда Shutdown перестает ждать доставки: обработчик прерывает паузу между попытками
+	// и текущий запрос, а оставшиеся события отбрасывает
+	stop   context.Context
+	cancel context.CancelFunc
+	// done закрывается, когда обработчик завершился
+	done chan struct{}
+}
+
+// delivery — событие в очереди вместе с уже сериализованным телом запроса.
+type delivery struct {
+	event Event
+	body  []byte
+}
+
+// New создаёт Publisher по конфигу и, если задан WEBHOOK_URL, запускает обработчик очереди.
+func New(config config.Config, log *slog.Logger) Publisher {
+	stop, cancel := context.WithCancel(context.Background())
+
+	p := Publisher{
+		URL:     config.WebhookURL,
+		Secret:  config.WebhookSecret,
+		Retries: config.WebhookRetries,
+		Backoff: config.WebhookBackoff,
+		Client: &http.Client{
+			Timeout: config.WebhookTimeout,
+		},
+		Log: log,
+
+		queue: make(chan delivery, QueueSize),
+
+		mu:     &sync.RWMutex{},
+		closed: new(bool),
+
+		stop:   stop,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	if p.URL == "" {
+		close(p.done)
+		return p
+	}
+
+	go p.run()
+	return p
+}
+
+// Publish ставит событие в очередь на доставку и сразу возвращает управление. Ошибки доставки только
+// логируются. Если очередь заполнена или уже началась остановка, событие отбрасывается.
+func (p Publisher) Publish(event Event) {
+	const op = "webhook.Publish()"
+
+	if p.URL == "" {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.Log.Error(
+			"не удалось сериализовать событие",
+			slog.String("op", op),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if *p.closed {
+		p.Log.Warn(
+			"событие отброшено: идёт остановка",
+			slog.String("op", op),
+			slog.String("type", event.Type),
+			slog.Int("id", event.ID),
+		)
+		return
+	}
+
+	select {
+	case p.queue <- delivery{event: event, body: body}:
+	default:
+		p.Log.Error(
+			"событие отброшено: очередь доставки заполнена",
+			slog.String("op", op),
+			slog.String("type", event.Type),
+			slog.Int("id", event.ID),
+			slog.Int("queue_size", QueueSize),
+		)
+	}
+}
+
+// run доставляет события из очереди по одному, пока очередь не закрыта. После отмены stop оставшиеся
+// события только логируются как отброшенные.
+func (p Publisher) run() {
+	const op = "webhook.run()"
+
+	defer close(p.done)
+
+	for d := range p.queue {
+		if p.stop.Err() != nil {
+			p.Log.Error(
+				"событие отброшено: остановка не дождалась доставки",
+				slog.String("op", op),
+				slog.String("type", d.event.Type),
+				slog.Int("id", d.event.ID),
+			)
+			continue
+		}
+
+		p.send(d)
+	}
+}
+
+// send доставляет одно событие, повторяя попытки до Retries раз с линейно растущей паузой
+func (p Publisher) send(d delivery) {
+	const op = "webhook.send()"
+
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(p.Backoff * time.Duration(attempt))
+
+			select {
+			case <-timer.C:
+			case <-p.stop.Done():
+				timer.Stop()
+
+				p.Log.Error(
+					"событие отброшено: остановка не дождалась доставки",
+					slog.String("op", op),
+					slog.String("type", d.event.Type),
+					slog.Int("id", d.event.ID),
+					slog.Int("attempts", attempt),
+				)
+				return
+			}
+		}
+
+		err := p.deliver(p.stop, d.body)
+		if err == nil {
+			return
+		}
+
+		p.Log.Warn(
+			"не удалось доставить событие",
+			slog.String("op", op),
+			slog.String("type", d.event.Type),
+			slog.Int("id", d.event.ID),
+			slog.Int("attempt", attempt+1),
+			slog.Any("error", err),
+		)
+	}
+
+	p.Log.Error(
+		"событие не доставлено, попытки исчерпаны",
+		slog.String("op", op),
+		slog.String("type", d.event.Type),
+		slog.Int("id", d.event.ID),
+	)
+}
+
+// deliver выполняет одну попытку доставки события. Запрос прерывается при отмене ctx.
+func (p Publisher) deliver(ctx context.Context, body []byte) error {
+	const op = "webhook.deliver()"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if p.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(p.Secret, body))
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %w: %d", op, ErrUnexpectedStatus, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign вычисляет HMAC-SHA256 подпись тела в hex. Получатель может сверить её с заголовком HeaderSignature.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Shutdown перестаёт принимать события и ждёт, пока обработчик доставит уже поставленные в очередь, но
+// не дольше, чем позволяет ctx. Когда ctx истекает, текущая доставка прерывается, а оставшиеся события
+// отбрасываются с записью в журнал.
+func (p Publisher) Shutdown(ctx context.Context) error {
+	const op = "webhook.Shutdown()"
+
+	p.mu.Lock()
+	if !*p.closed {
+		*p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+}
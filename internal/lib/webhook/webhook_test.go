@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"getcitation/internal/utils/config"
+)
+
+// receiver — получатель вебхуков для тестов: отвечает статусами из statuses по порядку (последний
+// повторяется) и запоминает запросы.
+type receiver struct {
+	mu         sync.Mutex
+	statuses   []int
+	bodies     [][]byte
+	signatures []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.bodies = append(rc.bodies, body)
+	rc.signatures = append(rc.signatures, r.Header.Get(HeaderSignature))
+
+	status := rc.statuses[min(len(rc.bodies), len(rc.statuses))-1]
+	w.WriteHeader(status)
+}
+
+// requests возвращает, сколько запросов получено
+func (rc *receiver) requests() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.bodies)
+}
+
+// newPublisher создает Publisher, доставляющий события на сервер получателя rc
+func newPublisher(t *testing.T, rc *receiver, cfg config.Config) Publisher {
+	t.Helper()
+
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	cfg.WebhookURL = server.URL
+	if cfg.WebhookTimeout == 0 {
+		cfg.WebhookTimeout = time.Second
+	}
+	return New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// shutdown дожидается доставки поставленных событий
+func shutdown(t *testing.T, p Publisher) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := p.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestPublishSignature(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusNoContent}}
+	p := newPublisher(t, rc, config.Config{WebhookSecret: "secret"})
+
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1, Author: "Seneca", Quote: "While we teach, we learn"})
+	shutdown(t, p)
+
+	if rc.requests() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", rc.requests())
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(rc.bodies[0])
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if rc.signatures[0] != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, rc.signatures[0], want)
+	}
+}
+
+func TestPublishWithoutSecretIsUnsigned(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusOK}}
+	p := newPublisher(t, rc, config.Config{})
+
+	p.Publish(Event{Type: EventQuoteDeleted, ID: 1})
+	shutdown(t, p)
+
+	if rc.requests() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", rc.requests())
+	}
+	if rc.signatures[0] != "" {
+		t.Errorf("%s = %q, want none", HeaderSignature, rc.signatures[0])
+	}
+}
+
+func TestPublishRetriesOnServerError(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK}}
+	p := newPublisher(t, rc, config.Config{WebhookRetries: 5, WebhookBackoff: time.Millisecond})
+
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1})
+	shutdown(t, p)
+
+	if rc.requests() != 3 {
+		t.Errorf("receiver got %d requests, want 3: delivery must stop after the first success", rc.requests())
+	}
+}
+
+func TestPublishGivesUpAfterRetries(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusServiceUnavailable}}
+	p := newPublisher(t, rc, config.Config{WebhookRetries: 2, WebhookBackoff: time.Millisecond})
+
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1})
+	shutdown(t, p)
+
+	if rc.requests() != 3 {
+		t.Errorf("receiver got %d requests, want 3: the first attempt and 2 retries", rc.requests())
+	}
+}
+
+func TestPublishDeliversInOrder(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusOK}}
+	p := newPublisher(t, rc, config.Config{})
+
+	for id := 1; id <= 10; id++ {
+		p.Publish(Event{Type: EventQuoteCreated, ID: id})
+	}
+	shutdown(t, p)
+
+	if rc.requests() != 10 {
+		t.Errorf("receiver got %d requests, want 10", rc.requests())
+	}
+}
+
+func TestShutdownInterruptsBackoff(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	p := newPublisher(t, rc, config.Config{WebhookRetries: 3, WebhookBackoff: time.Hour})
+
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1})
+	p.Publish(Event{Type: EventQuoteCreated, ID: 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := p.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Shutdown() took %s, want it to interrupt the backoff", elapsed)
+	}
+
+	// Второе событие отброшено при остановке и не доставлялось
+	if rc.requests() != 1 {
+		t.Errorf("receiver got %d requests, want 1", rc.requests())
+	}
+}
+
+func TestPublishAfterShutdownIsDropped(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusOK}}
+	p := newPublisher(t, rc, config.Config{})
+
+	shutdown(t, p)
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1})
+
+	// Повторная остановка не закрывает очередь второй раз
+	shutdown(t, p)
+
+	if rc.requests() != 0 {
+		t.Errorf("receiver got %d requests after shutdown, want 0", rc.requests())
+	}
+}
+
+func TestPublishWithoutURL(t *testing.T) {
+	p := New(config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	p.Publish(Event{Type: EventQuoteCreated, ID: 1})
+	shutdown(t, p)
+}
@@ -0,0 +1,244 @@
+// Описание gRPC API сервиса getcitation. Go-код генерируется в internal/pb:
+//
+//	protoc --go_out=. --go_opt=module=getcitation \
+//	       --go-grpc_out=. --go-grpc_opt=module=getcitation \
+//	       api/quotes.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: api/quotes.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Quotes_CreateQuote_FullMethodName    = "/getcitation.v1.Quotes/CreateQuote"
+	Quotes_DeleteQuote_FullMethodName    = "/getcitation.v1.Quotes/DeleteQuote"
+	Quotes_GetRandomQuote_FullMethodName = "/getcitation.v1.Quotes/GetRandomQuote"
+	Quotes_ListQuotes_FullMethodName     = "/getcitation.v1.Quotes/ListQuotes"
+)
+
+// QuotesClient is the client API for Quotes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Quotes — операции над цитатами, те же, что и в HTTP API.
+type QuotesClient interface {
+	// CreateQuote добавляет новую цитату. ALREADY_EXISTS, если такая цитата уже есть.
+	CreateQuote(ctx context.Context, in *CreateQuoteRequest, opts ...grpc.CallOption) (*CreateQuoteResponse, error)
+	// DeleteQuote удаляет цитату по ID. NOT_FOUND, если цитаты нет.
+	DeleteQuote(ctx context.Context, in *DeleteQuoteRequest, opts ...grpc.CallOption) (*DeleteQuoteResponse, error)
+	// GetRandomQuote возвращает случайную цитату. NOT_FOUND, если цитат нет.
+	GetRandomQuote(ctx context.Context, in *GetRandomQuoteRequest, opts ...grpc.CallOption) (*GetRandomQuoteResponse, error)
+	// ListQuotes возвращает страницу цитат, при необходимости только указанного автора. Без limit
+	// возвращается PAGE_SIZE_DEFAULT цитат, limit больше PAGE_SIZE_MAX уменьшается до него.
+	ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error)
+}
+
+type quotesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuotesClient(cc grpc.ClientConnInterface) QuotesClient {
+	return &quotesClient{cc}
+}
+
+func (c *quotesClient) CreateQuote(ctx context.Context, in *CreateQuoteRequest, opts ...grpc.CallOption) (*CreateQuoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateQuoteResponse)
+	err := c.cc.Invoke(ctx, Quotes_CreateQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesClient) DeleteQuote(ctx context.Context, in *DeleteQuoteRequest, opts ...grpc.CallOption) (*DeleteQuoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQuoteResponse)
+	err := c.cc.Invoke(ctx, Quotes_DeleteQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesClient) GetRandomQuote(ctx context.Context, in *GetRandomQuoteRequest, opts ...grpc.CallOption) (*GetRandomQuoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRandomQuoteResponse)
+	err := c.cc.Invoke(ctx, Quotes_GetRandomQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesClient) ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuotesResponse)
+	err := c.cc.Invoke(ctx, Quotes_ListQuotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QuotesServer is the server API for Quotes service.
+// All implementations must embed UnimplementedQuotesServer
+// for forward compatibility
+//
+// Quotes — операции над цитатами, те же, что и в HTTP API.
+type QuotesServer interface {
+	// CreateQuote добавляет новую цитату. ALREADY_EXISTS, если такая цитата уже есть.
+	CreateQuote(context.Context, *CreateQuoteRequest) (*CreateQuoteResponse, error)
+	// DeleteQuote удаляет цитату по ID. NOT_FOUND, если цитаты нет.
+	DeleteQuote(context.Context, *DeleteQuoteRequest) (*DeleteQuoteResponse, error)
+	// GetRandomQuote возвращает случайную цитату. NOT_FOUND, если цитат нет.
+	GetRandomQuote(context.Context, *GetRandomQuoteRequest) (*GetRandomQuoteResponse, error)
+	// ListQuotes возвращает страницу цитат, при необходимости только указанного автора. Без limit
+	// возвращается PAGE_SIZE_DEFAULT цитат, limit больше PAGE_SIZE_MAX уменьшается до него.
+	ListQuotes(context.Context, *ListQuotesRequest) (*ListQuotesResponse, error)
+	mustEmbedUnimplementedQuotesServer()
+}
+
+// UnimplementedQuotesServer must be embedded to have forward compatible implementations.
+type UnimplementedQuotesServer struct {
+}
+
+func (UnimplementedQuotesServer) CreateQuote(context.Context, *CreateQuoteRequest) (*CreateQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQuote not implemented")
+}
+func (UnimplementedQuotesServer) DeleteQuote(context.Context, *DeleteQuoteRequest) (*DeleteQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQuote not implemented")
+}
+func (UnimplementedQuotesServer) GetRandomQuote(context.Context, *GetRandomQuoteRequest) (*GetRandomQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRandomQuote not implemented")
+}
+func (UnimplementedQuotesServer) ListQuotes(context.Context, *ListQuotesRequest) (*ListQuotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQuotes not implemented")
+}
+func (UnimplementedQuotesServer) mustEmbedUnimplementedQuotesServer() {}
+
+// UnsafeQuotesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuotesServer will
+// result in compilation errors.
+type UnsafeQuotesServer interface {
+	mustEmbedUnimplementedQuotesServer()
+}
+
+func RegisterQuotesServer(s grpc.ServiceRegistrar, srv QuotesServer) {
+	s.RegisterService(&Quotes_ServiceDesc, srv)
+}
+
+func _Quotes_CreateQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServer).CreateQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Quotes_CreateQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServer).CreateQuote(ctx, req.(*CreateQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Quotes_DeleteQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServer).DeleteQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Quotes_DeleteQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServer).DeleteQuote(ctx, req.(*DeleteQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Quotes_GetRandomQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRandomQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServer).GetRandomQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Quotes_GetRandomQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServer).GetRandomQuote(ctx, req.(*GetRandomQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Quotes_ListQuotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServer).ListQuotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Quotes_ListQuotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServer).ListQuotes(ctx, req.(*ListQuotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Quotes_ServiceDesc is the grpc.ServiceDesc for Quotes service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Quotes_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "getcitation.v1.Quotes",
+	HandlerType: (*QuotesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateQuote",
+			Handler:    _Quotes_CreateQuote_Handler,
+		},
+		{
+			MethodName: "DeleteQuote",
+			Handler:    _Quotes_DeleteQuote_Handler,
+		},
+		{
+			MethodName: "GetRandomQuote",
+			Handler:    _Quotes_GetRandomQuote_Handler,
+		},
+		{
+			MethodName: "ListQuotes",
+			Handler:    _Quotes_ListQuotes_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/quotes.proto",
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Действия, которые записываются в журнал аудита
+const (
+	AuditCreate  = "create"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+	AuditImport  = "import"
+
+	// AuditRenameAuthor записывается с прежним именем автора
+	AuditRenameAuthor = "rename_author"
+)
+
+// AuditEntry — запись журнала аудита. Записи добавляются в той же транзакции, что и само изменение,
+// поэтому журнал не расходится с данными. QuoteID и Author пусты для операций над всеми цитатами,
+// Actor — если аутентификация выключена.
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	Action    string    `json:"action"`
+	QuoteID   *int      `json:"quote_id,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// actorKey — ключ инициатора изменения в контексте
+type actorKey struct{}
+
+// WithActor возвращает контекст, в котором изменения записываются в журнал аудита от имени actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает инициатора изменения или пустую строку, если он неизвестен
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
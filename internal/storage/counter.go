@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"sync"
+)
+
+// QuoteCounter кэширует число неудалённых цитат, чтобы случайную цитату можно было выбрать по смещению
+// без COUNT(*) на каждый запрос. Изменения, уменьшающие или увеличивающие число цитат, сообщают о себе
+// через Add после фиксации транзакции. Пока кэш холодный (после запуска или Reset), Add ничего не
+// делает, а число нужно посчитать запросом и сохранить через Store.
+// Нулевое значение выключено: Load всегда сообщает холодный кэш, остальные методы ничего не делают.
+type QuoteCounter struct {
+	state *counterState
+}
+
+type counterState struct {
+	mu    sync.Mutex
+	count int
+	warm  bool
+	// writes растёт при каждом Add, в том числе на холодном кэше: по нему Store узнаёт, что пока
+	// считался COUNT(*), число цитат могло измениться, и подсчитанное значение уже устарело.
+	writes uint64
+}
+
+// NewQuoteCounter создаёт включённый холодный счётчик.
+func NewQuoteCounter() QuoteCounter {
+	return QuoteCounter{state: &counterState{}}
+}
+
+// Enabled сообщает, включён ли счётчик.
+func (c QuoteCounter) Enabled() bool {
+	return c.state != nil
+}
+
+// Load возвращает закэшированное число цитат и true либо, если кэш холодный, версию для Store и false.
+func (c QuoteCounter) Load() (int, uint64, bool) {
+	if c.state == nil {
+		return 0, 0, false
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	return c.state.count, c.state.writes, c.state.warm
+}
+
+// Store прогревает кэш подсчитанным числом цитат, если с момента Load, вернувшего version, не было Add.
+func (c QuoteCounter) Store(count int, version uint64) {
+	if c.state == nil {
+		return
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if c.state.writes != version {
+		return
+	}
+	c.state.count = count
+	c.state.warm = true
+}
+
+// Add учитывает изменение числа цитат на delta.
+func (c QuoteCounter) Add(delta int) {
+	if c.state == nil {
+		return
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	c.state.writes++
+	if c.state.warm {
+		c.state.count += delta
+	}
+}
+
+// Reset делает кэш холодным: следующий Load потребует пересчёта. Нужен, когда изменение числа цитат
+// неизвестно заранее или кэш разошёлся с таблицей.
+func (c QuoteCounter) Reset() {
+	if c.state == nil {
+		return
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	c.state.writes++
+	c.state.warm = false
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrUnavailable = fmt.Errorf("storage is unavailable")
+)
+
+// Пределы паузы между проверками соединения после сбоя: пауза удваивается от минимальной до максимальной.
+const (
+	probeMinBackoff time.Duration = 100 * time.Millisecond
+	probeMaxBackoff time.Duration = 10 * time.Second
+)
+
+// Health отслеживает доступность БД. Ошибки соединения (в отличие от ошибок запросов вроде
+// нарушения уникальности) переводят хранилище в недоступное состояние и запускают фоновую
+// проверку: соединения пингуются с растущей паузой, пока БД не ответит. Нового соединения
+// открывать не нужно — database/sql сам заменяет разорванные соединения пула.
+type Health struct {
+	dbs          []*sql.DB
+	isConnection func(error) bool
+	log          *slog.Logger
+
+	healthy *atomic.Bool
+	probing *atomic.Bool
+	done    chan struct{}
+}
+
+// NewHealth создаёт монитор доступности для соединений dbs. isConnection отличает ошибки
+// соединения конкретного драйвера от ошибок запросов.
+func NewHealth(isConnection func(error) bool, log *slog.Logger, dbs ...*sql.DB) Health {
+	health := Health{
+		dbs:          dbs,
+		isConnection: isConnection,
+		log:          log,
+
+		healthy: &atomic.Bool{},
+		probing: &atomic.Bool{},
+		done:    make(chan struct{}),
+	}
+	health.healthy.Store(true)
+
+	return health
+}
+
+// Healthy сообщает, доступна ли БД по последним наблюдениям.
+func (h Health) Healthy() bool {
+	return h.healthy.Load()
+}
+
+// Check пингует все соединения. Если БД недоступна, возвращает ошибку, обёрнутую в ErrUnavailable.
+func (h Health) Check(ctx context.Context) error {
+	const op = "storage.Health.Check()"
+
+	if !h.Healthy() {
+		return fmt.Errorf("%s: %w", op, ErrUnavailable)
+	}
+
+	for _, db := range h.dbs {
+		err := db.PingContext(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, h.Observe(err))
+		}
+	}
+	return nil
+}
+
+// Observe классифицирует ошибку запроса. Ошибка соединения помечается ErrUnavailable, а хранилище
+// считается недоступным до успешной фоновой проверки. Остальные ошибки возвращаются как есть.
+func (h Health) Observe(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if !errors.Is(err, sql.ErrConnDone) && !h.isConnection(err) {
+		return err
+	}
+
+	if h.healthy.CompareAndSwap(true, false) {
+		h.log.Error(
+			"база данных недоступна",
+			slog.String("op", "storage.Health.Observe()"),
+			slog.Any("error", err),
+		)
+	}
+	if h.probing.CompareAndSwap(false, true) {
+		go h.probe()
+	}
+
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// probe пингует соединения с растущей паузой, пока все они не ответят, и возвращает хранилище в строй.
+func (h Health) probe() {
+	const op = "storage.Health.probe()"
+
+	backoff := probeMinBackoff
+
+	for {
+		select {
+		case <-h.done:
+			h.probing.Store(false)
+			return
+		case <-time.After(backoff):
+		}
+
+		err := h.ping()
+		if err == nil {
+			// Сначала снимается флаг проверки: сбой, замеченный между двумя записями, запустит новую проверку,
+			// а не оставит хранилище недоступным навсегда.
+			h.probing.Store(false)
+			h.healthy.Store(true)
+			h.log.Info(
+				"база данных снова доступна",
+				slog.String("op", op),
+			)
+			return
+		}
+
+		backoff = min(backoff*2, probeMaxBackoff)
+
+		h.log.Warn(
+			"база данных всё ещё недоступна",
+			slog.String("op", op),
+			slog.Any("error", err),
+			slog.Duration("retry_in", backoff),
+		)
+	}
+}
+
+// ping проверяет все соединения с таймаутом в размер максимальной паузы.
+func (h Health) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), probeMaxBackoff)
+	defer cancel()
+
+	for _, db := range h.dbs {
+		err := db.PingContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close останавливает фоновую проверку. Вызывается перед закрытием соединений.
+func (h Health) Close() {
+	close(h.done)
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// migrationsTable — таблица, в которой migrate (cmd/migrator) хранит версию схемы. Мигратор использует
+// таблицу по умолчанию, поэтому имя не берется из MIGRATIONS_TABLE.
+const migrationsTable = "schema_migrations"
+
+// migrationFile — имя файла миграции вверх: версия, подчеркивание, название
+var migrationFile = regexp.MustCompile(`^(\d+)_.*\.up\.sql$`)
+
+// MigrationError — схема БД отстает от миграций сервиса: Current — версия схемы (0, если миграции
+// не применялись), Expected — последняя доступная миграция. Dirty означает, что миграция Current
+// завершилась с ошибкой и схема в промежуточном состоянии.
+type MigrationError struct {
+	Current  uint
+	Expected uint
+	Dirty    bool
+}
+
+func (e *MigrationError) Error() string {
+	if e.Dirty {
+		return fmt.Sprintf("database schema is dirty at migration %d, expected %d", e.Current, e.Expected)
+	}
+	return fmt.Sprintf("database schema is at migration %d, expected %d", e.Current, e.Expected)
+}
+
+// LatestMigration возвращает версию последней миграции в каталоге path — наибольшую из имен
+// файлов вида N_name.up.sql.
+func LatestMigration(path string) (uint, error) {
+	const op = "storage.LatestMigration()"
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var latest uint
+
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %s: %w", op, entry.Name(), err)
+		}
+		latest = max(latest, uint(version))
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("%s: no migrations found in %s", op, path)
+	}
+	return latest, nil
+}
+
+// CheckMigration сверяет версию схемы БД с expected. Если схема отстает или грязная, возвращает
+// *MigrationError. Схема новее expected не считается ошибкой: ее накатил более новый экземпляр сервиса.
+// Ошибка чтения версии (чаще всего таблицы версий еще нет — миграции не применялись) считается версией 0;
+// доступность самой БД проверяется до этого.
+func CheckMigration(ctx context.Context, db *sql.DB, expected uint) error {
+	const op = "storage.CheckMigration()"
+
+	var current uint
+	var dirty bool
+
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM `+migrationsTable+` LIMIT 1`).Scan(&current, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		current, dirty = 0, false
+	}
+
+	if current < expected || dirty {
+		return fmt.Errorf("%s: %w", op, &MigrationError{Current: current, Expected: expected, Dirty: dirty})
+	}
+	return nil
+}
@@ -2,13 +2,22 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 
+	"getcitation/internal/lib/slug"
+	"getcitation/internal/storage"
 	"getcitation/internal/utils"
 	"getcitation/internal/utils/config"
 )
@@ -17,10 +26,50 @@ var (
 	CodeDuplicateEntry pq.ErrorCode = "23505"
 )
 
+// warmUpTimeout ограничивает прогрев пула при старте: прогрев лишь сглаживает первые запросы, и долго ждать
+// медленный сервер ради него не стоит.
+const warmUpTimeout = 10 * time.Second
+
+// Коды ошибок, которыми сервер сообщает о завершении или невозможности соединения.
+// Кроме них к ошибкам соединения относится весь класс 08 (connection exception).
+var (
+	CodeAdminShutdown    pq.ErrorCode = "57P01"
+	CodeCrashShutdown    pq.ErrorCode = "57P02"
+	CodeCannotConnectNow pq.ErrorCode = "57P03"
+)
+
+// Коды ошибок, после которых транзакцию можно повторить с начала: конфликт сериализации
+// (при уровнях изоляции выше READ COMMITTED) и взаимоблокировка.
 var (
-	ErrDuplicateEntry = fmt.Errorf("duplicate entry")
+	CodeSerializationFailure pq.ErrorCode = "40001"
+	CodeDeadlockDetected     pq.ErrorCode = "40P01"
 )
 
+// isRetryable сообщает, что транзакция завершилась временным конфликтом и её можно повторить.
+func isRetryable(err error) bool {
+	var e *pq.Error
+	return errors.As(err, &e) && (e.Code == CodeSerializationFailure || e.Code == CodeDeadlockDetected)
+}
+
+// isConnectionError отличает потерю соединения с сервером от ошибок самого запроса.
+func isConnectionError(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		switch e.Code {
+		case CodeAdminShutdown, CodeCrashShutdown, CodeCannotConnectNow:
+			return true
+		}
+		return e.Code.Class() == "08"
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
 // Storage содержит подключение к БД и основные зависимости (логгер, конфиг).
 type Storage struct {
 	DB     DB
@@ -28,9 +77,11 @@ type Storage struct {
 	Config config.Config
 }
 
-// DB содержит подключение к PostgreSQL и обработчики.
+// DB содержит подключения к PostgreSQL и обработчики. Replica совпадает с Implementation,
+// если реплика для чтения не настроена.
 type DB struct {
 	Implementation *sql.DB
+	Replica        *sql.DB
 	Handlers       Handlers
 }
 
@@ -38,6 +89,18 @@ type DB struct {
 func New(config config.Config, log *slog.Logger) (Storage, error) {
 	const op = "postgresql.New()"
 
+	// Последняя миграция определяется до подключения: без каталога миграций проверка готовности
+	// невозможна, и сервис не должен стартовать.
+	var migration uint
+	if config.ReadyCheckMigrations {
+		var err error
+
+		migration, err = storage.LatestMigration(config.MigrationsPath)
+		if err != nil {
+			return Storage{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
 	conn := utils.BuildPostgreSQLDSN(config)
 
 	db, err := sql.Open("postgres", conn)
@@ -50,13 +113,67 @@ func New(config config.Config, log *slog.Logger) (Storage, error) {
 		return Storage{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	replica := db
+
+	if config.PostgreSQLReplicaDSN != "" {
+		replica, err = sql.Open("postgres", config.PostgreSQLReplicaDSN)
+		if err != nil {
+			db.Close()
+			return Storage{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = replica.Ping()
+		if err != nil {
+			replica.Close()
+			db.Close()
+			return Storage{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	statements, err := prepareStatements(db, replica, config.PostgreSQLTable)
+	if err != nil {
+		if replica != db {
+			replica.Close()
+		}
+		db.Close()
+		return Storage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if config.PostgreSQLWarmUpConns > 0 {
+		// Лимит простаивающих соединений остаётся поднятым после прогрева: по умолчанию database/sql держит
+		// только 2, и остальные прогретые соединения закрылись бы сразу после возврата в пул
+		idle := max(config.PostgreSQLWarmUpConns, 2)
+		db.SetMaxIdleConns(idle)
+		warmUp(log, db, config.PostgreSQLWarmUpConns)
+		if replica != db {
+			replica.SetMaxIdleConns(idle)
+			warmUp(log, replica, config.PostgreSQLWarmUpConns)
+		}
+	}
+
+	health := storage.NewHealth(isConnectionError, log, db)
+	if replica != db {
+		health = storage.NewHealth(isConnectionError, log, db, replica)
+	}
+
+	var counter storage.QuoteCounter
+	if config.RandomCountCache {
+		counter = storage.NewQuoteCounter()
+	}
+
 	return Storage{
 		DB: DB{
 			Implementation: db,
+			Replica:        replica,
 			Handlers: Handlers{
-				DB:     db,
-				Log:    log,
-				Config: config,
+				DB:         db,
+				Replica:    replica,
+				Statements: statements,
+				Health:     health,
+				Counter:    counter,
+				Migration:  migration,
+				Log:        log,
+				Config:     config,
 			},
 		},
 		Log:    log,
@@ -64,164 +181,1583 @@ func New(config config.Config, log *slog.Logger) (Storage, error) {
 	}, nil
 }
 
+// warmUp открывает conns соединений пула и выполняет на каждом SELECT 1, чтобы первые запросы не ждали
+// установки соединения. Соединения удерживаются одновременно, иначе пул отдал бы одно и то же; лимит
+// простаивающих соединений к этому моменту должен быть не меньше conns (см. New). Ошибка прогрева не мешает
+// старту и только пишется в журнал.
+func warmUp(log *slog.Logger, db *sql.DB, conns int) {
+	const op = "postgresql.warmUp()"
+
+	ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout)
+	defer cancel()
+
+	start := time.Now()
+	warmed := make([]*sql.Conn, 0, conns)
+
+	defer func() {
+		for _, conn := range warmed {
+			conn.Close()
+		}
+	}()
+
+	for range conns {
+		conn, err := db.Conn(ctx)
+		if err == nil {
+			_, err = conn.ExecContext(ctx, `SELECT 1`)
+			warmed = append(warmed, conn)
+		}
+		if err != nil {
+			log.Warn(
+				"не удалось прогреть пул соединений",
+				slog.String("op", op),
+				slog.Any("error", err),
+				slog.Int("warmed", len(warmed)),
+				slog.Int("connections", conns),
+			)
+			return
+		}
+	}
+
+	log.Info(
+		"пул соединений прогрет",
+		slog.String("op", op),
+		slog.Int("connections", conns),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
 // Shutdown корректно закрывает соединение с БД.
 func (s Storage) Shutdown() error {
 	const op = "postgresql.Shutdown()"
 
-	err := s.DB.Implementation.Close()
+	s.DB.Handlers.Health.Close()
+
+	err := s.DB.Handlers.Statements.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if s.DB.Replica != s.DB.Implementation {
+		err = s.DB.Replica.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = s.DB.Implementation.Close()
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
+// PoolStats возвращает статистику пула соединений основного сервера (через него идут записи).
+func (s Storage) PoolStats() sql.DBStats {
+	return s.DB.Handlers.PoolStats()
+}
+
 // Handlers — структура для реализации логики работы с конкретной таблицей или сущностью.
+// Записи идут в DB (основной сервер), чтения — в Replica.
 type Handlers struct {
-	DB     *sql.DB
-	Log    *slog.Logger
-	Config config.Config
+	DB         *sql.DB
+	Replica    *sql.DB
+	Statements Statements
+	Health     storage.Health
+	Counter    storage.QuoteCounter
+	Migration  uint
+	Log        *slog.Logger
+	Config     config.Config
 }
 
-// Quote - объект цитаты.
-type Quote struct {
-	ID     int    `json:"id"`
-	Author string `json:"author"`
-	Quote  string `json:"quote"`
+// Statements содержит запросы горячих путей чтения, подготовленные один раз при подключении.
+// Чтения выполняются без транзакций; внутри транзакции запрос нужно привязывать через tx.Stmt.
+type Statements struct {
+	RandomQuote         *sql.Stmt
+	RandomQuoteAt       *sql.Stmt
+	FairRandomQuote     *sql.Stmt
+	AddView             *sql.Stmt
+	QuoteByID           *sql.Stmt
+	ViewQuoteByID       *sql.Stmt
+	CountQuotes         *sql.Stmt
+	CountQuotesByAuthor *sql.Stmt
+}
+
+// prepareStatements подготавливает все запросы Statements для таблицы цитат table: чтения — на реплике,
+// записи — на основном сервере.
+// При ошибке уже подготовленные запросы закрываются.
+func prepareStatements(db *sql.DB, replica *sql.DB, table string) (Statements, error) {
+	const op = "postgresql.prepareStatements()"
+
+	var statements Statements
+
+	// RandomQuote выбирает среди существующих строк, а не по случайному ID из диапазона, поэтому
+	// дыры в ID после удалений не смещают выборку: каждая оставшаяся цитата равновероятна.
+	// Более быстрая замена (например, по ID или TABLESAMPLE) должна сохранить это свойство.
+	queries := []struct {
+		stmt  **sql.Stmt
+		db    *sql.DB
+		query string
+	}{
+		{&statements.RandomQuote, replica, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL AND author <> $1 AND ($2 = '' OR language = $2) ORDER BY RANDOM() LIMIT 1`},
+		{&statements.RandomQuoteAt, replica, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL ORDER BY id LIMIT 1 OFFSET $1`},
+		{&statements.FairRandomQuote, replica, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL AND ($2 = '' OR language = $2) AND author = (SELECT author FROM {quotes} WHERE deleted_at IS NULL AND author <> $1 AND ($2 = '' OR language = $2) GROUP BY author ORDER BY RANDOM() LIMIT 1) ORDER BY RANDOM() LIMIT 1`},
+		{&statements.AddView, db, `UPDATE {quotes} SET views = views + 1 WHERE id = $1`},
+		{&statements.QuoteByID, replica, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE id = $1 AND deleted_at IS NULL`},
+		{&statements.ViewQuoteByID, db, `UPDATE {quotes} SET views = views + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING id, author, quote, likes, views, language, source, uuid, slug`},
+		{&statements.CountQuotes, replica, `SELECT COUNT(*) FROM {quotes} WHERE deleted_at IS NULL`},
+		{&statements.CountQuotesByAuthor, replica, `SELECT COUNT(*) FROM {quotes} WHERE author = $1 AND deleted_at IS NULL`},
+	}
+
+	for _, q := range queries {
+		stmt, err := q.db.Prepare(withTable(q.query, table))
+		if err != nil {
+			statements.Close()
+			return Statements{}, fmt.Errorf("%s: %w", op, err)
+		}
+		*q.stmt = stmt
+	}
+
+	return statements, nil
+}
+
+// Close закрывает все подготовленные запросы.
+func (s Statements) Close() error {
+	var errs []error
+
+	for _, stmt := range []*sql.Stmt{s.RandomQuote, s.RandomQuoteAt, s.FairRandomQuote, s.AddView, s.QuoteByID, s.ViewQuoteByID, s.CountQuotes, s.CountQuotesByAuthor} {
+		if stmt == nil {
+			continue
+		}
+		errs = append(errs, stmt.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// tablePlaceholder обозначает в тексте запросов таблицу цитат. Имя таблицы нельзя передать параметром
+// запроса, поэтому оно подставляется в текст; config.validate пропускает только простые идентификаторы,
+// так что подстановка не открывает SQL-инъекцию.
+const tablePlaceholder = "{quotes}"
+
+// defaultTable — таблица цитат, которую создают миграции
+const defaultTable = "quotes"
+
+// dependentTables — таблицы, которые ссылаются на таблицу цитат внешними ключами или хранят её историю.
+// В запросах они обозначаются как {имя}.
+var dependentTables = []string{"categories", "quote_categories", "idempotency_keys", "audit_log"}
+
+// withTable подставляет в запрос имя таблицы цитат table и имена зависимых от неё таблиц. С таблицей
+// по умолчанию это таблицы из миграций, с другой — те же имена с префиксом "<table>_" (archive_audit_log
+// для archive): так внешние ключи каждой установки ссылаются на её собственную таблицу цитат, а категории,
+// ключи идемпотентности и журнал аудита разных установок не смешиваются.
+func withTable(query string, table string) string {
+	query = strings.ReplaceAll(query, tablePlaceholder, table)
+
+	for _, dependent := range dependentTables {
+		name := dependent
+		if table != defaultTable {
+			name = table + "_" + dependent
+		}
+		query = strings.ReplaceAll(query, "{"+dependent+"}", name)
+	}
+	return query
+}
+
+// query подставляет в запрос таблицу цитат из POSTGRESQL_TABLE.
+func (h Handlers) query(query string) string {
+	return withTable(query, h.Config.PostgreSQLTable)
+}
+
+// fail добавляет к ошибке запроса контекст: op и входные параметры запроса (attrs), чтобы по логам
+// было видно, на каких данных запрос упал. Ошибки соединения помечаются storage.ErrUnavailable,
+// а хранилище до восстановления соединения считается недоступным.
+func (h Handlers) fail(op string, err error, attrs ...slog.Attr) error {
+	err = h.Health.Observe(err)
+
+	if len(attrs) == 0 {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return fmt.Errorf("%s [%s]: %w", op, storage.ErrorContext(attrs...), err)
+}
+
+// retry выполняет транзакцию fn, повторяя её до TX_RETRIES раз при временных конфликтах.
+func (h Handlers) retry(ctx context.Context, fn func() error) error {
+	return storage.Retry(ctx, h.Config.TxRetries, isRetryable, fn)
+}
+
+// PoolStats возвращает статистику пула соединений основного сервера (через него идут записи).
+func (h Handlers) PoolStats() sql.DBStats {
+	return h.DB.Stats()
+}
+
+// Ready проверяет, что БД доступна и отвечает на ping. Если задан Migration (READY_CHECK_MIGRATIONS=true),
+// еще и что схема БД не отстает от этой миграции — иначе *storage.MigrationError.
+func (h Handlers) Ready(ctx context.Context) error {
+	const op = "postgresql.Ready()"
+
+	err := h.Health.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if h.Migration > 0 {
+		err = storage.CheckMigration(ctx, h.DB, h.Migration)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// setSlug записывает slug только что добавленной цитаты. Slug содержит ID, который известен только после
+// вставки, поэтому он записывается отдельным запросом в той же транзакции.
+func (h Handlers) setSlug(ctx context.Context, tx *sql.Tx, id int, quote storage.Quote) error {
+	_, err := tx.ExecContext(ctx, h.query(`UPDATE {quotes} SET slug = $1 WHERE id = $2`), slug.Make(quote.Author, quote.Quote, id), id)
+	return err
+}
+
+// audit добавляет запись в журнал аудита в транзакции изменения, чтобы журнал не расходился с данными.
+// Нулевой quoteID и пустые author и инициатор из контекста записываются как NULL.
+func (h Handlers) audit(ctx context.Context, tx *sql.Tx, action string, quoteID int, author string) error {
+	actor := storage.ActorFromContext(ctx)
+
+	_, err := tx.Exec(
+		h.query(`INSERT INTO {audit_log} (action, quote_id, author, actor) VALUES ($1, $2, $3, $4)`),
+		action,
+		sql.NullInt64{Int64: int64(quoteID), Valid: quoteID != 0},
+		sql.NullString{String: author, Valid: author != ""},
+		sql.NullString{String: actor, Valid: actor != ""},
+	)
+	return err
 }
 
 // CreateQuote добавляет новую цитату в базу.
-func (h Handlers) CreateQuote(quote Quote) (int, error) {
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) CreateQuote(ctx context.Context, quote storage.Quote) (int, error) {
+	var id int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		id, err = h.createQuote(ctx, quote)
+		return err
+	})
+	if err == nil {
+		h.Counter.Add(1)
+	}
+	return id, err
+}
+
+// createQuote выполняет одну попытку CreateQuote в отдельной транзакции.
+func (h Handlers) createQuote(ctx context.Context, quote storage.Quote) (int, error) {
 	const op = "postgresql.CreateQuote()"
 
-	tx, err := h.DB.Begin()
+	tx, err := h.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 	defer tx.Rollback()
 
 	var id int
 	var e *pq.Error
 
-	err = tx.QueryRow(`INSERT INTO quotes (author, quote) VALUES ($1, $2) RETURNING id`, quote.Author, quote.Quote).Scan(&id)
+	err = tx.QueryRow(h.query(`INSERT INTO {quotes} (author, quote, language, source) VALUES ($1, $2, $3, $4) RETURNING id`), quote.Author, quote.Quote, quote.Language, quote.Source).Scan(&id)
 	if err != nil {
 		if errors.As(err, &e) && e.Code == CodeDuplicateEntry {
-			return 0, fmt.Errorf("%s: %w", op, ErrDuplicateEntry)
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
 		}
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = h.setSlug(ctx, tx, id, quote)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = h.audit(ctx, tx, storage.AuditCreate, id, quote.Author)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
 	return id, nil
 }
 
-// DeleteQuoteByID удаляет цитату по ID.
-func (h Handlers) DeleteQuoteByID(id int) error {
-	const op = "postgresql.DeleteQuoteByID()"
+// UpsertQuote добавляет цитату, а если такая же неудалённая цитата уже есть, возвращает её ID и true
+// вместо storage.ErrDuplicateEntry.
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) UpsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error) {
+	var id int
+	var existing bool
 
-	tx, err := h.DB.Begin()
+	err := h.retry(ctx, func() error {
+		var err error
+		id, existing, err = h.upsertQuote(ctx, quote)
+		return err
+	})
+	if err == nil && !existing {
+		h.Counter.Add(1)
+	}
+	return id, existing, err
+}
+
+// upsertQuote выполняет одну попытку UpsertQuote в отдельной транзакции.
+func (h Handlers) upsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error) {
+	const op = "postgresql.UpsertQuote()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 	defer tx.Rollback()
 
-	res, err := tx.Exec(`DELETE FROM quotes WHERE id = $1`, id)
+	var id int
+	var existing bool
+
+	// При конфликте DO NOTHING не возвращает строку, и ID существующей цитаты читается отдельным запросом.
+	// Если ту цитату успели удалить между INSERT и SELECT, SELECT тоже ничего не находит: тогда вставка
+	// повторяется и, раз конфликта больше нет, добавляет цитату как новую.
+	for attempt := 1; ; attempt++ {
+		err = tx.QueryRowContext(ctx, h.query(`INSERT INTO {quotes} (author, quote, language, source) VALUES ($1, $2, $3, $4) ON CONFLICT (author, quote) WHERE deleted_at IS NULL DO NOTHING RETURNING id`), quote.Author, quote.Quote, quote.Language, quote.Source).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+
+		err = tx.QueryRowContext(ctx, h.query(`SELECT id FROM {quotes} WHERE author = $1 AND quote = $2 AND deleted_at IS NULL`), quote.Author, quote.Quote).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) || attempt == storage.UpsertAttempts {
+			existing = true
+			break
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	if existing {
+		err = tx.Commit()
+		if err != nil {
+			return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+		}
+
+		return id, true, nil
 	}
 
-	affected, err := res.RowsAffected()
+	err = h.setSlug(ctx, tx, id, quote)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
-	if affected == 0 {
-		return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	err = h.audit(ctx, tx, storage.AuditCreate, id, quote.Author)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
-	return nil
+	return id, false, nil
 }
 
-// GetRandomQuote получает случайную цитату.
-func (h Handlers) GetRandomQuote() (Quote, error) {
-	const op = "postgresql.GetRandomQuote()"
+// CreateQuoteIdempotent добавляет новую цитату и привязывает к ней ключ идемпотентности.
+// Если ключ уже использовался и ещё не истёк, возвращает ID ранее созданной цитаты и true, а если
+// с ключом создавалась другая цитата — storage.ErrKeyReused.
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) CreateQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error) {
+	var id int
+	var replayed bool
 
-	tx, err := h.DB.Begin()
+	err := h.retry(ctx, func() error {
+		var err error
+		id, replayed, err = h.createQuoteIdempotent(ctx, key, quote, ttl)
+		return err
+	})
+	if err == nil && !replayed {
+		h.Counter.Add(1)
+	}
+	return id, replayed, err
+}
+
+// createQuoteIdempotent выполняет одну попытку CreateQuoteIdempotent в отдельной транзакции.
+func (h Handlers) createQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error) {
+	const op = "postgresql.CreateQuoteIdempotent()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return Quote{}, fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 	defer tx.Rollback()
 
-	var quote Quote
+	// Без блокировки два одновременных запроса с одним ключом оба не нашли бы его и оба стали бы вставлять
+	// цитату. Блокировка по ключу держится до конца транзакции: второй запрос дождется первого и увидит ключ.
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	_, err = tx.ExecContext(ctx, h.query(`DELETE FROM {idempotency_keys} WHERE created_at < $1`), time.Now().Add(-ttl))
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	var id int
+	var fingerprint sql.NullString
+	var e *pq.Error
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT quote_id, fingerprint FROM {idempotency_keys} WHERE key = $1`), key).Scan(&id, &fingerprint)
+	if err == nil {
+		// У ключей, записанных до миграции 17, отпечатка нет, и их повтор принимается как раньше.
+		if fingerprint.Valid && fingerprint.String != storage.Fingerprint(quote) {
+			return 0, false, fmt.Errorf("%s: %w", op, storage.ErrKeyReused)
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+		}
+		return id, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = tx.QueryRowContext(ctx, h.query(`INSERT INTO {quotes} (author, quote, language, source) VALUES ($1, $2, $3, $4) RETURNING id`), quote.Author, quote.Quote, quote.Language, quote.Source).Scan(&id)
+	if err != nil {
+		if errors.As(err, &e) && e.Code == CodeDuplicateEntry {
+			return 0, false, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	_, err = tx.ExecContext(ctx, h.query(`INSERT INTO {idempotency_keys} (key, quote_id, fingerprint) VALUES ($1, $2, $3)`), key, id, storage.Fingerprint(quote))
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = h.setSlug(ctx, tx, id, quote)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
 
-	err = tx.QueryRow(`SELECT id, author, quote FROM quotes ORDER BY RANDOM() LIMIT 1`).Scan(&quote.ID, &quote.Author, &quote.Quote)
+	err = h.audit(ctx, tx, storage.AuditCreate, id, quote.Author)
 	if err != nil {
-		return Quote{}, fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return Quote{}, fmt.Errorf("%s: %w", op, err)
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
 	}
 
-	return quote, nil
+	return id, false, nil
 }
 
-// GetQuotes получает все цитаты, при необходимости фильтрует по автору.
-func (h Handlers) GetQuotes(authorFilter string) ([]Quote, error) {
-	const op = "postgresql.GetQuotes()"
+// ImportQuotes добавляет цитаты одной транзакцией и возвращает ID добавленных цитат в порядке quotes;
+// 0 означает, что такая цитата уже есть и строка пропущена. Каждая строка вставляется в своей точке
+// сохранения, поэтому дубликат откатывает только себя, а не весь импорт. Прочие ошибки прерывают импорт целиком.
+// При strict точек сохранения нет: первый дубликат откатывает весь импорт и возвращается как *storage.RowError
+// с номером строки, оборачивающий storage.ErrDuplicateEntry.
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) ImportQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error) {
+	var ids []int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		ids, err = h.importQuotes(ctx, quotes, strict)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if id != 0 {
+			h.Counter.Add(1)
+		}
+	}
+	return ids, nil
+}
+
+// importQuotes выполняет одну попытку ImportQuotes в отдельной транзакции.
+func (h Handlers) importQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error) {
+	const op = "postgresql.ImportQuotes()"
 
-	tx, err := h.DB.Begin()
+	tx, err := h.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, h.fail(op, err, slog.Int("quotes", len(quotes)))
 	}
 	defer tx.Rollback()
 
-	var rows *sql.Rows
+	ids := make([]int, len(quotes))
+	var e *pq.Error
 
-	if authorFilter == "" {
-		rows, err = tx.Query(`SELECT id, author, quote FROM quotes`)
+	for i, quote := range quotes {
+		if !strict {
+			_, err = tx.ExecContext(ctx, `SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, h.query(`INSERT INTO {quotes} (author, quote, language, source) VALUES ($1, $2, $3, $4) RETURNING id`), quote.Author, quote.Quote, quote.Language, quote.Source).Scan(&ids[i])
+		if err != nil {
+			if !(errors.As(err, &e) && e.Code == CodeDuplicateEntry) {
+				return nil, h.fail(op, err, slog.Int("row", i+1), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+			}
+			if strict {
+				return nil, fmt.Errorf("%s: %w", op, &storage.RowError{Row: i, Err: storage.ErrDuplicateEntry})
+			}
+
+			_, err = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+			ids[i] = 0
+			continue
+		}
+
+		err = h.setSlug(ctx, tx, ids[i], quote)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("row", i+1))
+		}
+
+		err = h.audit(ctx, tx, storage.AuditImport, ids[i], quote.Author)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("row", i+1))
+		}
+
+		if !strict {
+			_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("quotes", len(quotes)))
+	}
+
+	return ids, nil
+}
+
+// DeleteQuoteByID удаляет цитату по ID. При включённом мягком удалении (SOFT_DELETE) цитата
+// не удаляется, а помечается временем удаления.
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) DeleteQuoteByID(ctx context.Context, id int) error {
+	err := h.retry(ctx, func() error {
+		return h.deleteQuoteByID(ctx, id)
+	})
+	if err == nil {
+		h.Counter.Add(-1)
+	}
+	return err
+}
+
+// deleteQuoteByID выполняет одну попытку DeleteQuoteByID в отдельной транзакции.
+func (h Handlers) deleteQuoteByID(ctx context.Context, id int) error {
+	const op = "postgresql.DeleteQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var author string
+
+	if h.Config.SoftDelete {
+		err = tx.QueryRow(h.query(`UPDATE {quotes} SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING author`), id).Scan(&author)
 	} else {
-		rows, err = tx.Query(`SELECT id, author, quote FROM quotes WHERE author = $1`, authorFilter)
+		err = tx.QueryRow(h.query(`DELETE FROM {quotes} WHERE id = $1 AND deleted_at IS NULL RETURNING author`), id).Scan(&author)
 	}
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+			return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
 		}
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return h.fail(op, err, slog.Int("id", id))
 	}
-	defer rows.Close()
 
-	quotes := []Quote{}
+	err = h.audit(ctx, tx, storage.AuditDelete, id, author)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
 
-	for rows.Next() {
-		var quote Quote
+	err = tx.Commit()
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
 
-		err := rows.Scan(&quote.ID, &quote.Author, &quote.Quote)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+	return nil
+}
+
+// RenameAuthor переименовывает автора from в to у всех неудалённых цитат в одной транзакции и возвращает
+// число изменённых цитат. Возвращает sql.ErrNoRows, если цитат автора from нет, и storage.ErrDuplicateEntry,
+// если у автора to уже есть такая же цитата. В журнал аудита пишется одна запись со старым именем.
+func (h Handlers) RenameAuthor(ctx context.Context, from string, to string) (int, error) {
+	var renamed int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		renamed, err = h.renameAuthor(ctx, from, to)
+		return err
+	})
+	return renamed, err
+}
+
+// renameAuthor выполняет одну попытку RenameAuthor в отдельной транзакции.
+func (h Handlers) renameAuthor(ctx context.Context, from string, to string) (int, error) {
+	const op = "postgresql.RenameAuthor()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+	defer tx.Rollback()
+
+	var e *pq.Error
+
+	result, err := tx.ExecContext(ctx, h.query(`UPDATE {quotes} SET author = $2 WHERE author = $1 AND deleted_at IS NULL`), from, to)
+	if err != nil {
+		if errors.As(err, &e) && e.Code == CodeDuplicateEntry {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
 		}
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
 
-		quotes = append(quotes, quote)
+	renamed, err := result.RowsAffected()
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+	if renamed == 0 {
+		return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
 	}
 
-	err = rows.Err()
+	err = h.audit(ctx, tx, storage.AuditRenameAuthor, 0, from)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
 	}
 
-	return quotes, nil
+	return int(renamed), nil
+}
+
+// PurgeQuotes удаляет все цитаты, включая мягко удалённые, вместе с ключами идемпотентности и
+// привязками к категориям и сбрасывает счётчик ID. Сами категории остаются. Возвращает число удалённых цитат.
+func (h Handlers) PurgeQuotes(ctx context.Context) (int, error) {
+	const op = "postgresql.PurgeQuotes()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	// Блокировка до подсчёта: иначе между COUNT и TRUNCATE могли бы добавиться неучтённые цитаты.
+	_, err = tx.Exec(h.query(`LOCK TABLE {quotes} IN ACCESS EXCLUSIVE MODE`))
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	var count int
+
+	err = tx.QueryRow(h.query(`SELECT COUNT(*) FROM {quotes}`)).Scan(&count)
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	_, err = tx.Exec(h.query(`TRUNCATE {quotes}, {idempotency_keys}, {quote_categories} RESTART IDENTITY`))
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	err = h.audit(ctx, tx, storage.AuditPurge, 0, "")
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+	h.Counter.Reset()
+
+	return count, nil
+}
+
+// RestoreQuoteByID снимает пометку об удалении с мягко удалённой цитаты и возвращает её.
+// Возвращает sql.ErrNoRows, если цитаты нет, и storage.ErrNotDeleted, если она не была удалена.
+// При конфликте сериализации или взаимоблокировке транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	var quote storage.Quote
+
+	err := h.retry(ctx, func() error {
+		var err error
+		quote, err = h.restoreQuoteByID(ctx, id)
+		return err
+	})
+	if err == nil {
+		h.Counter.Add(1)
+	}
+	return quote, err
+}
+
+// restoreQuoteByID выполняет одну попытку RestoreQuoteByID в отдельной транзакции.
+func (h Handlers) restoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "postgresql.RestoreQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var deleted bool
+
+	err = tx.QueryRow(h.query(`SELECT deleted_at IS NOT NULL FROM {quotes} WHERE id = $1`), id).Scan(&deleted)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	if !deleted {
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, storage.ErrNotDeleted)
+	}
+
+	var quote storage.Quote
+	var e *pq.Error
+
+	err = tx.QueryRow(h.query(`UPDATE {quotes} SET deleted_at = NULL WHERE id = $1 RETURNING id, author, quote, likes, views, language, source, uuid, slug`), id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if err != nil {
+		if errors.As(err, &e) && e.Code == CodeDuplicateEntry {
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = h.audit(ctx, tx, storage.AuditRestore, quote.ID, quote.Author)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return quote, nil
+}
+
+// LikeQuoteByID атомарно увеличивает счётчик лайков цитаты и возвращает новое значение.
+// Возвращает sql.ErrNoRows, если цитаты нет или она удалена.
+func (h Handlers) LikeQuoteByID(ctx context.Context, id int) (int, error) {
+	const op = "postgresql.LikeQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var likes int
+
+	err = tx.QueryRowContext(ctx, h.query(`UPDATE {quotes} SET likes = likes + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING likes`), id).Scan(&likes)
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return likes, nil
+}
+
+// GetRandomQuote получает случайную цитату и, если включён TRACK_VIEWS, засчитывает ей просмотр.
+// Цитаты автора excludeAuthor не выбираются; пустая строка ничего не исключает, так как автор не бывает пустым.
+// Непустой language оставляет только цитаты на этом языке.
+func (h Handlers) GetRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	const op = "postgresql.GetRandomQuote()"
+
+	var quote storage.Quote
+	var found bool
+	var err error
+
+	if excludeAuthor == "" && language == "" {
+		quote, found, err = h.randomQuoteByCount(ctx)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err)
+		}
+	}
+
+	if !found {
+		err = h.Statements.RandomQuote.QueryRowContext(ctx, excludeAuthor, language).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.String("exclude_author", excludeAuthor), slog.String("language", language))
+		}
+	}
+
+	// Увеличение счётчика атомарно само по себе, поэтому транзакция вокруг чтения не нужна.
+	if h.Config.TrackViews {
+		_, err = h.Statements.AddView.ExecContext(ctx, quote.ID)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.Int("id", quote.ID))
+		}
+		quote.Views++
+	}
+
+	return quote, nil
+}
+
+// randomQuoteByCount выбирает случайную цитату по случайному смещению среди неудалённых цитат, упорядоченных
+// по ID, если включён RANDOM_COUNT_CACHE. Число цитат берётся из Counter, а при холодном кэше считается
+// запросом. Как и RandomQuote, каждая цитата равновероятна, но без сортировки всей таблицы.
+// Возвращает false, если кэш выключен, цитат нет или кэш разошёлся с таблицей (цитаты удалили в обход
+// сервиса); тогда цитату нужно выбрать обычным запросом.
+func (h Handlers) randomQuoteByCount(ctx context.Context) (storage.Quote, bool, error) {
+	if !h.Counter.Enabled() {
+		return storage.Quote{}, false, nil
+	}
+
+	count, version, warm := h.Counter.Load()
+	if !warm {
+		err := h.Statements.CountQuotes.QueryRowContext(ctx).Scan(&count)
+		if err != nil {
+			return storage.Quote{}, false, err
+		}
+		h.Counter.Store(count, version)
+	}
+	if count <= 0 {
+		return storage.Quote{}, false, nil
+	}
+
+	var quote storage.Quote
+
+	err := h.Statements.RandomQuoteAt.QueryRowContext(ctx, rand.N(count)).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		h.Counter.Reset()
+		return storage.Quote{}, false, nil
+	}
+	if err != nil {
+		return storage.Quote{}, false, err
+	}
+
+	return quote, true, nil
+}
+
+// GetFairRandomQuote получает случайную цитату так, чтобы каждый автор выпадал с равной вероятностью:
+// сначала равновероятно выбирается автор, затем равновероятно — его цитата. Если включён TRACK_VIEWS,
+// цитате засчитывается просмотр.
+// Автор excludeAuthor не участвует в выборе (пустая строка ничего не исключает), непустой language
+// оставляет только цитаты на этом языке — и при выборе автора, и при выборе его цитаты.
+func (h Handlers) GetFairRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	const op = "postgresql.GetFairRandomQuote()"
+
+	var quote storage.Quote
+
+	err := h.Statements.FairRandomQuote.QueryRowContext(ctx, excludeAuthor, language).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.String("exclude_author", excludeAuthor), slog.String("language", language))
+	}
+
+	if h.Config.TrackViews {
+		_, err = h.Statements.AddView.ExecContext(ctx, quote.ID)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.Int("id", quote.ID))
+		}
+		quote.Views++
+	}
+
+	return quote, nil
+}
+
+// QuoteLanguages возвращает языки, на которых есть хотя бы одна цитата. Цитаты без языка не учитываются.
+func (h Handlers) QuoteLanguages(ctx context.Context) ([]string, error) {
+	const op = "postgresql.QuoteLanguages()"
+
+	rows, err := h.Replica.QueryContext(ctx, h.query(`SELECT DISTINCT language FROM {quotes} WHERE deleted_at IS NULL AND language <> '' ORDER BY language`))
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+	defer rows.Close()
+
+	languages := []string{}
+
+	for rows.Next() {
+		var language string
+
+		err = rows.Scan(&language)
+		if err != nil {
+			return nil, h.fail(op, err)
+		}
+
+		languages = append(languages, language)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+
+	return languages, nil
+}
+
+// QuoteExists сообщает, есть ли неудалённая цитата с таким автором и текстом — то же сравнение,
+// что и у уникального индекса unique_author_quote, поэтому true означает, что добавление вернёт дубликат.
+func (h Handlers) QuoteExists(ctx context.Context, author string, quote string) (bool, error) {
+	const op = "postgresql.QuoteExists()"
+
+	var exists bool
+
+	err := h.Replica.QueryRowContext(ctx, h.query(`SELECT EXISTS (SELECT 1 FROM {quotes} WHERE author = $1 AND quote = $2 AND deleted_at IS NULL)`), author, quote).Scan(&exists)
+	if err != nil {
+		return false, h.fail(op, err, slog.String("author", author), slog.Int("quote_length", len(quote)))
+	}
+
+	return exists, nil
+}
+
+// GetQuoteIDByUUID возвращает ID цитаты по её UUID, в том числе мягко удалённой (её можно восстановить).
+// Возвращает sql.ErrNoRows, если цитаты нет.
+// Запрос идёт на основной сервер, а не на реплику: за поиском следует изменение (удаление, лайк),
+// и только что добавленная цитата должна находиться сразу.
+func (h Handlers) GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error) {
+	const op = "postgresql.GetQuoteIDByUUID()"
+
+	var id int
+
+	err := h.DB.QueryRowContext(ctx, h.query(`SELECT id FROM {quotes} WHERE uuid = $1`), uuid).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return 0, h.fail(op, err, slog.String("uuid", uuid))
+	}
+
+	return id, nil
+}
+
+// GetQuoteIDBySlug возвращает ID не удалённой цитаты по её slug. Возвращает sql.ErrNoRows, если цитаты нет.
+func (h Handlers) GetQuoteIDBySlug(ctx context.Context, slug string) (int, error) {
+	const op = "postgresql.GetQuoteIDBySlug()"
+
+	var id int
+
+	err := h.Replica.QueryRowContext(ctx, h.query(`SELECT id FROM {quotes} WHERE slug = $1 AND deleted_at IS NULL`), slug).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return 0, h.fail(op, err, slog.String("slug", slug))
+	}
+
+	return id, nil
+}
+
+// GetQuoteByID получает цитату по ID. При включённом подсчёте просмотров (TRACK_VIEWS) счётчик
+// увеличивается тем же запросом, что и читает цитату. Возвращает sql.ErrNoRows, если цитаты нет.
+func (h Handlers) GetQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "postgresql.GetQuoteByID()"
+
+	var quote storage.Quote
+	var err error
+
+	if h.Config.TrackViews {
+		err = h.Statements.ViewQuoteByID.QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	} else {
+		err = h.Statements.QuoteByID.QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	}
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return quote, nil
+}
+
+// GetRecentQuotes возвращает до limit последних добавленных цитат, начиная с самой новой, вместе со временем
+// добавления. Цитаты, добавленные одновременно, упорядочиваются по убыванию ID.
+func (h Handlers) GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error) {
+	const op = "postgresql.GetRecentQuotes()"
+
+	rows, err := h.Replica.QueryContext(
+		ctx,
+		h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug, created_at FROM {quotes} WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1`),
+		limit,
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.CreatedAt)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("limit", limit))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit))
+	}
+
+	return quotes, nil
+}
+
+// GetQuotesByIDs возвращает цитаты с указанными ID в порядке возрастания ID. Отсутствующие
+// и удалённые цитаты просто не попадают в результат; просмотры не засчитываются.
+func (h Handlers) GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error) {
+	const op = "postgresql.GetQuotesByIDs()"
+
+	rows, err := h.Replica.QueryContext(
+		ctx,
+		h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL AND id = ANY($1) ORDER BY id`),
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Any("ids", ids))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Any("ids", ids))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Any("ids", ids))
+	}
+
+	return quotes, nil
+}
+
+// GetSimilarQuotes возвращает до limit цитат, наиболее похожих по тексту на цитату с указанным ID
+// (триграммное сходство pg_trgm). Сама цитата в выборку не попадает. Если цитата не найдена,
+// возвращает sql.ErrNoRows.
+func (h Handlers) GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error) {
+	const op = "postgresql.GetSimilarQuotes()"
+
+	var target string
+
+	err := h.Statements.QuoteByID.QueryRowContext(ctx, id).Scan(new(int), new(string), &target, new(int), new(int), new(string))
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("id", id), slog.Int("limit", limit))
+	}
+
+	// Оператор % отсекает цитаты ниже порога pg_trgm.similarity_threshold и использует GIN-индекс.
+	rows, err := h.Replica.QueryContext(
+		ctx,
+		h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL AND id <> $1 AND quote % $2 ORDER BY similarity(quote, $2) DESC, id LIMIT $3`),
+		id, target, limit,
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("id", id), slog.Int("limit", limit))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("id", id), slog.Int("limit", limit))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("id", id), slog.Int("limit", limit))
+	}
+
+	return quotes, nil
+}
+
+// SearchQuotes выполняет полнотекстовый поиск по автору и тексту цитат и возвращает до limit цитат
+// по убыванию ts_rank. Запрос разбирается websearch_to_tsquery: слова объединяются через AND,
+// фразы в кавычках ищутся целиком, поддерживаются OR и исключение через "-".
+func (h Handlers) SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error) {
+	const op = "postgresql.SearchQuotes()"
+
+	rows, err := h.Replica.QueryContext(
+		ctx,
+		h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes}, websearch_to_tsquery('english', $1) query WHERE deleted_at IS NULL AND search @@ query ORDER BY ts_rank(search, query) DESC, id LIMIT $2`),
+		query, limit,
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("q", query), slog.Int("limit", limit))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return nil, h.fail(op, err, slog.String("q", query), slog.Int("limit", limit))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("q", query), slog.Int("limit", limit))
+	}
+
+	return quotes, nil
+}
+
+// GetStats считает сводные показатели по цитатам в одной транзакции.
+func (h Handlers) GetStats(ctx context.Context) (storage.Stats, error) {
+	const op = "postgresql.GetStats()"
+
+	// REPEATABLE READ даёт всем запросам один снимок данных, поэтому показатели согласованы между собой.
+	tx, err := h.Replica.BeginTx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	var stats storage.Stats
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT COUNT(*), COUNT(DISTINCT author) FROM {quotes} WHERE deleted_at IS NULL`)).Scan(&stats.Quotes, &stats.Authors)
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT author, COUNT(*) FROM {quotes} WHERE deleted_at IS NULL GROUP BY author ORDER BY COUNT(*) DESC, author LIMIT 1`)).Scan(&stats.TopAuthor, &stats.TopAuthorQuotes)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	return stats, nil
+}
+
+// GetTextStats считает показатели текста цитат в одной транзакции. Слова — части текста между
+// пробельными символами, длина — число символов, а не байт.
+func (h Handlers) GetTextStats(ctx context.Context) (storage.TextStats, error) {
+	const op = "postgresql.GetTextStats()"
+
+	tx, err := h.Replica.BeginTx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	var stats storage.TextStats
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT COUNT(*), COALESCE(SUM(array_length(regexp_split_to_array(btrim(quote), '\s+'), 1)), 0), COALESCE(SUM(char_length(quote)), 0), COALESCE(AVG(char_length(quote)), 0) FROM {quotes} WHERE deleted_at IS NULL`)).Scan(&stats.Quotes, &stats.Words, &stats.Characters, &stats.AverageLength)
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	var longest, shortest storage.QuoteLength
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT id, char_length(quote) FROM {quotes} WHERE deleted_at IS NULL ORDER BY char_length(quote) DESC, id LIMIT 1`)).Scan(&longest.ID, &longest.Length)
+	if err == nil {
+		stats.Longest = &longest
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT id, char_length(quote) FROM {quotes} WHERE deleted_at IS NULL ORDER BY char_length(quote), id LIMIT 1`)).Scan(&shortest.ID, &shortest.Length)
+	if err == nil {
+		stats.Shortest = &shortest
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	return stats, nil
+}
+
+// GetAuditLog возвращает записи журнала аудита, начиная с последних.
+func (h Handlers) GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "postgresql.GetAuditLog()"
+
+	rows, err := h.Replica.QueryContext(ctx, h.query(`SELECT id, action, quote_id, author, actor, created_at FROM {audit_log} ORDER BY id DESC LIMIT $1 OFFSET $2`), limit, offset)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+	defer rows.Close()
+
+	entries := []storage.AuditEntry{}
+
+	for rows.Next() {
+		var entry storage.AuditEntry
+		var quoteID sql.NullInt64
+		var author, actor sql.NullString
+
+		err = rows.Scan(&entry.ID, &entry.Action, &quoteID, &author, &actor, &entry.CreatedAt)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+		}
+
+		if quoteID.Valid {
+			id := int(quoteID.Int64)
+			entry.QuoteID = &id
+		}
+		entry.Author = author.String
+		entry.Actor = actor.String
+
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+
+	return entries, nil
+}
+
+// GetAuthors возвращает страницу авторов неудалённых цитат по алфавиту с числом цитат каждого.
+// Непустой prefix оставляет только авторов, чье имя начинается с него без учета регистра.
+func (h Handlers) GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error) {
+	const op = "postgresql.GetAuthors()"
+
+	where := "deleted_at IS NULL"
+	var args []any
+
+	if prefix != "" {
+		args = append(args, storage.LikePrefix(prefix))
+		where += fmt.Sprintf(` AND author ILIKE $%d ESCAPE '\'`, len(args))
+	}
+	args = append(args, limit, offset)
+
+	rows, err := h.Replica.QueryContext(ctx, h.query(fmt.Sprintf(`SELECT author, COUNT(*) FROM {quotes} WHERE %s GROUP BY author ORDER BY author LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))), args...)
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+	defer rows.Close()
+
+	authors := []storage.AuthorQuotes{}
+
+	for rows.Next() {
+		var author storage.AuthorQuotes
+
+		err = rows.Scan(&author.Author, &author.Quotes)
+		if err != nil {
+			return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+		}
+
+		authors = append(authors, author)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+
+	return authors, nil
+}
+
+// CreateCategory создаёт категорию и возвращает её ID. Возвращает storage.ErrDuplicateEntry,
+// если категория с таким названием уже есть.
+func (h Handlers) CreateCategory(ctx context.Context, name string) (int, error) {
+	const op = "postgresql.CreateCategory()"
+
+	var id int
+
+	err := h.DB.QueryRowContext(ctx, h.query(`INSERT INTO {categories} (name) VALUES ($1) RETURNING id`), name).Scan(&id)
+	if err != nil {
+		var e *pq.Error
+		if errors.As(err, &e) && e.Code == CodeDuplicateEntry {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, h.fail(op, err, slog.String("name", name))
+	}
+
+	return id, nil
+}
+
+// GetCategories возвращает все категории по алфавиту с числом неудалённых цитат в каждой.
+func (h Handlers) GetCategories(ctx context.Context) ([]storage.Category, error) {
+	const op = "postgresql.GetCategories()"
+
+	rows, err := h.Replica.QueryContext(ctx, h.query(`SELECT c.id, c.name, COUNT(q.id) FROM {categories} c LEFT JOIN {quote_categories} qc ON qc.category_id = c.id LEFT JOIN {quotes} q ON q.id = qc.quote_id AND q.deleted_at IS NULL GROUP BY c.id, c.name ORDER BY c.name`))
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+	defer rows.Close()
+
+	categories := []storage.Category{}
+
+	for rows.Next() {
+		var category storage.Category
+
+		err = rows.Scan(&category.ID, &category.Name, &category.Quotes)
+		if err != nil {
+			return nil, h.fail(op, err)
+		}
+
+		categories = append(categories, category)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+
+	return categories, nil
+}
+
+// AssignCategory относит цитату к категории. Повторное отнесение ничего не меняет. Возвращает sql.ErrNoRows,
+// если неудалённой цитаты нет, и storage.ErrNoCategory, если нет категории.
+func (h Handlers) AssignCategory(ctx context.Context, quoteID int, categoryID int) error {
+	const op = "postgresql.AssignCategory()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	defer tx.Rollback()
+
+	// FOR SHARE не даёт удалить цитату и категорию до фиксации транзакции.
+	var exists bool
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT EXISTS (SELECT 1 FROM {quotes} WHERE id = $1 AND deleted_at IS NULL FOR SHARE)`), quoteID).Scan(&exists)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	}
+
+	err = tx.QueryRowContext(ctx, h.query(`SELECT EXISTS (SELECT 1 FROM {categories} WHERE id = $1 FOR SHARE)`), categoryID).Scan(&exists)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrNoCategory)
+	}
+
+	_, err = tx.ExecContext(ctx, h.query(`INSERT INTO {quote_categories} (quote_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`), quoteID, categoryID)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+
+	return nil
+}
+
+// quotesWhere строит условие WHERE (с ведущим пробелом) и его аргументы по фильтру цитат.
+// Сортировка и страница фильтра (в том числе курсор After) не учитываются.
+func quotesWhere(filter storage.QuoteFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Authors) > 0 {
+		args = append(args, pq.Array(filter.Authors))
+		conditions = append(conditions, fmt.Sprintf("author = ANY($%d)", len(args)))
+	}
+	if filter.Category > 0 {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("id IN (SELECT quote_id FROM {quote_categories} WHERE category_id = $%d)", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// quotesQuery строит запрос выборки цитат по фильтру. Используется списком и экспортом.
+func (h Handlers) quotesQuery(filter storage.QuoteFilter) (string, []any) {
+	where, args := quotesWhere(filter)
+
+	if filter.After > 0 {
+		args = append(args, filter.After)
+		if where == "" {
+			where = " WHERE " + fmt.Sprintf("id > $%d", len(args))
+		} else {
+			where += " AND " + fmt.Sprintf("id > $%d", len(args))
+		}
+	}
+
+	query := h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug, deleted_at FROM {quotes}` + where)
+	switch filter.Sort {
+	case storage.SortPopular:
+		query += " ORDER BY likes DESC, id"
+	case storage.SortMostViewed:
+		query += " ORDER BY views DESC, id"
+	default:
+		// Без сортировки порядок строк не определён, и страницы могли бы пересекаться.
+		if filter.Limit > 0 {
+			query += " ORDER BY id"
+		}
+	}
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	return query, args
+}
+
+// CountFilteredQuotes считает цитаты, подходящие под фильтр списка, без учёта страницы.
+func (h Handlers) CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error) {
+	const op = "postgresql.CountFilteredQuotes()"
+
+	where, args := quotesWhere(filter)
+
+	var count int
+
+	err := h.Replica.QueryRowContext(ctx, h.query(`SELECT COUNT(*) FROM {quotes}`+where), args...).Scan(&count)
+	if err != nil {
+		return 0, h.fail(op, err, filter.Attrs()...)
+	}
+
+	return count, nil
+}
+
+// GetQuotes получает все цитаты, при необходимости фильтрует по одному или нескольким авторам. Мягко удалённые цитаты
+// возвращаются только при filter.IncludeDeleted.
+func (h Handlers) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	const op = "postgresql.GetQuotes()"
+
+	query, args := h.quotesQuery(filter)
+
+	rows, err := h.Replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		// Клиент мог уйти, не дождавшись ответа: дочитывать большую выборку незачем.
+		err := ctx.Err()
+		if err != nil {
+			return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.DeletedAt)
+		if err != nil {
+			return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+
+	return quotes, nil
+}
+
+// ExportQuotes построчно передаёт цитаты по фильтру в fn, не собирая их в памяти. Если fn возвращает
+// ошибку или ctx отменён (например, клиент отключился), выборка прерывается, а курсор закрывается.
+func (h Handlers) ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error {
+	const op = "postgresql.ExportQuotes()"
+
+	query, args := h.quotesQuery(filter)
+
+	rows, err := h.Replica.QueryContext(ctx, query, args...)
+	if err != nil {
+		return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// Клиент мог уйти, не дождавшись конца выгрузки: дочитывать таблицу незачем.
+		err := ctx.Err()
+		if err != nil {
+			return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.DeletedAt)
+		if err != nil {
+			return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		// Ошибка fn — это ошибка получателя (например, отключившегося клиента), а не БД.
+		err = fn(quote)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+
+	return nil
+}
+
+// CountQuotes возвращает количество цитат, при необходимости только указанного автора.
+func (h Handlers) CountQuotes(ctx context.Context, authorFilter string) (int, error) {
+	const op = "postgresql.CountQuotes()"
+
+	var count int
+	var err error
+
+	if authorFilter == "" {
+		err = h.Statements.CountQuotes.QueryRowContext(ctx).Scan(&count)
+	} else {
+		err = h.Statements.CountQuotesByAuthor.QueryRowContext(ctx, authorFilter).Scan(&count)
+	}
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", authorFilter))
+	}
+
+	return count, nil
 }
@@ -0,0 +1,232 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/lib/pq"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// testDSNVariable — переменная с DSN пустой тестовой БД PostgreSQL. Без неё тесты пакета пропускаются.
+// Тесты создают схему миграциями и удаляют все таблицы после себя, поэтому рабочую БД указывать нельзя.
+const testDSNVariable = "GETCITATION_TEST_POSTGRESQL_DSN"
+
+// migrationsPath — каталог миграций PostgreSQL относительно пакета
+const migrationsPath = "../../../migrations/postgresql"
+
+// newTestHandlers применяет миграции к тестовой БД из GETCITATION_TEST_POSTGRESQL_DSN и открывает хранилище
+// поверх неё. После теста все таблицы удаляются.
+func newTestHandlers(t testing.TB, cfg config.Config) Handlers {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNVariable)
+	if dsn == "" {
+		t.Skipf("%s не задан", testDSNVariable)
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("url.Parse(%s) error = %v", testDSNVariable, err)
+	}
+
+	password, _ := parsed.User.Password()
+
+	cfg.PostgreSQLUsername = parsed.User.Username()
+	cfg.PostgreSQLPassword = password
+	cfg.PostgreSQLHost = parsed.Hostname()
+	cfg.PostgreSQLPort = parsed.Port()
+	cfg.PostgreSQLDatabase = parsed.Path[1:]
+	cfg.PostgreSQLSSL = parsed.Query().Get("sslmode")
+	if cfg.PostgreSQLSSL == "" {
+		cfg.PostgreSQLSSL = "disable"
+	}
+	if cfg.PostgreSQLTable == "" {
+		cfg.PostgreSQLTable = "quotes"
+	}
+
+	m, err := migrate.New("file://"+migrationsPath, dsn)
+	if err != nil {
+		t.Fatalf("migrate.New() error = %v", err)
+	}
+	err = m.Up()
+	if err != nil {
+		t.Fatalf("migrate.Up() error = %v", err)
+	}
+
+	s, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		s.Shutdown()
+
+		err := m.Drop()
+		if err != nil {
+			t.Errorf("migrate.Drop() error = %v", err)
+		}
+		m.Close()
+	})
+
+	return s.DB.Handlers
+}
+
+// mustCreate добавляет цитату и возвращает её ID
+func mustCreate(t testing.TB, h Handlers, author string, quote string) int {
+	t.Helper()
+
+	id, err := h.CreateQuote(context.Background(), storage.Quote{Author: author, Quote: quote})
+	if err != nil {
+		t.Fatalf("CreateQuote(%q, %q) error = %v", author, quote, err)
+	}
+	return id
+}
+
+// seedQuotes добавляет n цитат от 50 авторов одним импортом
+func seedQuotes(t testing.TB, h Handlers, n int) {
+	t.Helper()
+
+	quotes := make([]storage.Quote, n)
+	for i := range quotes {
+		quotes[i] = storage.Quote{Author: fmt.Sprintf("Author %d", i%50), Quote: fmt.Sprintf("Quote %d", i)}
+	}
+
+	_, err := h.ImportQuotes(context.Background(), quotes, true)
+	if err != nil {
+		t.Fatalf("ImportQuotes(%d) error = %v", n, err)
+	}
+}
+
+// BenchmarkRandomQuote сравнивает выбор случайной цитаты подготовленным запросом (Statements.RandomQuote)
+// с тем же запросом, который разбирается при каждом вызове
+func BenchmarkRandomQuote(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	seedQuotes(b, h, 1000)
+
+	b.Run("prepared", func(b *testing.B) {
+		for b.Loop() {
+			_, err := h.GetRandomQuote(context.Background(), "", "")
+			if err != nil {
+				b.Fatalf("GetRandomQuote() error = %v", err)
+			}
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		for b.Loop() {
+			var quote storage.Quote
+			err := h.Replica.QueryRow(
+				h.query(`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM {quotes} WHERE deleted_at IS NULL AND author <> $1 AND ($2 = '' OR language = $2) ORDER BY RANDOM() LIMIT 1`),
+				"", "",
+			).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+			if err != nil {
+				b.Fatalf("QueryRow() error = %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkGetQuotes измеряет выборку страницы списка цитат
+func BenchmarkGetQuotes(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	seedQuotes(b, h, 1000)
+
+	for b.Loop() {
+		_, err := h.GetQuotes(context.Background(), storage.QuoteFilter{Limit: 100})
+		if err != nil {
+			b.Fatalf("GetQuotes() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkReadTransaction сравнивает чтение цитаты без транзакции (как в GetQuoteByID) с тем же чтением
+// в транзакции только для чтения: транзакция добавляет BEGIN и COMMIT, то есть два обращения к серверу
+func BenchmarkReadTransaction(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	id := mustCreate(b, h, "Lev Tolstoy", "All happy families are alike")
+
+	b.Run("direct", func(b *testing.B) {
+		for b.Loop() {
+			_, err := h.GetQuoteByID(context.Background(), id)
+			if err != nil {
+				b.Fatalf("GetQuoteByID() error = %v", err)
+			}
+		}
+	})
+
+	b.Run("transaction", func(b *testing.B) {
+		ctx := context.Background()
+
+		for b.Loop() {
+			tx, err := h.Replica.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				b.Fatalf("BeginTx() error = %v", err)
+			}
+
+			var quote storage.Quote
+			err = tx.StmtContext(ctx, h.Statements.QuoteByID).QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+			if err != nil {
+				tx.Rollback()
+				b.Fatalf("QueryRow() error = %v", err)
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				b.Fatalf("Commit() error = %v", err)
+			}
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pq.Error{Code: CodeSerializationFailure}, true},
+		{"deadlock", &pq.Error{Code: CodeDeadlockDetected}, true},
+		{"wrapped", fmt.Errorf("postgresql.CreateQuote(): %w", &pq.Error{Code: CodeSerializationFailure}), true},
+		{"unique violation", &pq.Error{Code: CodeDuplicateEntry}, false},
+		{"no rows", sql.ErrNoRows, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTable(t *testing.T) {
+	const query = `SELECT c.id FROM {categories} c JOIN {quote_categories} qc ON qc.category_id = c.id JOIN {quotes} q ON q.id = qc.quote_id; DELETE FROM {idempotency_keys}; INSERT INTO {audit_log}`
+
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{"quotes", `SELECT c.id FROM categories c JOIN quote_categories qc ON qc.category_id = c.id JOIN quotes q ON q.id = qc.quote_id; DELETE FROM idempotency_keys; INSERT INTO audit_log`},
+		{"archive", `SELECT c.id FROM archive_categories c JOIN archive_quote_categories qc ON qc.category_id = c.id JOIN archive q ON q.id = qc.quote_id; DELETE FROM archive_idempotency_keys; INSERT INTO archive_audit_log`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := withTable(query, tt.table); got != tt.want {
+				t.Errorf("withTable(%q) = %q, want %q", tt.table, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"getcitation/internal/storage/storagetest"
+	"getcitation/internal/utils/config"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t testing.TB, cfg config.Config) storagetest.Backend {
+		h := newTestHandlers(t, cfg)
+
+		return storagetest.Backend{
+			Store: h,
+			SetCreatedAt: func(id int, createdAt time.Time) error {
+				_, err := h.DB.Exec(h.query(`UPDATE {quotes} SET created_at = $1 WHERE id = $2`), createdAt, id)
+				return err
+			},
+			DeleteRow: func(id int) error {
+				_, err := h.DB.Exec(h.query(`DELETE FROM {quotes} WHERE id = $1`), id)
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package postgresql
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// createArchiveTables создает таблицу цитат archive и её зависимые таблицы с той же схемой, что у таблиц
+// из миграций, и внешними ключами на archive
+func createArchiveTables(t *testing.T, h Handlers) {
+	t.Helper()
+
+	_, err := h.DB.Exec(`
+		CREATE TABLE archive (LIKE quotes INCLUDING ALL);
+		CREATE TABLE archive_categories (LIKE categories INCLUDING ALL);
+		CREATE TABLE archive_quote_categories (
+			LIKE quote_categories INCLUDING ALL,
+			FOREIGN KEY (quote_id) REFERENCES archive (id) ON DELETE CASCADE,
+			FOREIGN KEY (category_id) REFERENCES archive_categories (id) ON DELETE CASCADE
+		);
+		CREATE TABLE archive_idempotency_keys (
+			LIKE idempotency_keys INCLUDING ALL,
+			FOREIGN KEY (quote_id) REFERENCES archive (id) ON DELETE CASCADE
+		);
+		CREATE TABLE archive_audit_log (LIKE audit_log INCLUDING ALL);
+	`)
+	if err != nil {
+		t.Fatalf("create archive tables error = %v", err)
+	}
+}
+
+func TestOtherTableUsesOwnDependentTables(t *testing.T) {
+	quotes := newTestHandlers(t, config.Config{})
+	createArchiveTables(t, quotes)
+
+	cfg := quotes.Config
+	cfg.PostgreSQLTable = "archive"
+
+	s, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New(archive) error = %v", err)
+	}
+	t.Cleanup(func() { s.Shutdown() })
+
+	archive := s.DB.Handlers
+	ctx := context.Background()
+
+	id, _, err := archive.CreateQuoteIdempotent(ctx, "key-1", storage.Quote{Author: "Seneca", Quote: "While we teach, we learn"}, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateQuoteIdempotent() error = %v", err)
+	}
+
+	categoryID, err := archive.CreateCategory(ctx, "Stoicism")
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+
+	err = archive.AssignCategory(ctx, id, categoryID)
+	if err != nil {
+		t.Fatalf("AssignCategory() error = %v", err)
+	}
+
+	got, err := archive.GetQuotes(ctx, storage.QuoteFilter{Category: categoryID})
+	if err != nil {
+		t.Fatalf("GetQuotes(category) error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != id {
+		t.Errorf("GetQuotes(category) = %+v, want quote %d", got, id)
+	}
+
+	entries, err := archive.GetAuditLog(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("GetAuditLog() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("archive audit log has %d entries, want 1", len(entries))
+	}
+
+	// Установка с таблицей quotes не видит ни цитат, ни категорий, ни журнала archive
+	defaults, err := quotes.GetCategories(ctx)
+	if err != nil {
+		t.Fatalf("GetCategories() error = %v", err)
+	}
+	entries, err = quotes.GetAuditLog(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("GetAuditLog() error = %v", err)
+	}
+	if len(defaults) != 0 || len(entries) != 0 {
+		t.Errorf("quotes installation sees %d categories and %d audit entries, want none", len(defaults), len(entries))
+	}
+}
@@ -0,0 +1,25 @@
+package postgresql
+
+import (
+	"testing"
+
+	"getcitation/internal/utils/config"
+)
+
+func TestWarmUpKeepsConnectionsIdle(t *testing.T) {
+	const conns = 5
+
+	h := newTestHandlers(t, config.Config{PostgreSQLWarmUpConns: conns})
+
+	// Прогретые соединения остаются в пуле, а не закрываются при возврате сверх лимита по умолчанию (2)
+	if idle := h.DB.Stats().Idle; idle < conns {
+		t.Errorf("idle connections after warm-up = %d, want at least %d", idle, conns)
+	}
+
+	if err := h.DB.Ping(); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if idle := h.DB.Stats().Idle; idle < conns {
+		t.Errorf("idle connections after a query = %d, want at least %d", idle, conns)
+	}
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Пределы паузы между повторами транзакции: пауза удваивается и к ней добавляется случайная
+// добавка, чтобы конфликтующие транзакции не повторялись одновременно.
+const (
+	retryMinBackoff time.Duration = 10 * time.Millisecond
+	retryMaxBackoff time.Duration = 500 * time.Millisecond
+)
+
+// Retry выполняет транзакционную операцию fn и повторяет её до retries раз, если isRetryable признаёт
+// ошибку временной (конфликт сериализации, взаимоблокировка). fn должна целиком открывать и завершать
+// транзакцию: повтор имеет смысл только с начала. Прочие ошибки и отмена ctx возвращаются сразу.
+func Retry(ctx context.Context, retries int, isRetryable func(error) bool, fn func() error) error {
+	backoff := retryMinBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff + rand.N(backoff)):
+		}
+
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var (
+	errRetryable = errors.New("serialization failure")
+	errPermanent = errors.New("constraint violation")
+)
+
+func isTestRetryable(err error) bool {
+	return errors.Is(err, errRetryable)
+}
+
+// failing возвращает операцию, которая первые failures вызовов завершается ошибкой err, а потом успешно,
+// и счётчик её вызовов
+func failing(failures int, err error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int
+		err       error
+		wantErr   error
+		wantCalls int
+	}{
+		{"success", 3, 0, nil, nil, 1},
+		{"eventual success", 3, 2, errRetryable, nil, 3},
+		{"success on last retry", 3, 3, errRetryable, nil, 4},
+		{"retries exhausted", 3, 10, errRetryable, errRetryable, 4},
+		{"retries disabled", 0, 1, errRetryable, errRetryable, 1},
+		{"not retryable", 3, 1, errPermanent, errPermanent, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, calls := failing(tt.failures, tt.err)
+
+			err := Retry(context.Background(), tt.retries, isTestRetryable, fn)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("Retry() called fn %d times, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fn, calls := failing(10, errRetryable)
+
+	err := Retry(ctx, 3, isTestRetryable, fn)
+	if !errors.Is(err, errRetryable) {
+		t.Errorf("Retry() error = %v, want %v", err, errRetryable)
+	}
+	if *calls != 1 {
+		t.Errorf("Retry() called fn %d times after cancel, want 1", *calls)
+	}
+}
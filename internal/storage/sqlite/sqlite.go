@@ -0,0 +1,1573 @@
+// Пакет sqlite предоставляет функциональность для работы с БД SQLite — альтернативным бэкендом
+// для небольших одноузловых установок без PostgreSQL.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"getcitation/internal/lib/slug"
+	"getcitation/internal/storage"
+	"getcitation/internal/utils"
+	"getcitation/internal/utils/config"
+)
+
+var (
+	CodeDuplicateEntry = sqlite3.SQLITE_CONSTRAINT_UNIQUE
+)
+
+// isRetryable сообщает, что транзакция не получила блокировку файла БД (её держит другое соединение
+// или процесс) и её можно повторить.
+func isRetryable(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	switch e.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+// isConnectionError отличает потерю доступа к файлу БД (ошибки ввода-вывода, удалённый или
+// подменённый файл) от ошибок самого запроса.
+func isConnectionError(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return errors.Is(err, driver.ErrBadConn)
+	}
+
+	// Младший байт расширенного кода — основной код ошибки.
+	switch e.Code() & 0xff {
+	case sqlite3.SQLITE_IOERR, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_NOTADB:
+		return true
+	}
+	return false
+}
+
+// Storage содержит подключение к БД и основные зависимости (логгер, конфиг).
+type Storage struct {
+	DB     DB
+	Log    *slog.Logger
+	Config config.Config
+}
+
+// DB содержит подключение к SQLite и обработчики.
+type DB struct {
+	Implementation *sql.DB
+	Handlers       Handlers
+}
+
+// New открывает файл БД SQLite.
+func New(config config.Config, log *slog.Logger) (Storage, error) {
+	const op = "sqlite.New()"
+
+	// Последняя миграция определяется до подключения: без каталога миграций проверка готовности
+	// невозможна, и сервис не должен стартовать.
+	var migration uint
+	if config.ReadyCheckMigrations {
+		var err error
+
+		migration, err = storage.LatestMigration(config.MigrationsPath)
+		if err != nil {
+			return Storage{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	conn := utils.BuildSQLiteDSN(config)
+
+	db, err := sql.Open("sqlite", conn)
+	if err != nil {
+		return Storage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// SQLite допускает только одного писателя одновременно.
+	db.SetMaxOpenConns(1)
+
+	err = db.Ping()
+	if err != nil {
+		return Storage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	statements, err := prepareStatements(db)
+	if err != nil {
+		db.Close()
+		return Storage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	health := storage.NewHealth(isConnectionError, log, db)
+
+	var counter storage.QuoteCounter
+	if config.RandomCountCache {
+		counter = storage.NewQuoteCounter()
+	}
+
+	return Storage{
+		DB: DB{
+			Implementation: db,
+			Handlers: Handlers{
+				DB:         db,
+				Statements: statements,
+				Health:     health,
+				Counter:    counter,
+				Migration:  migration,
+				Log:        log,
+				Config:     config,
+			},
+		},
+		Log:    log,
+		Config: config,
+	}, nil
+}
+
+// Shutdown корректно закрывает соединение с БД.
+func (s Storage) Shutdown() error {
+	const op = "sqlite.Shutdown()"
+
+	s.DB.Handlers.Health.Close()
+
+	err := s.DB.Handlers.Statements.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.DB.Implementation.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// PoolStats возвращает статистику пула соединений.
+func (s Storage) PoolStats() sql.DBStats {
+	return s.DB.Handlers.PoolStats()
+}
+
+// Handlers — структура для реализации логики работы с конкретной таблицей или сущностью.
+type Handlers struct {
+	DB         *sql.DB
+	Statements Statements
+	Health     storage.Health
+	Counter    storage.QuoteCounter
+	Migration  uint
+	Log        *slog.Logger
+	Config     config.Config
+}
+
+// Statements содержит запросы горячих путей чтения, подготовленные один раз при подключении.
+// Чтения выполняются без транзакций; внутри транзакции запрос нужно привязывать через tx.Stmt.
+type Statements struct {
+	RandomQuote         *sql.Stmt
+	RandomQuoteAt       *sql.Stmt
+	FairRandomQuote     *sql.Stmt
+	AddView             *sql.Stmt
+	QuoteByID           *sql.Stmt
+	ViewQuoteByID       *sql.Stmt
+	CountQuotes         *sql.Stmt
+	CountQuotesByAuthor *sql.Stmt
+}
+
+// prepareStatements подготавливает все запросы Statements. При ошибке уже подготовленные запросы закрываются.
+func prepareStatements(db *sql.DB) (Statements, error) {
+	const op = "sqlite.prepareStatements()"
+
+	var statements Statements
+
+	// Как и в PostgreSQL, RandomQuote сортирует существующие строки, поэтому дыры в ID его не смещают.
+	queries := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&statements.RandomQuote, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE deleted_at IS NULL AND author <> ?1 AND (?2 = '' OR language = ?2) ORDER BY RANDOM() LIMIT 1`},
+		{&statements.RandomQuoteAt, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE deleted_at IS NULL ORDER BY id LIMIT 1 OFFSET ?`},
+		{&statements.FairRandomQuote, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE deleted_at IS NULL AND (?2 = '' OR language = ?2) AND author = (SELECT author FROM quotes WHERE deleted_at IS NULL AND author <> ?1 AND (?2 = '' OR language = ?2) GROUP BY author ORDER BY RANDOM() LIMIT 1) ORDER BY RANDOM() LIMIT 1`},
+		{&statements.AddView, `UPDATE quotes SET views = views + 1 WHERE id = ?`},
+		{&statements.QuoteByID, `SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE id = ? AND deleted_at IS NULL`},
+		{&statements.ViewQuoteByID, `UPDATE quotes SET views = views + 1 WHERE id = ? AND deleted_at IS NULL RETURNING id, author, quote, likes, views, language, source, uuid, slug`},
+		{&statements.CountQuotes, `SELECT COUNT(*) FROM quotes WHERE deleted_at IS NULL`},
+		{&statements.CountQuotesByAuthor, `SELECT COUNT(*) FROM quotes WHERE author = ? AND deleted_at IS NULL`},
+	}
+
+	for _, q := range queries {
+		stmt, err := db.Prepare(q.query)
+		if err != nil {
+			statements.Close()
+			return Statements{}, fmt.Errorf("%s: %w", op, err)
+		}
+		*q.stmt = stmt
+	}
+
+	return statements, nil
+}
+
+// Close закрывает все подготовленные запросы.
+func (s Statements) Close() error {
+	var errs []error
+
+	for _, stmt := range []*sql.Stmt{s.RandomQuote, s.RandomQuoteAt, s.FairRandomQuote, s.AddView, s.QuoteByID, s.ViewQuoteByID, s.CountQuotes, s.CountQuotesByAuthor} {
+		if stmt == nil {
+			continue
+		}
+		errs = append(errs, stmt.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// isDuplicateEntry проверяет, что ошибка вызвана нарушением ограничения уникальности.
+func isDuplicateEntry(err error) bool {
+	var e *sqlite.Error
+	return errors.As(err, &e) && e.Code() == CodeDuplicateEntry
+}
+
+// fail добавляет к ошибке запроса контекст: op и входные параметры запроса (attrs), чтобы по логам
+// было видно, на каких данных запрос упал. Ошибки соединения помечаются storage.ErrUnavailable,
+// а хранилище до восстановления соединения считается недоступным.
+func (h Handlers) fail(op string, err error, attrs ...slog.Attr) error {
+	err = h.Health.Observe(err)
+
+	if len(attrs) == 0 {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return fmt.Errorf("%s [%s]: %w", op, storage.ErrorContext(attrs...), err)
+}
+
+// retry выполняет транзакцию fn, повторяя её до TX_RETRIES раз при временных конфликтах.
+func (h Handlers) retry(ctx context.Context, fn func() error) error {
+	return storage.Retry(ctx, h.Config.TxRetries, isRetryable, fn)
+}
+
+// PoolStats возвращает статистику пула соединений.
+func (h Handlers) PoolStats() sql.DBStats {
+	return h.DB.Stats()
+}
+
+// Ready проверяет, что БД доступна и отвечает на ping. Если задан Migration (READY_CHECK_MIGRATIONS=true),
+// еще и что схема БД не отстает от этой миграции — иначе *storage.MigrationError.
+func (h Handlers) Ready(ctx context.Context) error {
+	const op = "sqlite.Ready()"
+
+	err := h.Health.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if h.Migration > 0 {
+		err = storage.CheckMigration(ctx, h.DB, h.Migration)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// setSlug записывает slug только что добавленной цитаты. Slug содержит ID, который известен только после
+// вставки, поэтому он записывается отдельным запросом в той же транзакции.
+func setSlug(ctx context.Context, tx *sql.Tx, id int, quote storage.Quote) error {
+	_, err := tx.ExecContext(ctx, `UPDATE quotes SET slug = ? WHERE id = ?`, slug.Make(quote.Author, quote.Quote, id), id)
+	return err
+}
+
+// audit добавляет запись в журнал аудита в транзакции изменения, чтобы журнал не расходился с данными.
+// Нулевой quoteID и пустые author и инициатор из контекста записываются как NULL.
+func audit(ctx context.Context, tx *sql.Tx, action string, quoteID int, author string) error {
+	actor := storage.ActorFromContext(ctx)
+
+	_, err := tx.Exec(
+		`INSERT INTO audit_log (action, quote_id, author, actor, created_at) VALUES (?, ?, ?, ?, ?)`,
+		action,
+		sql.NullInt64{Int64: int64(quoteID), Valid: quoteID != 0},
+		sql.NullString{String: author, Valid: author != ""},
+		sql.NullString{String: actor, Valid: actor != ""},
+		time.Now().UTC(),
+	)
+	return err
+}
+
+// CreateQuote добавляет новую цитату в базу.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) CreateQuote(ctx context.Context, quote storage.Quote) (int, error) {
+	var id int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		id, err = h.createQuote(ctx, quote)
+		return err
+	})
+	if err == nil {
+		h.Counter.Add(1)
+	}
+	return id, err
+}
+
+// createQuote выполняет одну попытку CreateQuote в отдельной транзакции.
+func (h Handlers) createQuote(ctx context.Context, quote storage.Quote) (int, error) {
+	const op = "sqlite.CreateQuote()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	// У created_at нет значения по умолчанию: SQLite не позволяет добавить столбец с CURRENT_TIMESTAMP.
+	err = tx.QueryRow(`INSERT INTO quotes (author, quote, language, source, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`, quote.Author, quote.Quote, quote.Language, quote.Source, time.Now().UTC()).Scan(&id)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = setSlug(ctx, tx, id, quote)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = audit(ctx, tx, storage.AuditCreate, id, quote.Author)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	return id, nil
+}
+
+// UpsertQuote добавляет цитату, а если такая же неудалённая цитата уже есть, возвращает её ID и true
+// вместо storage.ErrDuplicateEntry.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) UpsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error) {
+	var id int
+	var existing bool
+
+	err := h.retry(ctx, func() error {
+		var err error
+		id, existing, err = h.upsertQuote(ctx, quote)
+		return err
+	})
+	if err == nil && !existing {
+		h.Counter.Add(1)
+	}
+	return id, existing, err
+}
+
+// upsertQuote выполняет одну попытку UpsertQuote в отдельной транзакции.
+func (h Handlers) upsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error) {
+	const op = "sqlite.UpsertQuote()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+	defer tx.Rollback()
+
+	var id int
+	var existing bool
+
+	// При конфликте DO NOTHING не возвращает строку, и ID существующей цитаты читается отдельным запросом.
+	// Если ту цитату успели удалить между INSERT и SELECT, SELECT тоже ничего не находит: тогда вставка
+	// повторяется и, раз конфликта больше нет, добавляет цитату как новую.
+	for attempt := 1; ; attempt++ {
+		err = tx.QueryRowContext(ctx, `INSERT INTO quotes (author, quote, language, source, created_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (author, quote) WHERE deleted_at IS NULL DO NOTHING RETURNING id`, quote.Author, quote.Quote, quote.Language, quote.Source, time.Now().UTC()).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+
+		err = tx.QueryRowContext(ctx, `SELECT id FROM quotes WHERE author = ? AND quote = ? AND deleted_at IS NULL`, quote.Author, quote.Quote).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) || attempt == storage.UpsertAttempts {
+			existing = true
+			break
+		}
+	}
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	if existing {
+		err = tx.Commit()
+		if err != nil {
+			return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+		}
+
+		return id, true, nil
+	}
+
+	err = setSlug(ctx, tx, id, quote)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = audit(ctx, tx, storage.AuditCreate, id, quote.Author)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	return id, false, nil
+}
+
+// CreateQuoteIdempotent добавляет новую цитату и привязывает к ней ключ идемпотентности.
+// Если ключ уже использовался и ещё не истёк, возвращает ID ранее созданной цитаты и true, а если
+// с ключом создавалась другая цитата — storage.ErrKeyReused.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) CreateQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error) {
+	var id int
+	var replayed bool
+
+	err := h.retry(ctx, func() error {
+		var err error
+		id, replayed, err = h.createQuoteIdempotent(ctx, key, quote, ttl)
+		return err
+	})
+	if err == nil && !replayed {
+		h.Counter.Add(1)
+	}
+	return id, replayed, err
+}
+
+// createQuoteIdempotent выполняет одну попытку CreateQuoteIdempotent в отдельной транзакции. Одновременные
+// запросы с одним ключом не мешают друг другу: у пула одно соединение, и транзакции идут по очереди.
+func (h Handlers) createQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error) {
+	const op = "sqlite.CreateQuoteIdempotent()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, now.Add(-ttl))
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	var id int
+	var fingerprint sql.NullString
+
+	err = tx.QueryRowContext(ctx, `SELECT quote_id, fingerprint FROM idempotency_keys WHERE key = ?`, key).Scan(&id, &fingerprint)
+	if err == nil {
+		// У ключей, записанных до миграции 17, отпечатка нет, и их повтор принимается как раньше.
+		if fingerprint.Valid && fingerprint.String != storage.Fingerprint(quote) {
+			return 0, false, fmt.Errorf("%s: %w", op, storage.ErrKeyReused)
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+		}
+		return id, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO quotes (author, quote, language, source, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`, quote.Author, quote.Quote, quote.Language, quote.Source, now).Scan(&id)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return 0, false, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO idempotency_keys (key, quote_id, fingerprint, created_at) VALUES (?, ?, ?, ?)`, key, id, storage.Fingerprint(quote), now)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = setSlug(ctx, tx, id, quote)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = audit(ctx, tx, storage.AuditCreate, id, quote.Author)
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, false, h.fail(op, err, slog.String("key", key), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+	}
+
+	return id, false, nil
+}
+
+// ImportQuotes добавляет цитаты одной транзакцией и возвращает ID добавленных цитат в порядке quotes;
+// 0 означает, что такая цитата уже есть и строка пропущена. Каждая строка вставляется в своей точке
+// сохранения, поэтому дубликат откатывает только себя, а не весь импорт. Прочие ошибки прерывают импорт целиком.
+// При strict точек сохранения нет: первый дубликат откатывает весь импорт и возвращается как *storage.RowError
+// с номером строки, оборачивающий storage.ErrDuplicateEntry.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) ImportQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error) {
+	var ids []int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		ids, err = h.importQuotes(ctx, quotes, strict)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if id != 0 {
+			h.Counter.Add(1)
+		}
+	}
+	return ids, nil
+}
+
+// importQuotes выполняет одну попытку ImportQuotes в отдельной транзакции.
+func (h Handlers) importQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error) {
+	const op = "sqlite.ImportQuotes()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("quotes", len(quotes)))
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, len(quotes))
+	now := time.Now().UTC()
+
+	for i, quote := range quotes {
+		if !strict {
+			_, err = tx.ExecContext(ctx, `SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+		}
+
+		err = tx.QueryRowContext(ctx, `INSERT INTO quotes (author, quote, language, source, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`, quote.Author, quote.Quote, quote.Language, quote.Source, now).Scan(&ids[i])
+		if err != nil {
+			if !(isDuplicateEntry(err)) {
+				return nil, h.fail(op, err, slog.Int("row", i+1), slog.String("author", quote.Author), slog.Int("quote_length", len(quote.Quote)))
+			}
+			if strict {
+				return nil, fmt.Errorf("%s: %w", op, &storage.RowError{Row: i, Err: storage.ErrDuplicateEntry})
+			}
+
+			_, err = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+			ids[i] = 0
+			continue
+		}
+
+		err = setSlug(ctx, tx, ids[i], quote)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("row", i+1))
+		}
+
+		err = audit(ctx, tx, storage.AuditImport, ids[i], quote.Author)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("row", i+1))
+		}
+
+		if !strict {
+			_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT import_quote`)
+			if err != nil {
+				return nil, h.fail(op, err, slog.Int("row", i+1))
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("quotes", len(quotes)))
+	}
+
+	return ids, nil
+}
+
+// DeleteQuoteByID удаляет цитату по ID. При включённом мягком удалении (SOFT_DELETE) цитата
+// не удаляется, а помечается временем удаления.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) DeleteQuoteByID(ctx context.Context, id int) error {
+	err := h.retry(ctx, func() error {
+		return h.deleteQuoteByID(ctx, id)
+	})
+	if err == nil {
+		h.Counter.Add(-1)
+	}
+	return err
+}
+
+// deleteQuoteByID выполняет одну попытку DeleteQuoteByID в отдельной транзакции.
+func (h Handlers) deleteQuoteByID(ctx context.Context, id int) error {
+	const op = "sqlite.DeleteQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var author string
+
+	if h.Config.SoftDelete {
+		err = tx.QueryRow(`UPDATE quotes SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING author`, time.Now().UTC(), id).Scan(&author)
+	} else {
+		err = tx.QueryRow(`DELETE FROM quotes WHERE id = ? AND deleted_at IS NULL RETURNING author`, id).Scan(&author)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = audit(ctx, tx, storage.AuditDelete, id, author)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", id))
+	}
+
+	return nil
+}
+
+// RenameAuthor переименовывает автора from в to у всех неудалённых цитат в одной транзакции и возвращает
+// число изменённых цитат. Возвращает sql.ErrNoRows, если цитат автора from нет, и storage.ErrDuplicateEntry,
+// если у автора to уже есть такая же цитата. В журнал аудита пишется одна запись со старым именем.
+func (h Handlers) RenameAuthor(ctx context.Context, from string, to string) (int, error) {
+	var renamed int
+
+	err := h.retry(ctx, func() error {
+		var err error
+		renamed, err = h.renameAuthor(ctx, from, to)
+		return err
+	})
+	return renamed, err
+}
+
+// renameAuthor выполняет одну попытку RenameAuthor в отдельной транзакции.
+func (h Handlers) renameAuthor(ctx context.Context, from string, to string) (int, error) {
+	const op = "sqlite.RenameAuthor()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE quotes SET author = ? WHERE author = ? AND deleted_at IS NULL`, to, from)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+
+	renamed, err := result.RowsAffected()
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+	if renamed == 0 {
+		return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	}
+
+	err = audit(ctx, tx, storage.AuditRenameAuthor, 0, from)
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("from", from), slog.String("to", to))
+	}
+
+	return int(renamed), nil
+}
+
+// PurgeQuotes удаляет все цитаты, включая мягко удалённые (ключи идемпотентности и привязки к категориям
+// удаляются каскадно), и сбрасывает счётчик ID. Возвращает число удалённых цитат.
+func (h Handlers) PurgeQuotes(ctx context.Context) (int, error) {
+	const op = "sqlite.PurgeQuotes()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM quotes`)
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM sqlite_sequence WHERE name = 'quotes'`)
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	err = audit(ctx, tx, storage.AuditPurge, 0, "")
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err)
+	}
+	h.Counter.Reset()
+
+	return int(affected), nil
+}
+
+// RestoreQuoteByID снимает пометку об удалении с мягко удалённой цитаты и возвращает её.
+// Возвращает sql.ErrNoRows, если цитаты нет, и storage.ErrNotDeleted, если она не была удалена.
+// Если БД занята другим писателем, транзакция повторяется до TX_RETRIES раз.
+func (h Handlers) RestoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	var quote storage.Quote
+
+	err := h.retry(ctx, func() error {
+		var err error
+		quote, err = h.restoreQuoteByID(ctx, id)
+		return err
+	})
+	if err == nil {
+		h.Counter.Add(1)
+	}
+	return quote, err
+}
+
+// restoreQuoteByID выполняет одну попытку RestoreQuoteByID в отдельной транзакции.
+func (h Handlers) restoreQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "sqlite.RestoreQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var deleted bool
+
+	err = tx.QueryRow(`SELECT deleted_at IS NOT NULL FROM quotes WHERE id = ?`, id).Scan(&deleted)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	if !deleted {
+		return storage.Quote{}, fmt.Errorf("%s: %w", op, storage.ErrNotDeleted)
+	}
+
+	var quote storage.Quote
+
+	err = tx.QueryRow(`UPDATE quotes SET deleted_at = NULL WHERE id = ? RETURNING id, author, quote, likes, views, language, source, uuid, slug`, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return storage.Quote{}, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = audit(ctx, tx, storage.AuditRestore, quote.ID, quote.Author)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return quote, nil
+}
+
+// LikeQuoteByID атомарно увеличивает счётчик лайков цитаты и возвращает новое значение.
+// Возвращает sql.ErrNoRows, если цитаты нет или она удалена.
+func (h Handlers) LikeQuoteByID(ctx context.Context, id int) (int, error) {
+	const op = "sqlite.LikeQuoteByID()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+	defer tx.Rollback()
+
+	var likes int
+
+	err = tx.QueryRowContext(ctx, `UPDATE quotes SET likes = likes + 1 WHERE id = ? AND deleted_at IS NULL RETURNING likes`, id).Scan(&likes)
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return likes, nil
+}
+
+// GetRandomQuote получает случайную цитату и, если включён TRACK_VIEWS, засчитывает ей просмотр.
+// Цитаты автора excludeAuthor не выбираются; пустая строка ничего не исключает, так как автор не бывает пустым.
+// Непустой language оставляет только цитаты на этом языке.
+func (h Handlers) GetRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	const op = "sqlite.GetRandomQuote()"
+
+	var quote storage.Quote
+	var found bool
+	var err error
+
+	if excludeAuthor == "" && language == "" {
+		quote, found, err = h.randomQuoteByCount(ctx)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err)
+		}
+	}
+
+	if !found {
+		err = h.Statements.RandomQuote.QueryRowContext(ctx, excludeAuthor, language).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.String("exclude_author", excludeAuthor), slog.String("language", language))
+		}
+	}
+
+	// Увеличение счётчика атомарно само по себе, поэтому транзакция вокруг чтения не нужна.
+	if h.Config.TrackViews {
+		_, err = h.Statements.AddView.ExecContext(ctx, quote.ID)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.Int("id", quote.ID))
+		}
+		quote.Views++
+	}
+
+	return quote, nil
+}
+
+// randomQuoteByCount выбирает случайную цитату по случайному смещению среди неудалённых цитат, упорядоченных
+// по ID, если включён RANDOM_COUNT_CACHE. Число цитат берётся из Counter, а при холодном кэше считается
+// запросом. Как и RandomQuote, каждая цитата равновероятна, но без сортировки всей таблицы.
+// Возвращает false, если кэш выключен, цитат нет или кэш разошёлся с таблицей (цитаты удалили в обход
+// сервиса); тогда цитату нужно выбрать обычным запросом.
+func (h Handlers) randomQuoteByCount(ctx context.Context) (storage.Quote, bool, error) {
+	if !h.Counter.Enabled() {
+		return storage.Quote{}, false, nil
+	}
+
+	count, version, warm := h.Counter.Load()
+	if !warm {
+		err := h.Statements.CountQuotes.QueryRowContext(ctx).Scan(&count)
+		if err != nil {
+			return storage.Quote{}, false, err
+		}
+		h.Counter.Store(count, version)
+	}
+	if count <= 0 {
+		return storage.Quote{}, false, nil
+	}
+
+	var quote storage.Quote
+
+	err := h.Statements.RandomQuoteAt.QueryRowContext(ctx, rand.N(count)).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		h.Counter.Reset()
+		return storage.Quote{}, false, nil
+	}
+	if err != nil {
+		return storage.Quote{}, false, err
+	}
+
+	return quote, true, nil
+}
+
+// GetFairRandomQuote получает случайную цитату так, чтобы каждый автор выпадал с равной вероятностью:
+// сначала равновероятно выбирается автор, затем равновероятно — его цитата. Если включён TRACK_VIEWS,
+// цитате засчитывается просмотр.
+// Автор excludeAuthor не участвует в выборе (пустая строка ничего не исключает), непустой language
+// оставляет только цитаты на этом языке — и при выборе автора, и при выборе его цитаты.
+func (h Handlers) GetFairRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error) {
+	const op = "sqlite.GetFairRandomQuote()"
+
+	var quote storage.Quote
+
+	err := h.Statements.FairRandomQuote.QueryRowContext(ctx, excludeAuthor, language).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.String("exclude_author", excludeAuthor), slog.String("language", language))
+	}
+
+	if h.Config.TrackViews {
+		_, err = h.Statements.AddView.ExecContext(ctx, quote.ID)
+		if err != nil {
+			return storage.Quote{}, h.fail(op, err, slog.Int("id", quote.ID))
+		}
+		quote.Views++
+	}
+
+	return quote, nil
+}
+
+// QuoteLanguages возвращает языки, на которых есть хотя бы одна цитата. Цитаты без языка не учитываются.
+func (h Handlers) QuoteLanguages(ctx context.Context) ([]string, error) {
+	const op = "sqlite.QuoteLanguages()"
+
+	rows, err := h.DB.QueryContext(ctx, `SELECT DISTINCT language FROM quotes WHERE deleted_at IS NULL AND language <> '' ORDER BY language`)
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+	defer rows.Close()
+
+	languages := []string{}
+
+	for rows.Next() {
+		var language string
+
+		err = rows.Scan(&language)
+		if err != nil {
+			return nil, h.fail(op, err)
+		}
+
+		languages = append(languages, language)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+
+	return languages, nil
+}
+
+// QuoteExists сообщает, есть ли неудалённая цитата с таким автором и текстом — то же сравнение,
+// что и у уникального индекса unique_author_quote, поэтому true означает, что добавление вернёт дубликат.
+func (h Handlers) QuoteExists(ctx context.Context, author string, quote string) (bool, error) {
+	const op = "sqlite.QuoteExists()"
+
+	var exists bool
+
+	err := h.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM quotes WHERE author = ? AND quote = ? AND deleted_at IS NULL)`, author, quote).Scan(&exists)
+	if err != nil {
+		return false, h.fail(op, err, slog.String("author", author), slog.Int("quote_length", len(quote)))
+	}
+
+	return exists, nil
+}
+
+// GetQuoteIDByUUID возвращает ID цитаты по её UUID, в том числе мягко удалённой (её можно восстановить).
+// Возвращает sql.ErrNoRows, если цитаты нет.
+func (h Handlers) GetQuoteIDByUUID(ctx context.Context, uuid string) (int, error) {
+	const op = "sqlite.GetQuoteIDByUUID()"
+
+	var id int
+
+	err := h.DB.QueryRowContext(ctx, `SELECT id FROM quotes WHERE uuid = ?`, uuid).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return 0, h.fail(op, err, slog.String("uuid", uuid))
+	}
+
+	return id, nil
+}
+
+// GetQuoteIDBySlug возвращает ID не удалённой цитаты по её slug. Возвращает sql.ErrNoRows, если цитаты нет.
+func (h Handlers) GetQuoteIDBySlug(ctx context.Context, slug string) (int, error) {
+	const op = "sqlite.GetQuoteIDBySlug()"
+
+	var id int
+
+	err := h.DB.QueryRowContext(ctx, `SELECT id FROM quotes WHERE slug = ? AND deleted_at IS NULL`, slug).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+		}
+		return 0, h.fail(op, err, slog.String("slug", slug))
+	}
+
+	return id, nil
+}
+
+// GetQuoteByID получает цитату по ID. При включённом подсчёте просмотров (TRACK_VIEWS) счётчик
+// увеличивается тем же запросом, что и читает цитату. Возвращает sql.ErrNoRows, если цитаты нет.
+func (h Handlers) GetQuoteByID(ctx context.Context, id int) (storage.Quote, error) {
+	const op = "sqlite.GetQuoteByID()"
+
+	var quote storage.Quote
+	var err error
+
+	if h.Config.TrackViews {
+		err = h.Statements.ViewQuoteByID.QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	} else {
+		err = h.Statements.QuoteByID.QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+	}
+	if err != nil {
+		return storage.Quote{}, h.fail(op, err, slog.Int("id", id))
+	}
+
+	return quote, nil
+}
+
+// GetRecentQuotes возвращает до limit последних добавленных цитат, начиная с самой новой, вместе со временем
+// добавления. Цитаты, добавленные одновременно, упорядочиваются по убыванию ID.
+func (h Handlers) GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error) {
+	const op = "sqlite.GetRecentQuotes()"
+
+	rows, err := h.DB.QueryContext(
+		ctx,
+		`SELECT id, author, quote, likes, views, language, source, uuid, slug, created_at FROM quotes WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.CreatedAt)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("limit", limit))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit))
+	}
+
+	return quotes, nil
+}
+
+// GetQuotesByIDs возвращает цитаты с указанными ID в порядке возрастания ID. Отсутствующие
+// и удалённые цитаты просто не попадают в результат; просмотры не засчитываются.
+func (h Handlers) GetQuotesByIDs(ctx context.Context, ids []int) ([]storage.Quote, error) {
+	const op = "sqlite.GetQuotesByIDs()"
+
+	if len(ids) == 0 {
+		return []storage.Quote{}, nil
+	}
+
+	// Массивов в SQLite нет, поэтому под каждый ID подставляется свой параметр.
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := h.DB.QueryContext(
+		ctx,
+		`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE deleted_at IS NULL AND id IN (?`+strings.Repeat(", ?", len(ids)-1)+`) ORDER BY id`,
+		args...,
+	)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Any("ids", ids))
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Any("ids", ids))
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Any("ids", ids))
+	}
+
+	return quotes, nil
+}
+
+// GetSimilarQuotes не поддерживается: в SQLite нет триграммного поиска pg_trgm.
+func (h Handlers) GetSimilarQuotes(ctx context.Context, id int, limit int) ([]storage.Quote, error) {
+	const op = "sqlite.GetSimilarQuotes()"
+
+	return nil, fmt.Errorf("%s: %w", op, storage.ErrNotSupported)
+}
+
+// SearchQuotes не поддерживается: полнотекстовый поиск построен на tsvector PostgreSQL.
+func (h Handlers) SearchQuotes(ctx context.Context, query string, limit int) ([]storage.Quote, error) {
+	const op = "sqlite.SearchQuotes()"
+
+	return nil, fmt.Errorf("%s: %w", op, storage.ErrNotSupported)
+}
+
+// GetStats считает сводные показатели по цитатам в одной транзакции.
+func (h Handlers) GetStats(ctx context.Context) (storage.Stats, error) {
+	const op = "sqlite.GetStats()"
+
+	// Транзакции SQLite сериализуемы, поэтому все запросы видят один снимок данных.
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	var stats storage.Stats
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*), COUNT(DISTINCT author) FROM quotes WHERE deleted_at IS NULL`).Scan(&stats.Quotes, &stats.Authors)
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT author, COUNT(*) FROM quotes WHERE deleted_at IS NULL GROUP BY author ORDER BY COUNT(*) DESC, author LIMIT 1`).Scan(&stats.TopAuthor, &stats.TopAuthorQuotes)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.Stats{}, h.fail(op, err)
+	}
+
+	return stats, nil
+}
+
+// GetTextStats считает показатели текста цитат в одной транзакции. Длина — число символов, а не байт.
+// Регулярных выражений в SQLite нет, поэтому слова считаются в Go по тем же правилам, что и в PostgreSQL:
+// части текста между пробельными символами.
+func (h Handlers) GetTextStats(ctx context.Context) (storage.TextStats, error) {
+	const op = "sqlite.GetTextStats()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+	defer tx.Rollback()
+
+	var stats storage.TextStats
+
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(length(quote)), 0), COALESCE(AVG(length(quote)), 0) FROM quotes WHERE deleted_at IS NULL`).Scan(&stats.Quotes, &stats.Characters, &stats.AverageLength)
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT quote FROM quotes WHERE deleted_at IS NULL`)
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var quote string
+		if err := rows.Scan(&quote); err != nil {
+			return storage.TextStats{}, h.fail(op, err)
+		}
+		stats.Words += len(strings.Fields(quote))
+	}
+	if err := rows.Err(); err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	var longest, shortest storage.QuoteLength
+
+	err = tx.QueryRowContext(ctx, `SELECT id, length(quote) FROM quotes WHERE deleted_at IS NULL ORDER BY length(quote) DESC, id LIMIT 1`).Scan(&longest.ID, &longest.Length)
+	if err == nil {
+		stats.Longest = &longest
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT id, length(quote) FROM quotes WHERE deleted_at IS NULL ORDER BY length(quote), id LIMIT 1`).Scan(&shortest.ID, &shortest.Length)
+	if err == nil {
+		stats.Shortest = &shortest
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return storage.TextStats{}, h.fail(op, err)
+	}
+
+	return stats, nil
+}
+
+// GetAuditLog возвращает записи журнала аудита, начиная с последних.
+func (h Handlers) GetAuditLog(ctx context.Context, limit int, offset int) ([]storage.AuditEntry, error) {
+	const op = "sqlite.GetAuditLog()"
+
+	rows, err := h.DB.QueryContext(ctx, `SELECT id, action, quote_id, author, actor, created_at FROM audit_log ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+	defer rows.Close()
+
+	entries := []storage.AuditEntry{}
+
+	for rows.Next() {
+		var entry storage.AuditEntry
+		var quoteID sql.NullInt64
+		var author, actor sql.NullString
+
+		err = rows.Scan(&entry.ID, &entry.Action, &quoteID, &author, &actor, &entry.CreatedAt)
+		if err != nil {
+			return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+		}
+
+		if quoteID.Valid {
+			id := int(quoteID.Int64)
+			entry.QuoteID = &id
+		}
+		entry.Author = author.String
+		entry.Actor = actor.String
+
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+
+	return entries, nil
+}
+
+// GetAuthors возвращает страницу авторов неудалённых цитат по алфавиту с числом цитат каждого.
+// Непустой prefix оставляет только авторов, чье имя начинается с него без учета регистра (SQLite
+// не различает регистр только у латинских букв).
+func (h Handlers) GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error) {
+	const op = "sqlite.GetAuthors()"
+
+	where := "deleted_at IS NULL"
+	var args []any
+
+	if prefix != "" {
+		args = append(args, storage.LikePrefix(prefix))
+		where += ` AND author LIKE ? ESCAPE '\'`
+	}
+	args = append(args, limit, offset)
+
+	rows, err := h.DB.QueryContext(ctx, `SELECT author, COUNT(*) FROM quotes WHERE `+where+` GROUP BY author ORDER BY author LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+	defer rows.Close()
+
+	authors := []storage.AuthorQuotes{}
+
+	for rows.Next() {
+		var author storage.AuthorQuotes
+
+		err = rows.Scan(&author.Author, &author.Quotes)
+		if err != nil {
+			return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+		}
+
+		authors = append(authors, author)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, slog.String("prefix", prefix), slog.Int("limit", limit), slog.Int("offset", offset))
+	}
+
+	return authors, nil
+}
+
+// CreateCategory создаёт категорию и возвращает её ID. Возвращает storage.ErrDuplicateEntry,
+// если категория с таким названием уже есть.
+func (h Handlers) CreateCategory(ctx context.Context, name string) (int, error) {
+	const op = "sqlite.CreateCategory()"
+
+	var id int
+
+	err := h.DB.QueryRowContext(ctx, `INSERT INTO categories (name, created_at) VALUES (?, ?) RETURNING id`, name, time.Now().UTC()).Scan(&id)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDuplicateEntry)
+		}
+		return 0, h.fail(op, err, slog.String("name", name))
+	}
+
+	return id, nil
+}
+
+// GetCategories возвращает все категории по алфавиту с числом неудалённых цитат в каждой.
+func (h Handlers) GetCategories(ctx context.Context) ([]storage.Category, error) {
+	const op = "sqlite.GetCategories()"
+
+	rows, err := h.DB.QueryContext(ctx, `SELECT c.id, c.name, COUNT(q.id) FROM categories c LEFT JOIN quote_categories qc ON qc.category_id = c.id LEFT JOIN quotes q ON q.id = qc.quote_id AND q.deleted_at IS NULL GROUP BY c.id, c.name ORDER BY c.name`)
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+	defer rows.Close()
+
+	categories := []storage.Category{}
+
+	for rows.Next() {
+		var category storage.Category
+
+		err = rows.Scan(&category.ID, &category.Name, &category.Quotes)
+		if err != nil {
+			return nil, h.fail(op, err)
+		}
+
+		categories = append(categories, category)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err)
+	}
+
+	return categories, nil
+}
+
+// AssignCategory относит цитату к категории. Повторное отнесение ничего не меняет. Возвращает sql.ErrNoRows,
+// если неудалённой цитаты нет, и storage.ErrNoCategory, если нет категории.
+func (h Handlers) AssignCategory(ctx context.Context, quoteID int, categoryID int) error {
+	const op = "sqlite.AssignCategory()"
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	defer tx.Rollback()
+
+	var exists bool
+
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM quotes WHERE id = ? AND deleted_at IS NULL)`, quoteID).Scan(&exists)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE id = ?)`, categoryID).Scan(&exists)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrNoCategory)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO quote_categories (quote_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING`, quoteID, categoryID)
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return h.fail(op, err, slog.Int("id", quoteID), slog.Int("category", categoryID))
+	}
+
+	return nil
+}
+
+// quotesWhere строит условие WHERE (с ведущим пробелом) и его аргументы по фильтру цитат.
+// Сортировка и страница фильтра (в том числе курсор After) не учитываются.
+func quotesWhere(filter storage.QuoteFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Authors) > 0 {
+		for _, author := range filter.Authors {
+			args = append(args, author)
+		}
+		conditions = append(conditions, "author IN (?"+strings.Repeat(", ?", len(filter.Authors)-1)+")")
+	}
+	if filter.Category > 0 {
+		args = append(args, filter.Category)
+		conditions = append(conditions, "id IN (SELECT quote_id FROM quote_categories WHERE category_id = ?)")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// quotesQuery строит запрос выборки цитат по фильтру. Используется списком и экспортом.
+func quotesQuery(filter storage.QuoteFilter) (string, []any) {
+	where, args := quotesWhere(filter)
+
+	if filter.After > 0 {
+		args = append(args, filter.After)
+		if where == "" {
+			where = " WHERE " + "id > ?"
+		} else {
+			where += " AND " + "id > ?"
+		}
+	}
+
+	query := `SELECT id, author, quote, likes, views, language, source, uuid, slug, deleted_at FROM quotes` + where
+	switch filter.Sort {
+	case storage.SortPopular:
+		query += " ORDER BY likes DESC, id"
+	case storage.SortMostViewed:
+		query += " ORDER BY views DESC, id"
+	default:
+		// Без сортировки порядок строк не определён, и страницы могли бы пересекаться.
+		if filter.Limit > 0 {
+			query += " ORDER BY id"
+		}
+	}
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += " LIMIT ? OFFSET ?"
+	}
+
+	return query, args
+}
+
+// CountFilteredQuotes считает цитаты, подходящие под фильтр списка, без учёта страницы.
+func (h Handlers) CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error) {
+	const op = "sqlite.CountFilteredQuotes()"
+
+	where, args := quotesWhere(filter)
+
+	var count int
+
+	err := h.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM quotes`+where, args...).Scan(&count)
+	if err != nil {
+		return 0, h.fail(op, err, filter.Attrs()...)
+	}
+
+	return count, nil
+}
+
+// GetQuotes получает все цитаты, при необходимости фильтрует по одному или нескольким авторам. Мягко удалённые цитаты
+// возвращаются только при filter.IncludeDeleted.
+func (h Handlers) GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error) {
+	const op = "sqlite.GetQuotes()"
+
+	query, args := quotesQuery(filter)
+
+	rows, err := h.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+	defer rows.Close()
+
+	quotes := []storage.Quote{}
+
+	for rows.Next() {
+		// Клиент мог уйти, не дождавшись ответа: дочитывать большую выборку незачем.
+		err := ctx.Err()
+		if err != nil {
+			return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.DeletedAt)
+		if err != nil {
+			return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+
+	return quotes, nil
+}
+
+// ExportQuotes построчно передаёт цитаты по фильтру в fn, не собирая их в памяти. Если fn возвращает
+// ошибку или ctx отменён (например, клиент отключился), выборка прерывается, а курсор закрывается.
+func (h Handlers) ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error {
+	const op = "sqlite.ExportQuotes()"
+
+	query, args := quotesQuery(filter)
+
+	rows, err := h.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// Клиент мог уйти, не дождавшись конца выгрузки: дочитывать таблицу незачем.
+		err := ctx.Err()
+		if err != nil {
+			return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		var quote storage.Quote
+
+		err = rows.Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug, &quote.DeletedAt)
+		if err != nil {
+			return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+		}
+
+		// Ошибка fn — это ошибка получателя (например, отключившегося клиента), а не БД.
+		err = fn(quote)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return h.fail(op, err, append(filter.Attrs(), slog.String("query", query))...)
+	}
+
+	return nil
+}
+
+// CountQuotes возвращает количество цитат, при необходимости только указанного автора.
+func (h Handlers) CountQuotes(ctx context.Context, authorFilter string) (int, error) {
+	const op = "sqlite.CountQuotes()"
+
+	var count int
+	var err error
+
+	if authorFilter == "" {
+		err = h.Statements.CountQuotes.QueryRowContext(ctx).Scan(&count)
+	} else {
+		err = h.Statements.CountQuotesByAuthor.QueryRowContext(ctx, authorFilter).Scan(&count)
+	}
+	if err != nil {
+		return 0, h.fail(op, err, slog.String("author", authorFilter))
+	}
+
+	return count, nil
+}
@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// migrationsPath — каталог миграций SQLite относительно пакета
+const migrationsPath = "../../../migrations/sqlite"
+
+// newTestHandlers создаёт файл БД во временном каталоге теста, применяет к нему все миграции и
+// открывает хранилище поверх него.
+func newTestHandlers(t testing.TB, cfg config.Config) Handlers {
+	t.Helper()
+
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "getcitation.db")
+
+	m, err := migrate.New("file://"+migrationsPath, "sqlite://"+cfg.SQLitePath+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("migrate.New() error = %v", err)
+	}
+	err = m.Up()
+	if err != nil {
+		t.Fatalf("migrate.Up() error = %v", err)
+	}
+	sourceErr, dbErr := m.Close()
+	if sourceErr != nil || dbErr != nil {
+		t.Fatalf("migrate.Close() error = %v, %v", sourceErr, dbErr)
+	}
+
+	s, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		s.Shutdown()
+	})
+
+	return s.DB.Handlers
+}
+
+// mustCreate добавляет цитату и возвращает её ID
+func mustCreate(t testing.TB, h Handlers, author string, quote string) int {
+	t.Helper()
+
+	id, err := h.CreateQuote(context.Background(), storage.Quote{Author: author, Quote: quote})
+	if err != nil {
+		t.Fatalf("CreateQuote(%q, %q) error = %v", author, quote, err)
+	}
+	return id
+}
+
+// seedQuotes добавляет n цитат от 50 авторов одним импортом
+func seedQuotes(t testing.TB, h Handlers, n int) {
+	t.Helper()
+
+	quotes := make([]storage.Quote, n)
+	for i := range quotes {
+		quotes[i] = storage.Quote{Author: fmt.Sprintf("Author %d", i%50), Quote: fmt.Sprintf("Quote %d", i)}
+	}
+
+	_, err := h.ImportQuotes(context.Background(), quotes, true)
+	if err != nil {
+		t.Fatalf("ImportQuotes(%d) error = %v", n, err)
+	}
+}
+
+// BenchmarkRandomQuote сравнивает выбор случайной цитаты подготовленным запросом (Statements.RandomQuote)
+// с тем же запросом, который разбирается при каждом вызове
+func BenchmarkRandomQuote(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	seedQuotes(b, h, 1000)
+
+	b.Run("prepared", func(b *testing.B) {
+		for b.Loop() {
+			_, err := h.GetRandomQuote(context.Background(), "", "")
+			if err != nil {
+				b.Fatalf("GetRandomQuote() error = %v", err)
+			}
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		for b.Loop() {
+			var quote storage.Quote
+			err := h.DB.QueryRow(
+				`SELECT id, author, quote, likes, views, language, source, uuid, slug FROM quotes WHERE deleted_at IS NULL AND author <> ?1 AND (?2 = '' OR language = ?2) ORDER BY RANDOM() LIMIT 1`,
+				"", "",
+			).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+			if err != nil {
+				b.Fatalf("QueryRow() error = %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkGetQuotes измеряет выборку страницы списка цитат
+func BenchmarkGetQuotes(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	seedQuotes(b, h, 1000)
+
+	for b.Loop() {
+		_, err := h.GetQuotes(context.Background(), storage.QuoteFilter{Limit: 100})
+		if err != nil {
+			b.Fatalf("GetQuotes() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkReadTransaction сравнивает чтение цитаты без транзакции (как в GetQuoteByID) с тем же чтением
+// в транзакции только для чтения
+func BenchmarkReadTransaction(b *testing.B) {
+	h := newTestHandlers(b, config.Config{})
+	id := mustCreate(b, h, "Lev Tolstoy", "All happy families are alike")
+
+	b.Run("direct", func(b *testing.B) {
+		for b.Loop() {
+			_, err := h.GetQuoteByID(context.Background(), id)
+			if err != nil {
+				b.Fatalf("GetQuoteByID() error = %v", err)
+			}
+		}
+	})
+
+	b.Run("transaction", func(b *testing.B) {
+		ctx := context.Background()
+
+		for b.Loop() {
+			tx, err := h.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				b.Fatalf("BeginTx() error = %v", err)
+			}
+
+			var quote storage.Quote
+			err = tx.StmtContext(ctx, h.Statements.QuoteByID).QueryRowContext(ctx, id).Scan(&quote.ID, &quote.Author, &quote.Quote, &quote.Likes, &quote.Views, &quote.Language, &quote.Source, &quote.UUID, &quote.Slug)
+			if err != nil {
+				tx.Rollback()
+				b.Fatalf("QueryRow() error = %v", err)
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				b.Fatalf("Commit() error = %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"getcitation/internal/storage/storagetest"
+	"getcitation/internal/utils/config"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t testing.TB, cfg config.Config) storagetest.Backend {
+		h := newTestHandlers(t, cfg)
+
+		return storagetest.Backend{
+			Store: h,
+			SetCreatedAt: func(id int, createdAt time.Time) error {
+				_, err := h.DB.Exec(`UPDATE quotes SET created_at = ? WHERE id = ?`, createdAt, id)
+				return err
+			},
+			DeleteRow: func(id int) error {
+				_, err := h.DB.Exec(`DELETE FROM quotes WHERE id = ?`, id)
+				return err
+			},
+		}
+	})
+}
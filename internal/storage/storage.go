@@ -0,0 +1,194 @@
+// Пакет storage содержит общие для всех бэкендов хранилища типы и ошибки.
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Поддерживаемые бэкенды хранилища.
+const (
+	BackendPostgreSQL = "postgresql"
+	BackendSQLite     = "sqlite"
+)
+
+// Поддерживаемые порядки сортировки списка цитат.
+const (
+	SortPopular    = "popular"
+	SortMostViewed = "most_viewed"
+)
+
+// UpsertAttempts — сколько раз UpsertQuote пробует вставить цитату, если конфликтующую с ней цитату удаляют
+// между вставкой и чтением её ID.
+const UpsertAttempts = 3
+
+var (
+	ErrDuplicateEntry = fmt.Errorf("duplicate entry")
+	ErrNotDeleted     = fmt.Errorf("entry is not deleted")
+	ErrNotSupported   = fmt.Errorf("operation is not supported by the storage backend")
+	ErrNoCategory     = fmt.Errorf("category does not exist")
+	ErrKeyReused      = fmt.Errorf("idempotency key was used with a different request")
+)
+
+// RowError — ошибка строки пакетной операции: Row — номер строки в переданном срезе, начиная с 0.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row+1, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Quote - объект цитаты. UUID — случайный идентификатор цитаты, который, в отличие от последовательного ID,
+// не выдаёт число цитат и не позволяет их перебирать. Slug — читаемый идентификатор для ссылок из автора,
+// начала цитаты и ID. Language — тег языка BCP 47 (пустой, если язык не указан). Source — произведение
+// или URL, откуда взята цитата (пустой, если не указан). CreatedAt заполняется только там, где нужно время
+// добавления (последние цитаты, лента RSS). DeletedAt заполнен только у мягко удалённых цитат.
+type Quote struct {
+	ID        int        `json:"id"`
+	UUID      string     `json:"uuid,omitempty"`
+	Slug      string     `json:"slug,omitempty"`
+	Author    string     `json:"author"`
+	Quote     string     `json:"quote"`
+	Likes     int        `json:"likes"`
+	Views     int        `json:"views"`
+	Language  string     `json:"language,omitempty"`
+	Source    string     `json:"source,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// QuoteFilter - параметры выборки списка цитат. Пустой Authors не ограничивает выборку,
+// иначе возвращаются цитаты любого из перечисленных авторов. Sort пустой или один из Sort*.
+// Limit больше нуля включает постраничную выборку: не больше Limit цитат, начиная с Offset.
+// After больше нуля оставляет только цитаты с ID больше After (курсор) — вместе с сортировкой по ID
+// страницы не сдвигаются, когда между запросами цитаты добавляются или удаляются.
+// Category больше нуля оставляет только цитаты, отнесённые к категории с этим ID.
+type QuoteFilter struct {
+	Authors        []string
+	IncludeDeleted bool
+	Sort           string
+	Limit          int
+	Offset         int
+	After          int
+	Category       int
+}
+
+// Stats — сводные показатели по неудалённым цитатам. TopAuthor — автор с наибольшим числом цитат
+// (при равенстве — первый по алфавиту), пустой, если цитат нет.
+type Stats struct {
+	Quotes          int    `json:"quotes"`
+	Authors         int    `json:"authors"`
+	TopAuthor       string `json:"top_author,omitempty"`
+	TopAuthorQuotes int    `json:"top_author_quotes,omitempty"`
+}
+
+// TextStats — показатели текста неудалённых цитат: сколько в них слов и символов, средняя длина
+// в символах, самая длинная и самая короткая цитата (при равенстве — с меньшим ID). Longest и Shortest
+// пустые, если цитат нет.
+type TextStats struct {
+	Quotes        int          `json:"quotes"`
+	Words         int          `json:"words"`
+	Characters    int          `json:"characters"`
+	AverageLength float64      `json:"average_length"`
+	Longest       *QuoteLength `json:"longest,omitempty"`
+	Shortest      *QuoteLength `json:"shortest,omitempty"`
+}
+
+// QuoteLength — ID цитаты и длина ее текста в символах
+type QuoteLength struct {
+	ID     int `json:"id"`
+	Length int `json:"length"`
+}
+
+// AuthorQuotes — автор и число его неудалённых цитат.
+type AuthorQuotes struct {
+	Author string `json:"author"`
+	Quotes int    `json:"quotes"`
+}
+
+// Category — категория цитат и число отнесённых к ней неудалённых цитат.
+type Category struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Quotes int    `json:"quotes"`
+}
+
+// PoolStats — состояние пула соединений с БД для диагностики его исчерпания: сколько соединений
+// открыто, занято и простаивает, сколько раз и как долго запросы ждали свободного соединения.
+type PoolStats struct {
+	MaxOpen        int   `json:"max_open"`
+	Open           int   `json:"open"`
+	InUse          int   `json:"in_use"`
+	Idle           int   `json:"idle"`
+	WaitCount      int64 `json:"wait_count"`
+	WaitDurationMS int64 `json:"wait_duration_ms"`
+}
+
+// NewPoolStats переводит статистику database/sql в PoolStats.
+func NewPoolStats(stats sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpen:        stats.MaxOpenConnections,
+		Open:           stats.OpenConnections,
+		InUse:          stats.InUse,
+		Idle:           stats.Idle,
+		WaitCount:      stats.WaitCount,
+		WaitDurationMS: stats.WaitDuration.Milliseconds(),
+	}
+}
+
+// Attrs возвращает параметры фильтра для контекста ошибок хранилища.
+func (f QuoteFilter) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("authors", strings.Join(f.Authors, ",")),
+		slog.Bool("include_deleted", f.IncludeDeleted),
+		slog.String("sort", f.Sort),
+		slog.Int("limit", f.Limit),
+		slog.Int("offset", f.Offset),
+		slog.Int("after", f.After),
+		slog.Int("category", f.Category),
+	}
+}
+
+// Fingerprint возвращает отпечаток полей цитаты, с которыми её создают (SHA-256 в hex). По нему повторный
+// запрос с тем же ключом идемпотентности отличается от запроса с другим телом.
+func Fingerprint(quote Quote) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{quote.Author, quote.Quote, quote.Language, quote.Source}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// LikePrefix строит шаблон LIKE для поиска строк, начинающихся с prefix. Символы %, _ и \ в prefix
+// экранируются обратной косой чертой, поэтому в запросе нужно указать ESCAPE '\'.
+func LikePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
+
+// maxContextValueLength — сколько символов значения попадает в контекст ошибки.
+const maxContextValueLength = 128
+
+// ErrorContext форматирует входные параметры запроса для текста ошибки: "id=5 author=Confucius".
+// Длинные значения обрезаются. Тексты цитат и секреты передавать сюда нельзя — ошибки попадают в логи.
+func ErrorContext(attrs ...slog.Attr) string {
+	parts := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		value := attr.Value.String()
+		if utf8.RuneCountInString(value) > maxContextValueLength {
+			value = string([]rune(value)[:maxContextValueLength]) + "…"
+		}
+		parts = append(parts, attr.Key+"="+value)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,83 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"getcitation/internal/utils/config"
+)
+
+func testGetAuthorsPrefix(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	mustCreate(t, h, "Seneca", "Luck is what happens")
+	mustCreate(t, h, "Seneca", "While we teach, we learn")
+	mustCreate(t, h, "SENECA the Elder", "Errare humanum est")
+	mustCreate(t, h, "sentinel", "Watch")
+	mustCreate(t, h, "Sen_chan", "Underscore")
+	mustCreate(t, h, "Confucius", "Life is simple")
+	deleted := mustCreate(t, h, "Senator", "Deleted")
+
+	err := h.DeleteQuoteByID(ctx, deleted)
+	if err != nil {
+		t.Fatalf("DeleteQuoteByID() error = %v", err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"Sen", []string{"SENECA the Elder", "Sen_chan", "Seneca", "sentinel"}},
+		{"sen", []string{"SENECA the Elder", "Sen_chan", "Seneca", "sentinel"}},
+		{"SENECA", []string{"SENECA the Elder", "Seneca"}},
+		{"sEnEcA ", []string{"SENECA the Elder"}},
+		// _ и % в префиксе — обычные символы, а не шаблоны LIKE
+		{"Sen_", []string{"Sen_chan"}},
+		{"%", nil},
+		{"Plato", nil},
+		{"", []string{"Confucius", "SENECA the Elder", "Sen_chan", "Seneca", "sentinel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			authors, err := h.GetAuthors(ctx, tt.prefix, 100, 0)
+			if err != nil {
+				t.Fatalf("GetAuthors(%q) error = %v", tt.prefix, err)
+			}
+			if authors == nil {
+				t.Fatalf("GetAuthors(%q) = nil, want an empty slice", tt.prefix)
+			}
+
+			var names []string
+			for _, author := range authors {
+				names = append(names, author.Author)
+
+				want := 1
+				if author.Author == "Seneca" {
+					want = 2
+				}
+				if author.Quotes != want {
+					t.Errorf("author %q has %d quotes, want %d", author.Author, author.Quotes, want)
+				}
+			}
+
+			// Порядок сравнения строк зависит от сортировки БД, поэтому сравнивается набор
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("GetAuthors(%q) = %q, want %q", tt.prefix, names, tt.want)
+			}
+		})
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		authors, err := h.GetAuthors(ctx, "sen", 2, 1)
+		if err != nil {
+			t.Fatalf("GetAuthors() error = %v", err)
+		}
+		if len(authors) != 2 {
+			t.Errorf("GetAuthors(limit=2, offset=1) returned %d authors, want 2", len(authors))
+		}
+	})
+}
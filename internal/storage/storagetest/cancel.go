@@ -0,0 +1,86 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// cancelAfter — контекст, который считается отменённым, начиная с calls-го вызова Err. Done у него nil,
+// поэтому database/sql и драйвер не прерывают запрос сами, и отмену замечает только проверка ctx.Err()
+// в цикле по строкам: так отмена приходится ровно на середину выборки.
+type cancelAfter struct {
+	context.Context
+	calls *int
+}
+
+func (c cancelAfter) Err() error {
+	*c.calls--
+	if *c.calls <= 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func testGetQuotesStopsOnCancel(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	seedQuotes(t, h, 100)
+
+	calls := 3
+	quotes, err := h.GetQuotes(cancelAfter{Context: context.Background(), calls: &calls}, storage.QuoteFilter{Limit: 100})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetQuotes() error = %v, want %v", err, context.Canceled)
+	}
+	if quotes != nil {
+		t.Errorf("GetQuotes() returned %d quotes after cancel, want none", len(quotes))
+	}
+	if calls != 0 {
+		t.Errorf("GetQuotes() kept scanning after cancel: %d extra ctx.Err() calls", -calls)
+	}
+
+	// Отмена клиентом — не сбой БД: хранилище остаётся готовым, а соединение вернулось в пул
+	err = h.Ready(context.Background())
+	if err != nil {
+		t.Errorf("Ready() after cancel error = %v", err)
+	}
+	mustCreate(t, h, "Lev Tolstoy", "All happy families are alike")
+}
+
+func testGetQuotesCancelledBeforeQuery(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	seedQuotes(t, h, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := h.GetQuotes(ctx, storage.QuoteFilter{Limit: 100})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetQuotes() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func testExportQuotesStopsOnCancel(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	seedQuotes(t, h, 100)
+
+	calls := 3
+	exported := 0
+	err := h.ExportQuotes(cancelAfter{Context: context.Background(), calls: &calls}, storage.QuoteFilter{}, func(storage.Quote) error {
+		exported++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExportQuotes() error = %v, want %v", err, context.Canceled)
+	}
+	if exported != 2 {
+		t.Errorf("ExportQuotes() exported %d quotes before cancel, want 2", exported)
+	}
+
+	err = h.Ready(context.Background())
+	if err != nil {
+		t.Errorf("Ready() after cancel error = %v", err)
+	}
+}
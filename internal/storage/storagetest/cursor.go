@@ -0,0 +1,60 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// TestGetQuotesCursorStable листает список курсором After и между страницами добавляет и удаляет цитаты:
+// каждая цитата, которая существует на момент чтения своей страницы, должна попасться ровно один раз
+func testGetQuotesCursorStable(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	var ids []int
+	for i := range 10 {
+		ids = append(ids, mustCreate(t, h, "Confucius", fmt.Sprintf("Quote %d", i)))
+	}
+
+	const limit = 3
+
+	var seen []int
+	after := 0
+	for page := 0; ; page++ {
+		quotes, err := h.GetQuotes(ctx, storage.QuoteFilter{Limit: limit, After: after})
+		if err != nil {
+			t.Fatalf("GetQuotes(after=%d) error = %v", after, err)
+		}
+		if len(quotes) == 0 {
+			break
+		}
+		for _, quote := range quotes {
+			seen = append(seen, quote.ID)
+		}
+		after = quotes[len(quotes)-1].ID
+
+		if page == 0 {
+			// Удаление уже прочитанной цитаты сдвинуло бы offset на одну строку вперед, и следующая
+			// страница пропустила бы цитату; курсору это не мешает
+			err = h.DeleteQuoteByID(ctx, ids[0])
+			if err != nil {
+				t.Fatalf("DeleteQuoteByID(%d) error = %v", ids[0], err)
+			}
+			err = h.DeleteQuoteByID(ctx, ids[7])
+			if err != nil {
+				t.Fatalf("DeleteQuoteByID(%d) error = %v", ids[7], err)
+			}
+			ids = append(ids, mustCreate(t, h, "Lev Tolstoy", "Added mid-traversal"))
+		}
+	}
+
+	want := slices.Concat(ids[:7], ids[8:])
+	if !slices.Equal(seen, want) {
+		t.Errorf("traversal returned IDs %v, want %v", seen, want)
+	}
+}
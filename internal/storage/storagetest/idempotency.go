@@ -0,0 +1,90 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+func testCreateQuoteIdempotentReplaysKey(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+	quote := storage.Quote{Author: "Seneca", Quote: "Luck is what happens when preparation meets opportunity."}
+
+	id, replayed, err := h.CreateQuoteIdempotent(ctx, "key-1", quote, time.Hour)
+	if err != nil || replayed {
+		t.Fatalf("first CreateQuoteIdempotent() = %d, %t, %v; want new quote", id, replayed, err)
+	}
+
+	again, replayed, err := h.CreateQuoteIdempotent(ctx, "key-1", quote, time.Hour)
+	if err != nil {
+		t.Fatalf("second CreateQuoteIdempotent() error = %v", err)
+	}
+	if again != id || !replayed {
+		t.Errorf("second CreateQuoteIdempotent() = %d, %t; want %d, true", again, replayed, id)
+	}
+}
+
+func testCreateQuoteIdempotentRejectsDifferentBody(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	_, _, err := h.CreateQuoteIdempotent(ctx, "key-1", storage.Quote{Author: "Seneca", Quote: "First"}, time.Hour)
+	if err != nil {
+		t.Fatalf("first CreateQuoteIdempotent() error = %v", err)
+	}
+
+	_, _, err = h.CreateQuoteIdempotent(ctx, "key-1", storage.Quote{Author: "Seneca", Quote: "Second"}, time.Hour)
+	if !errors.Is(err, storage.ErrKeyReused) {
+		t.Errorf("CreateQuoteIdempotent() with another body error = %v, want %v", err, storage.ErrKeyReused)
+	}
+}
+
+func testCreateQuoteIdempotentConcurrentSameKey(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{TxRetries: 3})
+	quote := storage.Quote{Author: "Seneca", Quote: "We suffer more often in imagination than in reality."}
+
+	const requests = 8
+
+	type result struct {
+		id       int
+		replayed bool
+		err      error
+	}
+
+	results := make([]result, requests)
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var r result
+			r.id, r.replayed, r.err = h.CreateQuoteIdempotent(context.Background(), "shared-key", quote, time.Hour)
+			results[i] = r
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for i, r := range results {
+		if r.err != nil {
+			t.Fatalf("request %d error = %v", i, r.err)
+		}
+		if r.id != results[0].id {
+			t.Errorf("request %d got ID %d, want %d", i, r.id, results[0].id)
+		}
+		if !r.replayed {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d requests created a quote, want exactly 1", created)
+	}
+}
@@ -0,0 +1,95 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// importFile — импорт с новой цитатой, цитатой из БД, повтором внутри файла и еще одной новой цитатой
+var importFile = []storage.Quote{
+	{Author: "Confucius", Quote: "Life is simple"},
+	{Author: "Lev Tolstoy", Quote: "All happy families are alike"},
+	{Author: "Confucius", Quote: "Life is simple"},
+	{Author: "Seneca", Quote: "Luck is what happens"},
+}
+
+func testImportQuotesLenient(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	existing := mustCreate(t, h, "Lev Tolstoy", "All happy families are alike")
+
+	ids, err := h.ImportQuotes(ctx, importFile, false)
+	if err != nil {
+		t.Fatalf("ImportQuotes() error = %v", err)
+	}
+
+	if len(ids) != len(importFile) || ids[0] == 0 || ids[1] != 0 || ids[2] != 0 || ids[3] == 0 {
+		t.Fatalf("ImportQuotes() = %v, want IDs for rows 0 and 3 and 0 for duplicates", ids)
+	}
+
+	quotes, err := h.GetQuotes(ctx, storage.QuoteFilter{})
+	if err != nil {
+		t.Fatalf("GetQuotes() error = %v", err)
+	}
+
+	var got []int
+	for _, quote := range quotes {
+		got = append(got, quote.ID)
+	}
+	// Без limit порядок выборки не задан
+	slices.Sort(got)
+	if want := []int{existing, ids[0], ids[3]}; !slices.Equal(got, want) {
+		t.Errorf("stored IDs = %v, want %v", got, want)
+	}
+}
+
+func testImportQuotesStrict(t *testing.T, newBackend NewBackend) {
+	tests := []struct {
+		name    string
+		seed    bool
+		quotes  []storage.Quote
+		wantRow int
+	}{
+		{"duplicate of a stored quote", true, importFile[:2], 1},
+		{"duplicate within the file", false, importFile[:3], 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newBackend(t, config.Config{})
+			ctx := context.Background()
+
+			if tt.seed {
+				mustCreate(t, h, "Lev Tolstoy", "All happy families are alike")
+			}
+			before, err := h.CountFilteredQuotes(ctx, storage.QuoteFilter{})
+			if err != nil {
+				t.Fatalf("CountFilteredQuotes() error = %v", err)
+			}
+
+			_, err = h.ImportQuotes(ctx, tt.quotes, true)
+
+			var rowErr *storage.RowError
+			if !errors.As(err, &rowErr) || !errors.Is(err, storage.ErrDuplicateEntry) {
+				t.Fatalf("ImportQuotes() error = %v, want *storage.RowError wrapping %v", err, storage.ErrDuplicateEntry)
+			}
+			if rowErr.Row != tt.wantRow {
+				t.Errorf("RowError.Row = %d, want %d", rowErr.Row, tt.wantRow)
+			}
+
+			after, err := h.CountFilteredQuotes(ctx, storage.QuoteFilter{})
+			if err != nil {
+				t.Fatalf("CountFilteredQuotes() error = %v", err)
+			}
+			if after != before {
+				t.Errorf("quotes after rejected import = %d, want %d: nothing should be added", after, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// randomSamples — сколько раз выбирается случайная цитата в статистических тестах. Границы проверок
+// отстоят от ожидаемого значения не меньше чем на 5 стандартных отклонений, поэтому тесты не падают
+// случайно.
+const randomSamples = 2000
+
+// sampleAuthors выбирает случайную цитату randomSamples раз через random и считает, сколько раз выпал
+// каждый автор
+func sampleAuthors(t *testing.T, random func(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error)) map[string]int {
+	t.Helper()
+
+	authors := map[string]int{}
+	for range randomSamples {
+		quote, err := random(context.Background(), "", "")
+		if err != nil {
+			t.Fatalf("random quote error = %v", err)
+		}
+		authors[quote.Author]++
+	}
+	return authors
+}
+
+// seedSkewed добавляет одну цитату автора Rare и 9 цитат автора Prolific
+func seedSkewed(t *testing.T, h Backend) {
+	t.Helper()
+
+	mustCreate(t, h, "Rare", "The only quote")
+	for i := range 9 {
+		mustCreate(t, h, "Prolific", fmt.Sprintf("Quote %d", i))
+	}
+}
+
+func testGetFairRandomQuoteAuthorsEquallyLikely(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	seedSkewed(t, h)
+
+	// Каждый из двух авторов выпадает с вероятностью 1/2: ожидается 1000 ± 22
+	authors := sampleAuthors(t, h.GetFairRandomQuote)
+	if rare := authors["Rare"]; rare < 885 || rare > 1115 {
+		t.Errorf("fair: Rare drawn %d of %d times, want about %d", rare, randomSamples, randomSamples/2)
+	}
+}
+
+func testGetRandomQuoteQuotesEquallyLikely(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	seedSkewed(t, h)
+
+	// Без fair равновероятны цитаты: Rare выпадает в 1 случае из 10, ожидается 200 ± 13
+	authors := sampleAuthors(t, h.GetRandomQuote)
+	if rare := authors["Rare"]; rare < 130 || rare > 270 {
+		t.Errorf("default: Rare drawn %d of %d times, want about %d", rare, randomSamples, randomSamples/10)
+	}
+}
+
+func testGetRandomQuoteUniformAfterDeletes(t *testing.T, newBackend NewBackend) {
+	// Удаляется 30 цитат подряд из середины и каждая третья из остальных: в ID остаются дыры разной длины
+	deleted := func(i int) bool {
+		return i >= 10 && i < 40 || i%3 == 0
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.Config
+		delete func(h Backend, id int) error
+	}{
+		{
+			"order by random",
+			config.Config{},
+			func(h Backend, id int) error { return h.DeleteQuoteByID(context.Background(), id) },
+		},
+		{
+			"count cache",
+			config.Config{RandomCountCache: true},
+			func(h Backend, id int) error { return h.DeleteQuoteByID(context.Background(), id) },
+		},
+		{
+			"count cache with soft delete",
+			config.Config{RandomCountCache: true, SoftDelete: true},
+			func(h Backend, id int) error { return h.DeleteQuoteByID(context.Background(), id) },
+		},
+		{
+			// Кэш числа цитат не знает об удалении и остаётся больше настоящего числа
+			"count cache with deletes bypassing the service",
+			config.Config{RandomCountCache: true},
+			func(h Backend, id int) error {
+				return h.DeleteRow(id)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newBackend(t, tt.cfg)
+
+			ids := make([]int, 60)
+			for i := range ids {
+				ids[i] = mustCreate(t, h, fmt.Sprintf("Author %d", i), fmt.Sprintf("Quote %d", i))
+			}
+
+			// Прогреваем кэш числа цитат до удалений
+			_, err := h.GetRandomQuote(context.Background(), "", "")
+			if err != nil {
+				t.Fatalf("GetRandomQuote() error = %v", err)
+			}
+
+			remaining := map[int]int{}
+			for i, id := range ids {
+				if !deleted(i) {
+					remaining[id] = 0
+					continue
+				}
+				err := tt.delete(h, id)
+				if err != nil {
+					t.Fatalf("delete %d error = %v", id, err)
+				}
+			}
+
+			samples := 200 * len(remaining)
+			for range samples {
+				quote, err := h.GetRandomQuote(context.Background(), "", "")
+				if err != nil {
+					t.Fatalf("GetRandomQuote() error = %v", err)
+				}
+				if _, ok := remaining[quote.ID]; !ok {
+					t.Fatalf("GetRandomQuote() returned deleted quote %d", quote.ID)
+				}
+				remaining[quote.ID]++
+			}
+
+			// Каждая оставшаяся цитата выпадает в среднем 200 раз (± 14); границы — больше 5 отклонений
+			for id, count := range remaining {
+				if count < 125 || count > 275 {
+					t.Errorf("quote %d drawn %d of %d times, want about 200", id, count, samples)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"getcitation/internal/utils/config"
+)
+
+func testGetRecentQuotes(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	quotes, err := h.GetRecentQuotes(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetRecentQuotes() on an empty table error = %v", err)
+	}
+	if quotes == nil || len(quotes) != 0 {
+		t.Fatalf("GetRecentQuotes() on an empty table = %#v, want an empty slice", quotes)
+	}
+
+	// ID идут в порядке добавления, а время добавления — вразнобой, чтобы порядок выдачи
+	// определялся created_at, а не ID
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{3 * time.Hour, time.Hour, 4 * time.Hour, 2 * time.Hour}
+
+	ids := make([]int, len(offsets))
+	for i, offset := range offsets {
+		ids[i] = mustCreate(t, h, "Confucius", fmt.Sprintf("Quote %d", i))
+
+		err := h.SetCreatedAt(ids[i], base.Add(offset))
+		if err != nil {
+			t.Fatalf("SetCreatedAt() error = %v", err)
+		}
+	}
+
+	deleted := mustCreate(t, h, "Confucius", "Deleted")
+	err = h.DeleteQuoteByID(ctx, deleted)
+	if err != nil {
+		t.Fatalf("DeleteQuoteByID() error = %v", err)
+	}
+
+	tests := []struct {
+		limit int
+		want  []int
+	}{
+		{10, []int{ids[2], ids[0], ids[3], ids[1]}},
+		{2, []int{ids[2], ids[0]}},
+	}
+
+	for _, tt := range tests {
+		quotes, err := h.GetRecentQuotes(ctx, tt.limit)
+		if err != nil {
+			t.Fatalf("GetRecentQuotes(%d) error = %v", tt.limit, err)
+		}
+
+		var got []int
+		for _, quote := range quotes {
+			got = append(got, quote.ID)
+			if quote.CreatedAt == nil {
+				t.Errorf("quote %d has no created_at", quote.ID)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GetRecentQuotes(%d) IDs = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package storagetest — общий набор тестов, который проходят все бэкенды хранилища. Бэкенд подключает его
+// одним тестом: storagetest.Run(t, newBackend), где newBackend открывает чистое хранилище. Так одинаковые
+// проверки не копируются в пакеты бэкендов и не расходятся между ними.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+// Store — методы хранилища, которые проверяет набор. Ему удовлетворяют sqlite.Handlers и postgresql.Handlers.
+type Store interface {
+	CreateQuote(ctx context.Context, quote storage.Quote) (int, error)
+	CreateQuoteIdempotent(ctx context.Context, key string, quote storage.Quote, ttl time.Duration) (int, bool, error)
+	UpsertQuote(ctx context.Context, quote storage.Quote) (int, bool, error)
+	ImportQuotes(ctx context.Context, quotes []storage.Quote, strict bool) ([]int, error)
+	DeleteQuoteByID(ctx context.Context, id int) error
+	GetRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error)
+	GetFairRandomQuote(ctx context.Context, excludeAuthor string, language string) (storage.Quote, error)
+	GetQuoteByID(ctx context.Context, id int) (storage.Quote, error)
+	GetQuotes(ctx context.Context, filter storage.QuoteFilter) ([]storage.Quote, error)
+	ExportQuotes(ctx context.Context, filter storage.QuoteFilter, fn func(storage.Quote) error) error
+	CountFilteredQuotes(ctx context.Context, filter storage.QuoteFilter) (int, error)
+	GetRecentQuotes(ctx context.Context, limit int) ([]storage.Quote, error)
+	GetAuthors(ctx context.Context, prefix string, limit int, offset int) ([]storage.AuthorQuotes, error)
+	Ready(ctx context.Context) error
+}
+
+// Backend — хранилище под тестом и доступ к его таблице цитат в обход методов хранилища: SQL у бэкендов
+// разный, поэтому такие запросы предоставляет сам бэкенд.
+type Backend struct {
+	Store
+
+	// SetCreatedAt меняет время добавления цитаты id
+	SetCreatedAt func(id int, createdAt time.Time) error
+	// DeleteRow удаляет строку цитаты id из таблицы насовсем, минуя хранилище и его кэш числа цитат
+	DeleteRow func(id int) error
+}
+
+// NewBackend открывает чистое хранилище с конфигурацией cfg и закрывает его по окончании теста.
+// Если БД бэкенда недоступна, тест пропускается.
+type NewBackend func(t testing.TB, cfg config.Config) Backend
+
+// Run запускает весь набор тестов подтестами t поверх хранилищ, которые открывает newBackend
+func Run(t *testing.T, newBackend NewBackend) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, newBackend NewBackend)
+	}{
+		{"GetAuthorsPrefix", testGetAuthorsPrefix},
+		{"GetQuotesStopsOnCancel", testGetQuotesStopsOnCancel},
+		{"GetQuotesCancelledBeforeQuery", testGetQuotesCancelledBeforeQuery},
+		{"ExportQuotesStopsOnCancel", testExportQuotesStopsOnCancel},
+		{"GetQuotesCursorStable", testGetQuotesCursorStable},
+		{"CreateQuoteIdempotentReplaysKey", testCreateQuoteIdempotentReplaysKey},
+		{"CreateQuoteIdempotentRejectsDifferentBody", testCreateQuoteIdempotentRejectsDifferentBody},
+		{"CreateQuoteIdempotentConcurrentSameKey", testCreateQuoteIdempotentConcurrentSameKey},
+		{"ImportQuotesLenient", testImportQuotesLenient},
+		{"ImportQuotesStrict", testImportQuotesStrict},
+		{"GetFairRandomQuoteAuthorsEquallyLikely", testGetFairRandomQuoteAuthorsEquallyLikely},
+		{"GetRandomQuoteQuotesEquallyLikely", testGetRandomQuoteQuotesEquallyLikely},
+		{"GetRandomQuoteUniformAfterDeletes", testGetRandomQuoteUniformAfterDeletes},
+		{"GetRecentQuotes", testGetRecentQuotes},
+		{"UpsertQuote", testUpsertQuote},
+		{"UpsertQuoteConcurrentDelete", testUpsertQuoteConcurrentDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newBackend)
+		})
+	}
+}
+
+// mustCreate добавляет цитату и возвращает её ID
+func mustCreate(t testing.TB, h Store, author string, quote string) int {
+	t.Helper()
+
+	id, err := h.CreateQuote(context.Background(), storage.Quote{Author: author, Quote: quote})
+	if err != nil {
+		t.Fatalf("CreateQuote(%q, %q) error = %v", author, quote, err)
+	}
+	return id
+}
+
+// seedQuotes добавляет n цитат от 50 авторов одним импортом
+func seedQuotes(t testing.TB, h Store, n int) {
+	t.Helper()
+
+	quotes := make([]storage.Quote, n)
+	for i := range quotes {
+		quotes[i] = storage.Quote{Author: fmt.Sprintf("Author %d", i%50), Quote: fmt.Sprintf("Quote %d", i)}
+	}
+
+	_, err := h.ImportQuotes(context.Background(), quotes, true)
+	if err != nil {
+		t.Fatalf("ImportQuotes(%d) error = %v", n, err)
+	}
+}
@@ -0,0 +1,95 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"getcitation/internal/storage"
+	"getcitation/internal/utils/config"
+)
+
+func testUpsertQuote(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{})
+	ctx := context.Background()
+
+	quote := storage.Quote{Author: "Confucius", Quote: "Life is simple"}
+
+	id, existing, err := h.UpsertQuote(ctx, quote)
+	if err != nil {
+		t.Fatalf("first UpsertQuote() error = %v", err)
+	}
+	if existing {
+		t.Errorf("first UpsertQuote() existing = true, want a new quote")
+	}
+
+	again, existing, err := h.UpsertQuote(ctx, quote)
+	if err != nil {
+		t.Fatalf("second UpsertQuote() error = %v", err)
+	}
+	if !existing || again != id {
+		t.Errorf("second UpsertQuote() = %d, %t, want %d, true", again, existing, id)
+	}
+
+	err = h.DeleteQuoteByID(ctx, id)
+	if err != nil {
+		t.Fatalf("DeleteQuoteByID() error = %v", err)
+	}
+
+	// Удаленная цитата не мешает добавить такую же заново
+	restored, existing, err := h.UpsertQuote(ctx, quote)
+	if err != nil {
+		t.Fatalf("UpsertQuote() after delete error = %v", err)
+	}
+	if existing || restored == id {
+		t.Errorf("UpsertQuote() after delete = %d, %t, want a new id other than %d", restored, existing, id)
+	}
+
+	got, err := h.GetQuoteByID(ctx, restored)
+	if err != nil {
+		t.Fatalf("GetQuoteByID(%d) error = %v", restored, err)
+	}
+	if got.Author != quote.Author || got.Quote != quote.Quote {
+		t.Errorf("GetQuoteByID(%d) = %q by %q, want %q by %q", restored, got.Quote, got.Author, quote.Quote, quote.Author)
+	}
+}
+
+func testUpsertQuoteConcurrentDelete(t *testing.T, newBackend NewBackend) {
+	h := newBackend(t, config.Config{TxRetries: 3})
+	ctx := context.Background()
+
+	// Цитату удаляют одновременно с upsert такой же: какой бы запрос ни успел первым, upsert
+	// возвращает ID цитаты, а не ошибку
+	for i := range 50 {
+		quote := storage.Quote{Author: "Seneca", Quote: fmt.Sprintf("Quote %d", i)}
+		id := mustCreate(t, h, quote.Author, quote.Quote)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			if err := h.DeleteQuoteByID(ctx, id); err != nil {
+				t.Errorf("DeleteQuoteByID(%d) error = %v", id, err)
+			}
+		}()
+
+		var upserted int
+		var existing bool
+		var err error
+		go func() {
+			defer wg.Done()
+			upserted, existing, err = h.UpsertQuote(ctx, quote)
+		}()
+
+		wg.Wait()
+
+		if err != nil {
+			t.Fatalf("UpsertQuote() racing a delete error = %v", err)
+		}
+		if existing != (upserted == id) {
+			t.Errorf("UpsertQuote() racing a delete = %d, %t, deleted quote %d", upserted, existing, id)
+		}
+	}
+}
@@ -3,35 +3,201 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+
+	"getcitation/internal/storage"
+)
+
+var (
+	ErrUnknownStorageBackend  = fmt.Errorf("неизвестный бэкенд хранилища")
+	ErrMissingVariables       = fmt.Errorf("не заданы обязательные переменные окружения")
+	ErrInvalidTimeout         = fmt.Errorf("некорректный таймаут сервера")
+	ErrInvalidRoutePrefix     = fmt.Errorf("ROUTE_PREFIX должен начинаться с / и не заканчиваться на /")
+	ErrInvalidPageSize        = fmt.Errorf("PAGE_SIZE_DEFAULT и PAGE_SIZE_MAX должны быть положительными, а PAGE_SIZE_DEFAULT — не больше PAGE_SIZE_MAX")
+	ErrInvalidTrustedProxy    = fmt.Errorf("TRUSTED_PROXIES должен содержать CIDR или IP-адреса через запятую")
+	ErrInvalidMaxHeaderBytes  = fmt.Errorf("SERVER_MAX_HEADER_BYTES должен быть положительным")
+	ErrInvalidMaxBodyBytes    = fmt.Errorf("MAX_BODY_BYTES и MAX_IMPORT_BYTES должны быть положительными")
+	ErrInvalidTxRetries       = fmt.Errorf("TX_RETRIES не может быть отрицательным")
+	ErrInvalidCORSMaxAge      = fmt.Errorf("CORS_MAX_AGE не может быть отрицательным")
+	ErrInvalidCORSHeader      = fmt.Errorf("CORS_ALLOWED_HEADERS должен содержать имена заголовков через запятую")
+	ErrCORSCredentialsAny     = fmt.Errorf("CORS_ALLOW_CREDENTIALS нельзя включать при CORS_ALLOWED_ORIGINS=*: перечислите источники явно")
+	ErrInvalidLogSampleRate   = fmt.Errorf("LOG_SAMPLE_RATE должен быть положительным")
+	ErrInvalidMaxConcurrent   = fmt.Errorf("MAX_CONCURRENT_REQUESTS не может быть отрицательным")
+	ErrInvalidQuoteIDType     = fmt.Errorf("QUOTE_ID_TYPE должен быть int или uuid")
+	ErrInvalidMaxFilterLength = fmt.Errorf("MAX_FILTER_LENGTH должен быть положительным")
+	ErrInvalidWarmUpConns     = fmt.Errorf("POSTGRESQL_WARMUP_CONNS должен быть от 0 до %d", MaxWarmUpConns)
+	ErrInvalidTableName       = fmt.Errorf("POSTGRESQL_TABLE должен быть идентификатором из латинских букв, цифр и _, не начинающимся с цифры")
+	ErrTableNameTooLong       = fmt.Errorf("POSTGRESQL_TABLE длиннее %d символов: имена зависимых таблиц (<имя>_quote_categories) не поместятся в 63 символа, которые допускает PostgreSQL", MaxTableNameLength)
+	ErrTableUnsupported       = fmt.Errorf("POSTGRESQL_TABLE поддерживается только бэкендом postgresql: SQLite всегда работает с таблицей quotes")
+)
+
+// MaxWarmUpConns — наибольшее значение POSTGRESQL_WARMUP_CONNS. Прогрев держит все соединения одновременно,
+// а max_connections сервера PostgreSQL по умолчанию — 100, и их делят все экземпляры сервиса.
+const MaxWarmUpConns = 32
+
+// tableName — допустимое имя таблицы. Имя подставляется в текст SQL-запросов (параметром его не передать),
+// поэтому кавычки, пробелы, точки и прочие символы, через которые можно внедрить SQL, запрещены.
+var tableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// MaxTableNameLength — наибольшая длина POSTGRESQL_TABLE. С другим именем таблицы зависимые таблицы
+// называются <имя>_quote_categories и т.п., а PostgreSQL молча обрезает идентификаторы длиннее 63 символов,
+// из-за чего разные установки могли бы попасть в одни и те же таблицы.
+const MaxTableNameLength = 63 - len("_quote_categories")
+
+// Типы ID цитат в путях API (QUOTE_ID_TYPE)
+const (
+	QuoteIDInt  = "int"
+	QuoteIDUUID = "uuid"
 )
 
+// defaultTable — таблица цитат, которую создают миграции
+const defaultTable = "quotes"
+
+// minServerWriteTimeout — нижняя граница SERVER_WRITETIMEOUT: с меньшим таймаутом сервер не успевает
+// отдать даже обычный ответ, и соединения обрываются на полпути.
+const minServerWriteTimeout = time.Second
+
 // Config содержит параметры конфигурации приложения, загружаемые из env-переменных.
+// Поля с тегом secret скрываются при выводе конфигурации в журнал (см. LogValue).
 type Config struct {
-	AppLogMode string `env:"APP_LOG_MODE" env-required:"true" env-description:"Режим логгирования (local, dev, prod)"`
+	// EnvPrefix применяется до чтения остальных переменных (см. applyEnvPrefix) и здесь только для справки.
+	EnvPrefix string `env:"CONFIG_ENV_PREFIX" env-description:"Префикс имён переменных, например GETCITATION_ (GETCITATION_SERVER_PORT); переменные без префикса остаются запасным вариантом"`
+
+	AppLogMode   string `env:"APP_LOG_MODE" env-required:"true" env-description:"Режим логгирования (local, dev, prod)"`
+	AppLogOutput string `env:"LOG_OUTPUT" env-default:"file" env-description:"Куда пишутся JSON-логи режимов dev и prod (file, stdout, both)"`
+
+	// ExposeInternalErrors — добавлять в JSON-ответы об ошибках op обработчика и текст внутренней ошибки с цепочкой
+	// op, чтобы при локальной отладке не искать их в журнале. Действует только в режимах local и dev (см.
+	// InternalErrorsExposed): в prod детали ошибок клиенту не отдаются.
+	ExposeInternalErrors bool `env:"EXPOSE_INTERNAL_ERRORS" env-default:"false" env-description:"Добавлять в ответы об ошибках op и текст внутренней ошибки (только APP_LOG_MODE local и dev)"`
+
+	LogSampleRate int `env:"LOG_SAMPLE_RATE" env-default:"1" env-description:"Писать в журнал одну из LOG_SAMPLE_RATE записей об ошибках клиента (4xx); 5xx пишутся всегда (1 — все записи)"`
 
 	MigrationsPath      string `env:"MIGRATIONS_PATH" env-required:"true" env-description:"Путь до миграций"`
 	MigrationsDirection string `env:"MIGRATIONS_DIRECTION" env-required:"true" env-description:"Направление миграций"`
 	MigrationsTable     string `env:"MIGRATIONS_TABLE" env-required:"true" env-description:"Таблица миграций"`
 
+	// ReadyCheckMigrations — /ready дополнительно сверяет версию схемы БД с последней миграцией в MIGRATIONS_PATH
+	// и отвечает 503, если схема отстает. Выключено по умолчанию: некоторые установки мигрируют отдельно и
+	// не поставляют каталог миграций вместе с сервисом.
+	ReadyCheckMigrations bool `env:"READY_CHECK_MIGRATIONS" env-default:"false" env-description:"Проверять в /ready, что миграции БД применены"`
+
 	ServerHost         string        `env:"SERVER_HOST" env-required:"true" env-description:"Имя хоста"`
 	ServerPort         string        `env:"SERVER_PORT" env-required:"true" env-description:"Порт сервера"`
 	ServerReadTimeout  time.Duration `env:"SERVER_READTIMEOUT" env-required:"true" env-description:"Таймаут сервера на Read"`
 	ServerWriteTimeout time.Duration `env:"SERVER_WRITETIMEOUT" env-required:"true" env-description:"Таймаут сервера на Write"`
 	ServerIdleTimeout  time.Duration `env:"SERVER_IDLETIMEOUT" env-required:"true" env-description:"Таймаут сервера на Idle"`
+	ServerSocket       string        `env:"SERVER_SOCKET" env-description:"Путь до Unix-сокета; если задан, сервер слушает его вместо SERVER_HOST:SERVER_PORT"`
+
+	ServerReadHeaderTimeout time.Duration `env:"SERVER_READHEADERTIMEOUT" env-default:"5s" env-description:"Таймаут сервера на чтение заголовков запроса (не больше SERVER_READTIMEOUT)"`
+	ServerMaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" env-default:"1048576" env-description:"Максимальный размер заголовков запроса в байтах"`
+	MaxBodyBytes            int64         `env:"MAX_BODY_BYTES" env-default:"1048576" env-description:"Максимальный размер JSON-тела запроса в байтах, больше — 413"`
+	MaxImportBytes          int64         `env:"MAX_IMPORT_BYTES" env-default:"10485760" env-description:"Максимальный размер тела POST /quotes/import в байтах, больше — 413"`
+	RequestTimeout          time.Duration `env:"REQUEST_TIMEOUT" env-default:"0s" env-description:"Наибольшее время обработки запроса, после которого клиент получает 503 (0 — без ограничения; SSE и выгрузка не ограничиваются)"`
+	ServerKeepAliveDisabled bool          `env:"SERVER_KEEPALIVE_DISABLED" env-default:"false" env-description:"Отключить HTTP keep-alive: каждое соединение обслуживает один запрос"`
+	ReadOnlyMode            bool          `env:"READ_ONLY_MODE" env-default:"false" env-description:"Запустить в режиме только для чтения: изменяющие запросы получают 503, чтение работает (переключается на ходу через POST /admin/readonly)"`
+	MaxConcurrentRequests   int           `env:"MAX_CONCURRENT_REQUESTS" env-default:"0" env-description:"Наибольшее число одновременно обрабатываемых запросов, сверх него клиент сразу получает 503 (0 — без ограничения; /ready и SSE не учитываются)"`
+
+	RoutePrefix string `env:"ROUTE_PREFIX" env-description:"Префикс всех маршрутов API, например /api/v1 (пусто — без префикса; /ready не префиксуется)"`
+
+	TrustedProxies []string `env:"TRUSTED_PROXIES" env-separator:"," env-description:"CIDR или адреса прокси через запятую, которым можно верить в X-Forwarded-For и X-Real-IP (пусто — заголовки игнорируются)"`
+
+	CORSAllowedOrigins []string      `env:"CORS_ALLOWED_ORIGINS" env-separator:"," env-description:"Источники (Origin) через запятую, которым разрешены запросы из браузера, * — любые (пусто — CORS выключен)"`
+	CORSMaxAge         time.Duration `env:"CORS_MAX_AGE" env-default:"0s" env-description:"Сколько браузер может кэшировать ответ на preflight-запрос (Access-Control-Max-Age, округляется до секунд; 0 — заголовок не отправляется)"`
+
+	CORSAllowedHeaders   []string `env:"CORS_ALLOWED_HEADERS" env-separator:"," env-description:"Дополнительные заголовки запроса через запятую, которые браузеру можно отправлять, например X-Request-ID (к стандартным Authorization, Content-Type, Idempotency-Key, If-None-Match)"`
+	CORSAllowCredentials bool     `env:"CORS_ALLOW_CREDENTIALS" env-default:"false" env-description:"Разрешить браузеру отправлять cookie и заголовок Authorization (Access-Control-Allow-Credentials); несовместимо с CORS_ALLOWED_ORIGINS=*"`
+
+	GRPCPort string `env:"GRPC_PORT" env-description:"Порт gRPC-сервера на SERVER_HOST (пусто — gRPC выключен)"`
+
+	TLSCertFile   string `env:"TLS_CERT_FILE" env-description:"Путь до сертификата TLS (вместе с TLS_KEY_FILE включает HTTPS)"`
+	TLSKeyFile    string `env:"TLS_KEY_FILE" env-description:"Путь до приватного ключа TLS"`
+	TLSMinVersion string `env:"TLS_MIN_VERSION" env-default:"1.2" env-description:"Минимальная версия TLS (1.2, 1.3)"`
+
+	StorageBackend string `env:"STORAGE_BACKEND" env-default:"postgresql" env-description:"Бэкенд хранилища (postgresql, sqlite)"`
+
+	PostgreSQLUsername string `env:"POSTGRESQL_USERNAME" env-description:"Имя пользователя PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLPassword string `env:"POSTGRESQL_PASSWORD" env-description:"Пароль PostgreSQL" secret:"true"`
+	PostgreSQLHost     string `env:"POSTGRESQL_HOST" env-description:"Имя хоста PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLPort     string `env:"POSTGRESQL_PORT" env-description:"Порт PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLDatabase string `env:"POSTGRESQL_DBNAME" env-description:"БД PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLTable    string `env:"POSTGRESQL_TABLE" env-description:"Таблица PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLSSL      string `env:"POSTGRESQL_SSLMODE" env-description:"Режим SSL PostgreSQL (обязательно для postgresql)"`
+	PostgreSQLExtra    string `env:"POSTGRESQL_EXTRA" env-description:"Дополнительные опции PostgreSQL" secret:"true"`
+
+	// PostgreSQLApplicationName — имя, под которым соединения сервиса видны в pg_stat_activity
+	PostgreSQLApplicationName string `env:"POSTGRESQL_APPLICATION_NAME" env-default:"getcitation" env-description:"application_name соединений с PostgreSQL (пусто — не передаётся)"`
+
+	// PostgreSQLPasswordFile — файл с паролем (секрет Docker/Kubernetes). Если задан, пароль читается
+	// из него при загрузке конфига и заменяет POSTGRESQL_PASSWORD.
+	PostgreSQLPasswordFile string `env:"POSTGRESQL_PASSWORD_FILE" env-description:"Файл с паролем PostgreSQL, приоритетнее POSTGRESQL_PASSWORD"`
+
+	// PostgreSQLWarmUpConns — сколько соединений открыть и проверить запросом SELECT 1 при старте (на основном
+	// сервере и на реплике), чтобы первые запросы не ждали установки соединения. Не больше MaxWarmUpConns.
+	// При значении больше 0 лимит простаивающих соединений пула (SetMaxIdleConns) поднимается до него
+	// на всё время работы процесса, а не только на время прогрева: иначе прогретые соединения закрылись бы
+	// сразу после возврата в пул.
+	PostgreSQLWarmUpConns int `env:"POSTGRESQL_WARMUP_CONNS" env-default:"0" env-description:"Число соединений с PostgreSQL, которые прогреваются при старте (0 — без прогрева)"`
+
+	PostgreSQLReplicaDSN string `env:"POSTGRESQL_REPLICA_DSN" env-description:"DSN реплики PostgreSQL для чтения (пусто — чтение с основного сервера)" secret:"dsn"`
+
+	SQLitePath string `env:"SQLITE_PATH" env-default:"getcitation.db" env-description:"Путь до файла БД SQLite"`
+
+	TxRetries int `env:"TX_RETRIES" env-default:"3" env-description:"Количество повторов транзакции изменения при конфликте сериализации или взаимоблокировке (0 — без повторов)"`
+
+	IdempotencyKeyTTL time.Duration `env:"IDEMPOTENCY_KEY_TTL" env-default:"24h" env-description:"Время жизни ключа идемпотентности"`
+
+	SoftDelete bool `env:"SOFT_DELETE" env-default:"false" env-description:"Мягкое удаление: помечать цитаты удалёнными вместо удаления из БД"`
 
-	PostgreSQLUsername string `env:"POSTGRESQL_USERNAME" env-required:"true" env-description:"Имя пользователя PostgreSQL"`
-	PostgreSQLPassword string `env:"POSTGRESQL_PASSWORD" env-description:"Пароль PostgreSQL"`
-	PostgreSQLHost     string `env:"POSTGRESQL_HOST" env-required:"true" env-description:"Имя хоста PostgreSQL"`
-	PostgreSQLPort     string `env:"POSTGRESQL_PORT" env-required:"true" env-description:"Порт PostgreSQL"`
-	PostgreSQLDatabase string `env:"POSTGRESQL_DBNAME" env-required:"true" env-description:"БД PostgreSQL"`
-	PostgreSQLTable    string `env:"POSTGRESQL_TABLE" env-required:"true" env-description:"Таблица PostgreSQL"`
-	PostgreSQLSSL      string `env:"POSTGRESQL_SSLMODE" env-required:"true" env-description:"Режим SSL PostgreSQL"`
-	PostgreSQLExtra    string `env:"POSTGRESQL_EXTRA" env-description:"Дополнительные опции PostgreSQL"`
+	// IdempotentDelete — DELETE /quotes/{id} отвечает 204 и тогда, когда цитаты уже нет (например, ее удалил
+	// параллельный запрос). По умолчанию такой запрос получает 404.
+	IdempotentDelete bool `env:"IDEMPOTENT_DELETE" env-default:"false" env-description:"Идемпотентное удаление: 204 на DELETE /quotes/{id}, даже если цитаты нет"`
+
+	// QuoteIDType — чем цитата адресуется в путях /quotes/{id}: последовательным ID (int) или UUID (uuid).
+	// UUID не выдает число цитат и не позволяет перебирать их по порядку.
+	QuoteIDType string `env:"QUOTE_ID_TYPE" env-default:"int" env-description:"Идентификатор цитаты в путях API (int, uuid)"`
+
+	// SeedOnEmpty — при старте заполнить пустую БД встроенным набором цитат, чтобы сервис можно было
+	// попробовать без ручной загрузки данных. Если цитаты уже есть, ничего не добавляется.
+	SeedOnEmpty bool `env:"SEED_ON_EMPTY" env-default:"false" env-description:"Заполнить пустую БД встроенным набором цитат при старте"`
+
+	TrackViews bool `env:"TRACK_VIEWS" env-default:"true" env-description:"Считать просмотры цитат (случайная цитата и цитата по ID)"`
+
+	// RandomCountCache включает выбор случайной цитаты по смещению от закэшированного числа цитат вместо
+	// сортировки всей таблицы. Кэш обновляют только изменения через этот экземпляр сервиса.
+	RandomCountCache bool `env:"RANDOM_COUNT_CACHE" env-default:"false" env-description:"Выбирать случайную цитату по закэшированному числу цитат вместо ORDER BY RANDOM() (для одного экземпляра сервиса)"`
+
+	BannedWordsPath string `env:"BANNED_WORDS_PATH" env-description:"Файл со словами, с которыми нельзя добавить цитату, по одному на строку (пусто — проверка выключена)"`
+
+	PageSizeDefault int `env:"PAGE_SIZE_DEFAULT" env-default:"100" env-description:"Размер страницы списка цитат, если limit не задан"`
+	PageSizeMax     int `env:"PAGE_SIZE_MAX" env-default:"1000" env-description:"Наибольший допустимый limit списка цитат"`
+
+	// MaxFilterLength ограничивает длину фильтров по автору и поискового запроса в символах: слишком длинное
+	// значение отклоняется с 400 до обращения к БД, чтобы не раздувать запрос и журнал.
+	MaxFilterLength int `env:"MAX_FILTER_LENGTH" env-default:"256" env-description:"Наибольшая длина фильтра по автору и поискового запроса в символах"`
+
+	CacheTTL time.Duration `env:"CACHE_TTL" env-default:"0s" env-description:"Время жизни кэша списка цитат (0 — кэш выключен)"`
+
+	JWTSecret string `env:"JWT_SECRET" env-description:"Секрет HS256 для проверки bearer-токенов (пусто — аутентификация выключена)" secret:"true"`
+
+	WebhookURL     string        `env:"WEBHOOK_URL" env-description:"URL для отправки событий о создании и удалении цитат (пусто — выключено)" secret:"true"`
+	WebhookSecret  string        `env:"WEBHOOK_SECRET" env-description:"Секрет для HMAC-подписи событий" secret:"true"`
+	WebhookTimeout time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"5s" env-description:"Таймаут одной попытки доставки события"`
+	WebhookRetries int           `env:"WEBHOOK_RETRIES" env-default:"3" env-description:"Количество повторных попыток доставки события"`
+	WebhookBackoff time.Duration `env:"WEBHOOK_BACKOFF" env-default:"1s" env-description:"Базовая пауза между попытками доставки (растёт линейно)"`
+
+	StreamKeepAlive time.Duration `env:"STREAM_KEEPALIVE" env-default:"15s" env-description:"Интервал keep-alive комментариев в SSE-потоке"`
+	StreamBuffer    int           `env:"STREAM_BUFFER" env-default:"16" env-description:"Размер буфера цитат на одного SSE-подписчика"`
 }
 
 // New загружает конфигурацию из переменных окружения, используя .env файл и cleanenv.
@@ -45,6 +211,11 @@ func New() (Config, error) {
 		return Config{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = applyEnvPrefix()
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	err = cleanenv.ReadEnv(&config)
 	if err != nil {
 		fmt.Println("")
@@ -57,5 +228,221 @@ func New() (Config, error) {
 		return Config{}, fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = config.readPasswordFile()
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = config.validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return config, nil
 }
+
+// applyEnvPrefix подставляет значения переменных с префиксом CONFIG_ENV_PREFIX (например, GETCITATION_SERVER_PORT)
+// в переменные без префикса, которые читает cleanenv. Переменная с префиксом приоритетнее; если её нет,
+// используется переменная без префикса, поэтому старые окружения продолжают работать.
+func applyEnvPrefix() error {
+	prefix := os.Getenv("CONFIG_ENV_PREFIX")
+	if prefix == "" {
+		return nil
+	}
+
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(Config{})) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		value, ok := os.LookupEnv(prefix + name)
+		if !ok {
+			continue
+		}
+
+		err := os.Setenv(name, value)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", prefix, name, err)
+		}
+	}
+	return nil
+}
+
+// readPasswordFile подставляет пароль PostgreSQL из POSTGRESQL_PASSWORD_FILE, если он задан.
+// Завершающие переводы строки отбрасываются: их оставляют почти все способы записи секрета в файл.
+func (c *Config) readPasswordFile() error {
+	if c.PostgreSQLPasswordFile == "" {
+		return nil
+	}
+
+	password, err := os.ReadFile(c.PostgreSQLPasswordFile)
+	if err != nil {
+		return fmt.Errorf("POSTGRESQL_PASSWORD_FILE: %w", err)
+	}
+
+	c.PostgreSQLPassword = strings.TrimRight(string(password), "\r\n")
+	return nil
+}
+
+// InternalErrorsExposed сообщает, что детали внутренних ошибок попадают в ответы: EXPOSE_INTERNAL_ERRORS
+// включен, а режим — local или dev. В остальных режимах, включая prod, детали скрываются всегда.
+func (c Config) InternalErrorsExposed() bool {
+	return c.ExposeInternalErrors && (c.AppLogMode == "local" || c.AppLogMode == "dev")
+}
+
+// validate проверяет таймауты сервера и параметры, обязательность которых зависит от выбранного бэкенда хранилища.
+func (c Config) validate() error {
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READTIMEOUT", c.ServerReadTimeout},
+		{"SERVER_READHEADERTIMEOUT", c.ServerReadHeaderTimeout},
+		{"SERVER_WRITETIMEOUT", c.ServerWriteTimeout},
+		{"SERVER_IDLETIMEOUT", c.ServerIdleTimeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			return fmt.Errorf("%w: %s должен быть положительным, получено %s", ErrInvalidTimeout, timeout.name, timeout.value)
+		}
+	}
+
+	if c.ServerReadHeaderTimeout > c.ServerReadTimeout {
+		return fmt.Errorf("%w: SERVER_READHEADERTIMEOUT (%s) больше SERVER_READTIMEOUT (%s)", ErrInvalidTimeout, c.ServerReadHeaderTimeout, c.ServerReadTimeout)
+	}
+	if c.ServerWriteTimeout < minServerWriteTimeout {
+		return fmt.Errorf("%w: SERVER_WRITETIMEOUT должен быть не меньше %s, получено %s", ErrInvalidTimeout, minServerWriteTimeout, c.ServerWriteTimeout)
+	}
+
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("%w: REQUEST_TIMEOUT не может быть отрицательным, получено %s", ErrInvalidTimeout, c.RequestTimeout)
+	}
+	if c.RequestTimeout >= c.ServerWriteTimeout {
+		return fmt.Errorf("%w: REQUEST_TIMEOUT (%s) должен быть меньше SERVER_WRITETIMEOUT (%s), иначе соединение оборвется раньше ответа 503", ErrInvalidTimeout, c.RequestTimeout, c.ServerWriteTimeout)
+	}
+
+	if c.ServerMaxHeaderBytes <= 0 {
+		return fmt.Errorf("%w: получено %d", ErrInvalidMaxHeaderBytes, c.ServerMaxHeaderBytes)
+	}
+
+	if c.MaxBodyBytes <= 0 || c.MaxImportBytes <= 0 {
+		return fmt.Errorf("%w: MAX_BODY_BYTES=%d, MAX_IMPORT_BYTES=%d", ErrInvalidMaxBodyBytes, c.MaxBodyBytes, c.MaxImportBytes)
+	}
+
+	if c.LogSampleRate < 1 {
+		return fmt.Errorf("%w: получено %d", ErrInvalidLogSampleRate, c.LogSampleRate)
+	}
+
+	if c.CORSMaxAge < 0 {
+		return fmt.Errorf("%w: получено %s", ErrInvalidCORSMaxAge, c.CORSMaxAge)
+	}
+
+	for _, header := range c.CORSAllowedHeaders {
+		if !isHeaderName(strings.TrimSpace(header)) {
+			return fmt.Errorf("%w: %q", ErrInvalidCORSHeader, header)
+		}
+	}
+
+	if c.CORSAllowCredentials && slices.ContainsFunc(c.CORSAllowedOrigins, func(origin string) bool { return strings.TrimSpace(origin) == "*" }) {
+		return ErrCORSCredentialsAny
+	}
+
+	if c.QuoteIDType != QuoteIDInt && c.QuoteIDType != QuoteIDUUID {
+		return fmt.Errorf("%w: получено %q", ErrInvalidQuoteIDType, c.QuoteIDType)
+	}
+
+	if c.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("%w: получено %d", ErrInvalidMaxConcurrent, c.MaxConcurrentRequests)
+	}
+
+	if c.PostgreSQLWarmUpConns < 0 || c.PostgreSQLWarmUpConns > MaxWarmUpConns {
+		return fmt.Errorf("%w: получено %d", ErrInvalidWarmUpConns, c.PostgreSQLWarmUpConns)
+	}
+
+	if c.TxRetries < 0 {
+		return fmt.Errorf("%w: получено %d", ErrInvalidTxRetries, c.TxRetries)
+	}
+
+	if c.MaxFilterLength <= 0 {
+		return fmt.Errorf("%w: получено %d", ErrInvalidMaxFilterLength, c.MaxFilterLength)
+	}
+
+	if c.PageSizeDefault <= 0 || c.PageSizeMax <= 0 || c.PageSizeDefault > c.PageSizeMax {
+		return fmt.Errorf("%w: PAGE_SIZE_DEFAULT=%d, PAGE_SIZE_MAX=%d", ErrInvalidPageSize, c.PageSizeDefault, c.PageSizeMax)
+	}
+
+	if c.RoutePrefix != "" && (!strings.HasPrefix(c.RoutePrefix, "/") || strings.HasSuffix(c.RoutePrefix, "/")) {
+		return fmt.Errorf("%w: %q", ErrInvalidRoutePrefix, c.RoutePrefix)
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+
+		_, errPrefix := netip.ParsePrefix(proxy)
+		_, errAddr := netip.ParseAddr(proxy)
+		if errPrefix != nil && errAddr != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidTrustedProxy, proxy)
+		}
+	}
+
+	switch c.StorageBackend {
+	case storage.BackendPostgreSQL:
+		required := map[string]string{
+			"POSTGRESQL_USERNAME": c.PostgreSQLUsername,
+			"POSTGRESQL_HOST":     c.PostgreSQLHost,
+			"POSTGRESQL_PORT":     c.PostgreSQLPort,
+			"POSTGRESQL_DBNAME":   c.PostgreSQLDatabase,
+			"POSTGRESQL_TABLE":    c.PostgreSQLTable,
+			"POSTGRESQL_SSLMODE":  c.PostgreSQLSSL,
+		}
+
+		var missing []string
+		for name, value := range required {
+			if value == "" {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(missing, ", "))
+		}
+
+		if !tableName.MatchString(c.PostgreSQLTable) {
+			return fmt.Errorf("%w: %q", ErrInvalidTableName, c.PostgreSQLTable)
+		}
+		if len(c.PostgreSQLTable) > MaxTableNameLength {
+			return fmt.Errorf("%w: %q", ErrTableNameTooLong, c.PostgreSQLTable)
+		}
+
+	case storage.BackendSQLite:
+		if c.SQLitePath == "" {
+			return fmt.Errorf("%w: SQLITE_PATH", ErrMissingVariables)
+		}
+
+		// Запросы и миграции SQLite написаны для таблицы quotes. Другое имя молча игнорировалось бы,
+		// поэтому пропускается только пустое значение и значение по умолчанию из .env.
+		if c.PostgreSQLTable != "" && c.PostgreSQLTable != defaultTable {
+			return fmt.Errorf("%w: %q", ErrTableUnsupported, c.PostgreSQLTable)
+		}
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownStorageBackend, c.StorageBackend)
+	}
+	return nil
+}
+
+// isHeaderName сообщает, является ли name допустимым именем HTTP-заголовка (token из RFC 9110)
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// redacted заменяет значение секрета в журнале. Пустые секреты остаются пустыми, чтобы было видно,
+// что переменная не задана.
+const redacted = "REDACTED"
+
+// dsnPassword находит пароль в DSN вида "key=value key=value".
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// LogValue представляет конфигурацию в журнале набором атрибутов с именами env-переменных.
+// Значения полей с тегом secret:"true" заменяются на REDACTED, а у полей с тегом secret:"dsn"
+// скрывается только пароль, чтобы по журналу можно было проверить адрес сервера.
+func (c Config) LogValue() slog.Value {
+	value := reflect.ValueOf(c)
+	fields := reflect.VisibleFields(value.Type())
+
+	attrs := make([]slog.Attr, 0, len(fields))
+
+	for _, field := range fields {
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		v := value.FieldByIndex(field.Index).Interface()
+
+		switch s, _ := v.(string); field.Tag.Get("secret") {
+		case "true":
+			if s != "" {
+				v = redacted
+			}
+		case "dsn":
+			v = redactDSN(s)
+		}
+
+		// Длительности в JSON иначе выводятся наносекундами.
+		if d, ok := v.(time.Duration); ok {
+			v = d.String()
+		}
+
+		attrs = append(attrs, slog.Any(name, v))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// redactDSN скрывает пароль в DSN в формате URL или "key=value".
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+
+	u, err := url.Parse(dsn)
+	if err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		if query := u.Query(); query.Has("password") {
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -3,8 +3,13 @@ package utils
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"getcitation/internal/utils/config"
 )
@@ -25,34 +30,115 @@ func GetLogFile(path string) (*os.File, error) {
 	return f, nil
 }
 
-// BuildPostgresDSN строит строку подключения к PostgreSQL из конфига.
+// BuildPostgresDSN строит строку подключения к PostgreSQL из конфига. Имя пользователя, пароль и имя БД
+// экранируются, поэтому могут содержать @, :, / и другие служебные для URL символы. application_name
+// подписывает соединения сервиса в pg_stat_activity.
 func BuildPostgreSQLDSN(config config.Config) string {
-	var conn string
-
-	if config.PostgreSQLPassword == "" {
-		conn = fmt.Sprintf(
-			"postgres://%s@%s:%s/%s?sslmode=%s",
-			config.PostgreSQLUsername,
-			config.PostgreSQLHost,
-			config.PostgreSQLPort,
-			config.PostgreSQLDatabase,
-			config.PostgreSQLSSL,
-		)
-	} else {
-		conn = fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-			config.PostgreSQLUsername,
-			config.PostgreSQLPassword,
-			config.PostgreSQLHost,
-			config.PostgreSQLPort,
-			config.PostgreSQLDatabase,
-			config.PostgreSQLSSL,
-		)
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(config.PostgreSQLUsername),
+		Host:     config.PostgreSQLHost + ":" + config.PostgreSQLPort,
+		Path:     "/" + config.PostgreSQLDatabase,
+		RawQuery: "sslmode=" + url.QueryEscape(config.PostgreSQLSSL),
+	}
+	if config.PostgreSQLPassword != "" {
+		u.User = url.UserPassword(config.PostgreSQLUsername, config.PostgreSQLPassword)
+	}
+
+	conn := u.String()
+
+	// lib/pq берёт первое значение повторяющегося параметра, поэтому application_name из
+	// POSTGRESQL_EXTRA не перекрыл бы добавленный здесь — в этом случае он и не добавляется.
+	extra, err := url.ParseQuery(config.PostgreSQLExtra)
+	if config.PostgreSQLApplicationName != "" && (err != nil || !extra.Has("application_name")) {
+		conn += "&application_name=" + url.QueryEscape(config.PostgreSQLApplicationName)
 	}
 
 	if config.PostgreSQLExtra != "" {
-		conn += "&&" + config.PostgreSQLExtra
+		conn += "&" + config.PostgreSQLExtra
 	}
 
 	return conn
 }
+
+// BuildSQLiteDSN строит строку подключения к SQLite из конфига. Включает проверку внешних ключей
+// и таймаут ожидания блокировки, которые в SQLite по умолчанию выключены.
+func BuildSQLiteDSN(config config.Config) string {
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", config.SQLitePath)
+}
+
+// ClientIP возвращает IP-адрес клиента. Заголовкам X-Forwarded-For и X-Real-IP верит, только если
+// непосредственный собеседник (RemoteAddr) входит в trustedProxies — список CIDR или отдельных адресов.
+// X-Forwarded-For читается справа налево, пропуская доверенные прокси: левые элементы клиент может
+// подделать, а правый добавил ближайший к сервису прокси. Иначе возвращается адрес из RemoteAddr.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(peer.Unmap(), trustedProxies) {
+		return host
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+
+		var leftmost string
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// Неразборчивый адрес в цепочке — дальше влево верить нечему.
+				break
+			}
+			leftmost = addr.Unmap().String()
+			if !isTrustedProxy(addr.Unmap(), trustedProxies) {
+				return leftmost
+			}
+		}
+		if leftmost != "" {
+			return leftmost
+		}
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+
+	return host
+}
+
+// isTrustedProxy проверяет, входит ли адрес в список доверенных прокси. Некорректные элементы
+// списка пропускаются: их отсекает проверка конфига при запуске.
+func isTrustedProxy(addr netip.Addr, trustedProxies []string) bool {
+	for _, proxy := range trustedProxies {
+		prefix, err := ParseTrustedProxy(proxy)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxy разбирает элемент списка доверенных прокси: CIDR (10.0.0.0/8) или отдельный адрес.
+func ParseTrustedProxy(proxy string) (netip.Prefix, error) {
+	proxy = strings.TrimSpace(proxy)
+
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
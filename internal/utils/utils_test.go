@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"getcitation/internal/utils/config"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "192.168.1.1"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		trusted    []string
+		want       string
+	}{
+		{"no proxy", "203.0.113.5:1234", nil, "", trusted, "203.0.113.5"},
+		{"remote without port", "203.0.113.5", nil, "", trusted, "203.0.113.5"},
+		{"spoofed X-Forwarded-For from untrusted peer", "203.0.113.5:1234", []string{"1.2.3.4"}, "", trusted, "203.0.113.5"},
+		{"spoofed X-Real-IP from untrusted peer", "203.0.113.5:1234", nil, "1.2.3.4", trusted, "203.0.113.5"},
+		{"headers ignored without trusted proxies", "10.0.0.2:1234", []string{"198.51.100.7"}, "198.51.100.9", nil, "10.0.0.2"},
+		{"trusted CIDR", "10.0.0.2:1234", []string{"198.51.100.7"}, "", trusted, "198.51.100.7"},
+		{"trusted single address", "192.168.1.1:5000", []string{"198.51.100.7"}, "", trusted, "198.51.100.7"},
+		{"neighbour of trusted address", "192.168.1.2:5000", []string{"198.51.100.7"}, "", trusted, "192.168.1.2"},
+		{"client spoofs leftmost hop", "10.0.0.2:1234", []string{"6.6.6.6, 198.51.100.7, 10.0.0.3"}, "", trusted, "198.51.100.7"},
+		{"hops in several headers", "10.0.0.2:1234", []string{"6.6.6.6", "198.51.100.7"}, "", trusted, "198.51.100.7"},
+		{"every hop trusted", "10.0.0.2:1234", []string{"10.0.0.5, 10.0.0.3"}, "", trusted, "10.0.0.5"},
+		{"garbage left of client", "10.0.0.2:1234", []string{"garbage, 198.51.100.7"}, "", trusted, "198.51.100.7"},
+		{"garbage next to proxy", "10.0.0.2:1234", []string{"198.51.100.7, garbage"}, "", trusted, "10.0.0.2"},
+		{"X-Real-IP from trusted peer", "10.0.0.2:1234", nil, "198.51.100.9", trusted, "198.51.100.9"},
+		{"X-Forwarded-For wins over X-Real-IP", "10.0.0.2:1234", []string{"198.51.100.7"}, "198.51.100.9", trusted, "198.51.100.7"},
+		{"IPv4-mapped IPv6 peer", "[::ffff:10.0.0.2]:1234", []string{"::ffff:198.51.100.7"}, "", trusted, "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, forwarded := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			got := ClientIP(r, tt.trusted)
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxy(t *testing.T) {
+	tests := []struct {
+		proxy   string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{" 10.1.2.3/8 ", "10.0.0.0/8", false},
+		{"192.168.1.1", "192.168.1.1/32", false},
+		{"::1", "::1/128", false},
+		{"10.0.0.0/33", "", true},
+		{"proxy.local", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.proxy, func(t *testing.T) {
+			got, err := ParseTrustedProxy(tt.proxy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseTrustedProxy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPostgreSQLDSN(t *testing.T) {
+	cfg := config.Config{
+		PostgreSQLUsername:        "app",
+		PostgreSQLPassword:        "secret",
+		PostgreSQLHost:            "db",
+		PostgreSQLPort:            "5432",
+		PostgreSQLDatabase:        "quotes",
+		PostgreSQLSSL:             "disable",
+		PostgreSQLApplicationName: "getcitation",
+		PostgreSQLExtra:           "connect_timeout=5",
+	}
+
+	want := "postgres://app:secret@db:5432/quotes?sslmode=disable&application_name=getcitation&connect_timeout=5"
+	if got := BuildPostgreSQLDSN(cfg); got != want {
+		t.Errorf("BuildPostgreSQLDSN() = %q, want %q", got, want)
+	}
+
+	cfg.PostgreSQLPassword = ""
+	want = "postgres://app@db:5432/quotes?sslmode=disable&application_name=getcitation&connect_timeout=5"
+	if got := BuildPostgreSQLDSN(cfg); got != want {
+		t.Errorf("BuildPostgreSQLDSN() without password = %q, want %q", got, want)
+	}
+}
+
+func TestBuildPostgreSQLDSNEscapesCredentials(t *testing.T) {
+	cfg := config.Config{
+		PostgreSQLUsername: "app@corp:admin",
+		PostgreSQLPassword: "p@ss:w/rd?#% &=",
+		PostgreSQLHost:     "db",
+		PostgreSQLPort:     "5432",
+		PostgreSQLDatabase: "quotes/main",
+		PostgreSQLSSL:      "require",
+	}
+
+	dsn := BuildPostgreSQLDSN(cfg)
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", dsn, err)
+	}
+
+	password, _ := parsed.User.Password()
+	if parsed.User.Username() != cfg.PostgreSQLUsername || password != cfg.PostgreSQLPassword {
+		t.Errorf("credentials = %q:%q, want %q:%q", parsed.User.Username(), password, cfg.PostgreSQLUsername, cfg.PostgreSQLPassword)
+	}
+	if parsed.Host != "db:5432" || parsed.Path != "/"+cfg.PostgreSQLDatabase {
+		t.Errorf("host and path = %q %q, want db:5432 /%s", parsed.Host, parsed.Path, cfg.PostgreSQLDatabase)
+	}
+	if sslmode := parsed.Query().Get("sslmode"); sslmode != "require" {
+		t.Errorf("sslmode = %q, want require", sslmode)
+	}
+}
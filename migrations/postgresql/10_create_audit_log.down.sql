@@ -0,0 +1 @@
+DROP TABLE IF EXISTS audit_log;DROP FUNCTION IF EXISTS audit_log_append_only();
\ No newline at end of file
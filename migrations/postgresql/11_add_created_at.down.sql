@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quotes_created_at; ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS created_at;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(); CREATE INDEX IF NOT EXISTS idx_quotes_created_at ON quotes (created_at);
\ No newline at end of file
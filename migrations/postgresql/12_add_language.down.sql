@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quotes_language; ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS language;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT ''; CREATE INDEX IF NOT EXISTS idx_quotes_language ON quotes (language);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS source;
\ No newline at end of file
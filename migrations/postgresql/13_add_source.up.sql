@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS source VARCHAR(500) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quotes_uuid; ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS uuid;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto; ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS uuid UUID NOT NULL DEFAULT gen_random_uuid(); CREATE UNIQUE INDEX IF NOT EXISTS idx_quotes_uuid ON quotes (uuid);
\ No newline at end of file
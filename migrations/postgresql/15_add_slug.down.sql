@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS quotes_slug ON quotes; DROP FUNCTION IF EXISTS quotes_default_slug(); DROP INDEX IF EXISTS idx_quotes_slug; ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS slug;
\ No newline at end of file
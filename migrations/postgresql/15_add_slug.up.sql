@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS slug VARCHAR(255); UPDATE quotes SET slug = 'quote-' || id WHERE slug IS NULL; ALTER TABLE IF EXISTS quotes ALTER COLUMN slug SET NOT NULL; CREATE UNIQUE INDEX IF NOT EXISTS idx_quotes_slug ON quotes (slug); CREATE OR REPLACE FUNCTION quotes_default_slug() RETURNS trigger AS $$ BEGIN IF NEW.slug IS NULL THEN NEW.slug := 'quote-' || NEW.id; END IF; RETURN NEW; END; $$ LANGUAGE plpgsql; DROP TRIGGER IF EXISTS quotes_slug ON quotes; CREATE TRIGGER quotes_slug BEFORE INSERT ON quotes FOR EACH ROW EXECUTE FUNCTION quotes_default_slug();
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS idempotency_keys DROP COLUMN IF EXISTS fingerprint;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS idempotency_keys ADD COLUMN IF NOT EXISTS fingerprint CHAR(64);
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (key VARCHAR(255) PRIMARY KEY, quote_id BIGINT NOT NULL REFERENCES quotes (id) ON DELETE CASCADE, created_at TIMESTAMPTZ NOT NULL DEFAULT now());
\ No newline at end of file
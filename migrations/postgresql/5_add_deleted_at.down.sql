@@ -0,0 +1 @@
+DROP INDEX IF EXISTS unique_author_quote; DELETE FROM quotes WHERE deleted_at IS NOT NULL; ALTER TABLE IF EXISTS quotes ADD CONSTRAINT unique_author_quote UNIQUE (author, quote); ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ; ALTER TABLE IF EXISTS quotes DROP CONSTRAINT IF EXISTS unique_author_quote; CREATE UNIQUE INDEX IF NOT EXISTS unique_author_quote ON quotes (author, quote) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS likes;
\ No newline at end of file
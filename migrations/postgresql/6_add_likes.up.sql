@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS likes INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS views;
\ No newline at end of file
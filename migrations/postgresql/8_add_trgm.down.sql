@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quote_trgm;DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;CREATE INDEX IF NOT EXISTS idx_quote_trgm ON quotes USING gin (quote gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_search;ALTER TABLE IF EXISTS quotes DROP COLUMN IF EXISTS search;
\ No newline at end of file
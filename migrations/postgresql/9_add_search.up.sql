@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS quotes ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (to_tsvector('english', author || ' ' || quote)) STORED;CREATE INDEX IF NOT EXISTS idx_search ON quotes USING gin (search);
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE TABLE IF NOT EXISTS audit_log (id INTEGER PRIMARY KEY AUTOINCREMENT, action VARCHAR(16) NOT NULL, quote_id INTEGER, author VARCHAR(100), actor VARCHAR(255), created_at TIMESTAMP NOT NULL);CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quotes_created_at; ALTER TABLE quotes DROP COLUMN created_at;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN created_at TIMESTAMP; UPDATE quotes SET created_at = CURRENT_TIMESTAMP; CREATE INDEX IF NOT EXISTS idx_quotes_created_at ON quotes (created_at);
\ No newline at end of file
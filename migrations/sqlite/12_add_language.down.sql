@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_quotes_language; ALTER TABLE quotes DROP COLUMN language;
\ No newline at end of file
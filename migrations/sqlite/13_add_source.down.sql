@@ -0,0 +1 @@
+ALTER TABLE quotes DROP COLUMN source;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN source VARCHAR(500) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS quotes_uuid; DROP INDEX IF EXISTS idx_quotes_uuid; ALTER TABLE quotes DROP COLUMN uuid;
\ No newline at end of file
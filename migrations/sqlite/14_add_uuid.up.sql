@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN uuid TEXT; UPDATE quotes SET uuid = lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))) WHERE uuid IS NULL; CREATE UNIQUE INDEX IF NOT EXISTS idx_quotes_uuid ON quotes (uuid); CREATE TRIGGER IF NOT EXISTS quotes_uuid AFTER INSERT ON quotes FOR EACH ROW WHEN NEW.uuid IS NULL BEGIN UPDATE quotes SET uuid = lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))) WHERE id = NEW.id; END;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS quotes_slug; DROP INDEX IF EXISTS idx_quotes_slug; ALTER TABLE quotes DROP COLUMN slug;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN slug VARCHAR(255); UPDATE quotes SET slug = 'quote-' || id WHERE slug IS NULL; CREATE UNIQUE INDEX IF NOT EXISTS idx_quotes_slug ON quotes (slug); CREATE TRIGGER IF NOT EXISTS quotes_slug AFTER INSERT ON quotes FOR EACH ROW WHEN NEW.slug IS NULL BEGIN UPDATE quotes SET slug = 'quote-' || NEW.id WHERE id = NEW.id; END;
\ No newline at end of file
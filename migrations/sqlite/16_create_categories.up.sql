@@ -0,0 +1 @@
+CREATE TABLE IF NOT EXISTS categories (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(100) NOT NULL UNIQUE, created_at TIMESTAMP NOT NULL);CREATE TABLE IF NOT EXISTS quote_categories (quote_id INTEGER NOT NULL REFERENCES quotes (id) ON DELETE CASCADE, category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE, PRIMARY KEY (quote_id, category_id));CREATE INDEX IF NOT EXISTS idx_quote_categories_category_id ON quote_categories (category_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE idempotency_keys ADD COLUMN fingerprint CHAR(64);
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE TABLE IF NOT EXISTS quotes (id INTEGER PRIMARY KEY AUTOINCREMENT, author VARCHAR(100) NOT NULL, quote VARCHAR(250) NOT NULL);
\ No newline at end of file
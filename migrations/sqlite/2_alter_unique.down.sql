@@ -0,0 +1 @@
+DROP INDEX IF EXISTS unique_author_quote;
\ No newline at end of file
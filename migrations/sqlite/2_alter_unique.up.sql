@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS unique_author_quote ON quotes (author, quote);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_author;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_author ON quotes (author);
\ No newline at end of file
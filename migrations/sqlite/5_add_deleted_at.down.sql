@@ -0,0 +1 @@
+DROP INDEX IF EXISTS unique_author_quote; DELETE FROM quotes WHERE deleted_at IS NOT NULL; CREATE UNIQUE INDEX IF NOT EXISTS unique_author_quote ON quotes (author, quote); ALTER TABLE quotes DROP COLUMN deleted_at;
\ No newline at end of file
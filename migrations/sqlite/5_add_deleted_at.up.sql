@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN deleted_at TIMESTAMP; DROP INDEX IF EXISTS unique_author_quote; CREATE UNIQUE INDEX IF NOT EXISTS unique_author_quote ON quotes (author, quote) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes DROP COLUMN likes;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN likes INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes DROP COLUMN views;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE quotes ADD COLUMN views INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
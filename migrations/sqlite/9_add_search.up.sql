@@ -0,0 +1,2 @@
+-- Полнотекстовый поиск есть только в PostgreSQL: миграция сохраняет нумерацию версий общей для обоих бэкендов.
+SELECT 1;
\ No newline at end of file